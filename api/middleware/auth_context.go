@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"fmt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -19,7 +18,6 @@ func AuthContext(jwtSecret string) gin.HandlerFunc {
 		// Extract token from query or headers
 		if token = c.Query("token"); len(token) == 0 {
 			if authHeader := c.GetHeader("Authorization"); len(authHeader) > 7 {
-				fmt.Println("token auth : ", authHeader)
 				if strings.Contains(authHeader, "Bearer ") {
 					token = authHeader[7:] // Remove "Bearer " prefix
 				} else {
@@ -28,9 +26,9 @@ func AuthContext(jwtSecret string) gin.HandlerFunc {
 			}
 		}
 
-		// If token is missing, log it
+		// Without a token the request goes on unauthenticated, the
+		// authorizer decides whether that is enough
 		if token == "" {
-			fmt.Println("Token not found")
 			c.Next()
 			return
 		}
@@ -38,7 +36,6 @@ func AuthContext(jwtSecret string) gin.HandlerFunc {
 		// Parse the JWT token
 		claims, err := token_pkg.ParseJwtToken(token, jwtSecret)
 		if err != nil {
-			fmt.Println("Failed to parse token:", err)
 			c.Next()
 			return
 		}
@@ -51,7 +48,7 @@ func AuthContext(jwtSecret string) gin.HandlerFunc {
 				}
 			}
 			// Store auth data in Gin's context
-			c.Set(RequestIDHeader, authData)
+			c.Set(RequestAuthKey, authData)
 		}
 
 		c.Next()
@@ -4,32 +4,41 @@ import (
 	"net/http"
 
 	"github.com/casbin/casbin/v2"
-	"go.uber.org/zap"
+	"github.com/gin-gonic/gin"
+	logger "github.com/ruziba3vich/prodonik_lgger"
 )
 
-func Authorizer(e *casbin.CachedEnforcer, logger *zap.Logger) func(next http.Handler) http.Handler {
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			data, ok := r.Context().Value(RequestAuthCtx).(map[string]string)
-			if !ok {
-				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-				return
-			}
+// Authorizer checks the role claim stored by AuthContext against the casbin
+// policies for the requested path and method.
+func Authorizer(e *casbin.CachedEnforcer, log *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(RequestAuthKey)
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": http.StatusText(http.StatusUnauthorized)})
+			return
+		}
 
-			ok, err := e.Enforce(data["sub"], r.URL.Path, r.Method)
-			if err != nil {
-				logger.Error("middleware authorizer",
-					zap.Error(err),
-					zap.String("sub", data["sub"]),
-					zap.String("path", r.URL.Path),
-					zap.String("method", r.Method),
-				)
-			}
-			if ok {
-				next.ServeHTTP(w, r)
-				return
-			}
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-		})
+		data, ok := value.(map[string]string)
+		if !ok || data["role"] == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": http.StatusText(http.StatusUnauthorized)})
+			return
+		}
+
+		allowed, err := e.Enforce(data["role"], c.Request.URL.Path, c.Request.Method)
+		if err != nil {
+			log.Error("middleware authorizer", map[string]any{
+				"error":  err.Error(),
+				"sub":    data["sub"],
+				"role":   data["role"],
+				"path":   c.Request.URL.Path,
+				"method": c.Request.Method,
+			})
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": http.StatusText(http.StatusForbidden)})
+			return
+		}
+
+		c.Next()
 	}
 }
@@ -6,5 +6,6 @@ type (
 
 const (
 	RequestIDHeader                   = "X-Request-Id"
+	RequestAuthKey                    = "auth_data"
 	RequestAuthCtx  ctxKeyRequestAuth = 0
 )
@@ -3,12 +3,14 @@ package api
 import (
 	"time"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/ruziba3vich/argus/api/middleware"
 	handlers "github.com/ruziba3vich/argus/internal/https"
 	v1 "github.com/ruziba3vich/argus/internal/https/v1"
 	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
+	"github.com/ruziba3vich/argus/internal/infrastructure/multicard"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 	"github.com/ruziba3vich/argus/internal/postgres"
 	"github.com/ruziba3vich/argus/internal/service"
@@ -22,16 +24,29 @@ type RouteOption struct {
 	Logger *lgg.Logger
 
 	Attendance     service.AttendanceRepoInterface
+	Audit          service.AuditRepoInterface
 	Bonus          service.BonusesRepoInterface
+	BonusRule      service.BonusRuleRepoInterface
+	Compensation   service.CompensationRepoInterface
+	Currency       service.CurrencyRepoInterface
+	Deduction      service.DeductionsRepoInterface
 	File           service.FileRepoInterface
+	Leave          service.LeaveRepoInterface
+	Notification   service.NotificationRepoInterface
+	Payout         service.PayoutRepoInterface
+	Payroll        service.PayrollRepoInterface
 	Salary         service.SalaryRepoInterface
+	Shift          service.ShiftRepoInterface
 	Task           service.TaskRepoInterface
+	TaskComment    service.TaskCommentRepoInterface
 	User           service.UserRepoInterface
+	Enforcer       *casbin.CachedEnforcer
 	ShutdownOTLP   func() error
 	ContextTimeout time.Duration
 
-	DB    *postgres.Postgres
-	MinIO *minio.MinIOClient
+	DB        *postgres.Postgres
+	MinIO     *minio.MinIOClient
+	Multicard *multicard.Client
 }
 
 // NewRouter -.
@@ -49,12 +64,25 @@ func NewRouter(option *RouteOption) *gin.Engine {
 		Logger: option.Logger,
 
 		Attendance:     option.Attendance,
+		Audit:          option.Audit,
 		Bonuses:        option.Bonus,
+		BonusRule:      option.BonusRule,
+		Compensation:   option.Compensation,
+		Currency:       option.Currency,
+		Deductions:     option.Deduction,
 		File:           option.File,
+		Leave:          option.Leave,
+		Notification:   option.Notification,
+		Payout:         option.Payout,
+		Payroll:        option.Payroll,
 		Salary:         option.Salary,
+		Shift:          option.Shift,
 		Task:           option.Task,
+		TaskComment:    option.TaskComment,
 		User:           option.User,
+		Enforcer:       option.Enforcer,
 		MinIO:          option.MinIO,
+		Multicard:      option.Multicard,
 		DB:             option.DB,
 		ShutdownOTLP:   option.ShutdownOTLP,
 		ContextTimeout: option.ContextTimeout,
@@ -85,11 +113,24 @@ func NewRouter(option *RouteOption) *gin.Engine {
 	apiV1Group.Use(middleware.AuthContext(option.Config.Token.SigningKey))
 
 	routeRegistrars := []func(*gin.RouterGroup, *handlers.HandlerOption){
+		v1.NewAuthRoutes,
 		v1.NewAttendanceRoutes,
+		v1.NewAuditRoutes,
 		v1.NewBonusesRoutes,
+		v1.NewBonusRuleRoutes,
+		v1.NewCompensationRoutes,
+		v1.NewCurrencyRoutes,
+		v1.NewDeductionsRoutes,
 		v1.NewFileRoutes,
+		v1.NewLeaveRoutes,
+		v1.NewMeRoutes,
+		v1.NewNotificationRoutes,
+		v1.NewPayoutRoutes,
+		v1.NewPayrollRoutes,
 		v1.NewSalaryRoutes,
+		v1.NewShiftRoutes,
 		v1.NewTaskRoutes,
+		v1.NewTaskCommentRoutes,
 		v1.NewUserRoutes,
 	}
 
@@ -2,11 +2,13 @@ package main
 
 import (
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/ruziba3vich/argus/internal/app"
+	"github.com/ruziba3vich/argus/internal/infrastructure/multicard"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 )
 
@@ -17,6 +19,26 @@ func main() {
 		log.Fatalf("Config error: %s", err)
 	}
 
+	// `migrate up`, `migrate down [steps]` or `migrate version` only touches the schema and exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.RunMigrations(cfg, os.Args[2:]...); err != nil {
+			log.Fatalf("Migrate error: %s", err)
+		}
+		return
+	}
+
+	// `multicard-standin [address]` serves a local stand-in for the Multicard API
+	// so payouts can be tried without touching the real gateway
+	if len(os.Args) > 1 && os.Args[1] == "multicard-standin" {
+		address := "127.0.0.1:8089"
+		if len(os.Args) > 2 {
+			address = os.Args[2]
+		}
+		log.Printf("Multicard stand-in listening on http://%s/", address)
+		standIn := multicard.NewStandIn(cfg.Multicard.Aggr.ApplicationID, cfg.Multicard.Aggr.Secret)
+		log.Fatal(http.ListenAndServe(address, standIn))
+	}
+
 	app, err := app.NewApp(cfg)
 	if err != nil {
 		log.Fatal(err)
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/casbin/casbin/v2"
 	"github.com/ruziba3vich/argus/api"
 	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
+	"github.com/ruziba3vich/argus/internal/infrastructure/multicard"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 	"github.com/ruziba3vich/argus/internal/pkg/otlp"
 	"github.com/ruziba3vich/argus/internal/postgres"
@@ -19,20 +21,39 @@ type App struct {
 	Config       *config.Config
 	Logger       *logger.Logger
 	attendance   service.AttendanceRepoInterface
+	audit        service.AuditRepoInterface
 	bonus        service.BonusesRepoInterface
+	bonusRule    service.BonusRuleRepoInterface
+	compensation service.CompensationRepoInterface
+	currency     service.CurrencyRepoInterface
+	deduction    service.DeductionsRepoInterface
 	file         service.FileRepoInterface
+	leave        service.LeaveRepoInterface
+	notification service.NotificationRepoInterface
+	payout       service.PayoutRepoInterface
+	payroll      service.PayrollRepoInterface
 	salary       service.SalaryRepoInterface
+	shift        service.ShiftRepoInterface
 	task         service.TaskRepoInterface
+	taskComment  service.TaskCommentRepoInterface
 	user         service.UserRepoInterface
+	enforcer     *casbin.CachedEnforcer
 	server       *http.Server
+	stopJobs     context.CancelFunc
 	ShutdownOTLP func() error
 
-	DB    *postgres.Postgres
-	minIO *minio.MinIOClient
+	DB        *postgres.Postgres
+	minIO     *minio.MinIOClient
+	multicard *multicard.Client
 }
 
 func (a *App) Stop() {
 
+	// stop background jobs
+	if a.stopJobs != nil {
+		a.stopJobs()
+	}
+
 	// close database
 	a.DB.Close()
 
@@ -60,19 +81,45 @@ func NewApp(cfg *config.Config) (*App, error) {
 		return nil, err
 	}
 
+	// schema migrations
+	if cfg.DB.MigrateOnStart {
+		if err := Migrate(context.Background(), db, "up"); err != nil {
+			l.Error("schema migration failed", map[string]any{"error": err.Error()})
+			return nil, err
+		}
+	}
+
 	// otlp collector init
 	shutdownOTLP, err := otlp.InitOTLPProvider(cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	// casbin enforcer init
+	enforcer, err := newEnforcer(cfg)
+	if err != nil {
+		l.Error("newEnforcer failed", map[string]any{"error": err})
+		return nil, err
+	}
+
 	pocket := builder(db, l)
 
 	attendanceUC := pocket("attendance").(service.AttendanceRepoInterface)
+	auditUC := pocket("audit").(service.AuditRepoInterface)
 	bonusUC := pocket("bonus").(service.BonusesRepoInterface)
+	bonusRuleUC := pocket("bonus_rule").(service.BonusRuleRepoInterface)
+	compensationUC := pocket("compensation").(service.CompensationRepoInterface)
+	currencyUC := pocket("currency").(service.CurrencyRepoInterface)
+	deductionUC := pocket("deduction").(service.DeductionsRepoInterface)
 	fileUC := pocket("file").(service.FileRepoInterface)
+	leaveUC := pocket("leave").(service.LeaveRepoInterface)
+	notificationUC := pocket("notification").(service.NotificationRepoInterface)
+	payoutUC := pocket("payout").(service.PayoutRepoInterface)
+	payrollUC := pocket("payroll").(service.PayrollRepoInterface)
 	salaryUC := pocket("salary").(service.SalaryRepoInterface)
+	shiftUC := pocket("shift").(service.ShiftRepoInterface)
 	taskUC := pocket("task").(service.TaskRepoInterface)
+	taskCommentUC := pocket("task_comment").(service.TaskCommentRepoInterface)
 	userUC := pocket("user").(service.UserRepoInterface)
 
 	minIO, err := minio.NewMinIOClient(cfg)
@@ -85,13 +132,27 @@ func NewApp(cfg *config.Config) (*App, error) {
 		Config:       cfg,
 		Logger:       l,
 		attendance:   attendanceUC,
+		audit:        auditUC,
 		bonus:        bonusUC,
+		bonusRule:    bonusRuleUC,
+		compensation: compensationUC,
+		currency:     currencyUC,
+		deduction:    deductionUC,
 		file:         fileUC,
+		leave:        leaveUC,
+		notification: notificationUC,
+		payout:       payoutUC,
+		payroll:      payrollUC,
 		salary:       salaryUC,
+		shift:        shiftUC,
 		task:         taskUC,
+		taskComment:  taskCommentUC,
 		user:         userUC,
+		enforcer:     enforcer,
 		minIO:        minIO,
+		multicard:    multicard.NewClient(cfg),
 		ShutdownOTLP: shutdownOTLP,
+		DB:           db,
 	}, nil
 }
 
@@ -102,12 +163,23 @@ func NewService[T any](constructor func(*postgres.Postgres, *logger.Logger) T) f
 }
 
 var constructors = map[string]func(*postgres.Postgres, *logger.Logger) any{
-	"bonus":      NewService(service.NewBonusesRepo),
-	"attendance": NewService(service.NewAttendanceRepo),
-	"file":       NewService(service.NewFileRepo),
-	"salary":     NewService(service.NewSalaryRepo),
-	"task":       NewService(service.NewTaskRepo),
-	"user":       NewService(service.NewUserRepo),
+	"bonus":        NewService(service.NewBonusesRepo),
+	"bonus_rule":   NewService(service.NewBonusRuleRepo),
+	"attendance":   NewService(service.NewAttendanceRepo),
+	"audit":        NewService(service.NewAuditRepo),
+	"compensation": NewService(service.NewCompensationRepo),
+	"currency":     NewService(service.NewCurrencyRepo),
+	"deduction":    NewService(service.NewDeductionsRepo),
+	"file":         NewService(service.NewFileRepo),
+	"leave":        NewService(service.NewLeaveRepo),
+	"notification": NewService(service.NewNotificationRepo),
+	"payout":       NewService(service.NewPayoutRepo),
+	"payroll":      NewService(service.NewPayrollRepo),
+	"salary":       NewService(service.NewSalaryRepo),
+	"shift":        NewService(service.NewShiftRepo),
+	"task":         NewService(service.NewTaskRepo),
+	"task_comment": NewService(service.NewTaskCommentRepo),
+	"user":         NewService(service.NewUserRepo),
 }
 
 func builder(db *postgres.Postgres, log *logger.Logger) func(serviceName string) any {
@@ -122,15 +194,33 @@ func (a *App) Run() error {
 		return fmt.Errorf("error while parsing context timeout: %v", err)
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	a.stopJobs = stopJobs
+	a.startJobs(jobsCtx)
+
 	handler := api.NewRouter(&api.RouteOption{
 		Config:         a.Config,
 		Logger:         a.Logger,
 		Bonus:          a.bonus,
+		BonusRule:      a.bonusRule,
 		Attendance:     a.attendance,
+		Audit:          a.audit,
+		Compensation:   a.compensation,
+		Currency:       a.currency,
+		Deduction:      a.deduction,
 		File:           a.file,
+		Leave:          a.leave,
+		Notification:   a.notification,
+		Payout:         a.payout,
+		Payroll:        a.payroll,
 		Salary:         a.salary,
+		Shift:          a.shift,
 		Task:           a.task,
+		TaskComment:    a.taskComment,
 		User:           a.user,
+		Enforcer:       a.enforcer,
+		MinIO:          a.minIO,
+		Multicard:      a.multicard,
 		ShutdownOTLP:   a.ShutdownOTLP,
 		ContextTimeout: contextTimeout,
 	})
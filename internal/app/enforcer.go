@@ -0,0 +1,62 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/model"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/postgres"
+)
+
+// casbinModel is a role based model. The subject is the role claim of the
+// access token and objects are matched with keyMatch2, so a policy for
+// /v1/attendance/:id covers /v1/attendance/42.
+const casbinModel = `
+[request_definition]
+r = sub, obj, act
+
+[policy_definition]
+p = sub, obj, act
+
+[role_definition]
+g = _, _
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = g(r.sub, p.sub) && keyMatch2(r.obj, p.obj) && r.act == p.act
+`
+
+// roleHierarchy lists which roles inherit the policies of another role.
+var roleHierarchy = [][]string{
+	{string(entity.UserRoleSuperAdmin), string(entity.UserRoleAdmin)},
+	{string(entity.UserRoleAdmin), string(entity.UserRoleUser)},
+}
+
+func newEnforcer(cfg *config.Config) (*casbin.CachedEnforcer, error) {
+	adapter, err := postgres.GetAdapter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := model.NewModelFromString(casbinModel)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing casbin model: %w", err)
+	}
+
+	enforcer, err := casbin.NewCachedEnforcer(m, adapter)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating casbin enforcer: %w", err)
+	}
+
+	for _, rule := range roleHierarchy {
+		if _, err := enforcer.AddGroupingPolicy(rule); err != nil {
+			return nil, fmt.Errorf("error while adding casbin role hierarchy: %w", err)
+		}
+	}
+
+	return enforcer, nil
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+)
+
+// startJobs launches the background jobs. They stop when ctx is cancelled.
+func (a *App) startJobs(ctx context.Context) {
+	if !a.Config.Jobs.Enabled {
+		a.Logger.Info("background jobs are disabled")
+		return
+	}
+
+	location, err := time.LoadLocation(a.Config.Attendance.Timezone)
+	if err != nil {
+		a.Logger.Error("invalid attendance time zone, jobs run in UTC", map[string]any{"error": err.Error()})
+		location = time.UTC
+	}
+
+	absenceCheckAt, err := helper.ParseClock(a.Config.Jobs.AbsenceCheckAt)
+	if err != nil {
+		a.Logger.Error("invalid absence check time, absence detection is disabled", map[string]any{"error": err.Error()})
+	} else {
+		go runDaily(ctx, absenceCheckAt, location, a.closeAttendanceDay)
+	}
+
+	overdueCheckAt, err := helper.ParseClock(a.Config.Jobs.OverdueCheckAt)
+	if err != nil {
+		a.Logger.Error("invalid overdue check time, overdue marking is disabled", map[string]any{"error": err.Error()})
+	} else {
+		go runDaily(ctx, overdueCheckAt, location, a.markSalariesOverdue)
+	}
+
+	bonusRulesAt, err := helper.ParseClock(a.Config.Jobs.BonusRulesAt)
+	if err != nil {
+		a.Logger.Error("invalid bonus rules time, bonus rule evaluation is disabled", map[string]any{"error": err.Error()})
+	} else {
+		go runDaily(ctx, bonusRulesAt, location, a.evaluateBonusRules)
+	}
+}
+
+// runDaily calls job once a day at the given offset from midnight in location
+// until ctx is cancelled.
+func runDaily(ctx context.Context, at time.Duration, location *time.Location, job func(ctx context.Context, now time.Time)) {
+	for {
+		now := time.Now().In(location)
+		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location).Add(at)
+		if !next.After(now) {
+			next = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, location).Add(at)
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case fired := <-timer.C:
+			job(ctx, fired.In(location))
+		}
+	}
+}
+
+// closeAttendanceDay closes the working day before now: absent records are
+// created for scheduled users who never checked in, forgotten open records
+// are closed, and the affected users and the admins are notified.
+func (a *App) closeAttendanceDay(ctx context.Context, now time.Time) {
+	yesterday := now.AddDate(0, 0, -1)
+	day := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, time.UTC)
+	dayStr := day.Format(time.DateOnly)
+
+	result, err := a.attendance.CloseDay(ctx, day)
+	if err != nil {
+		a.Logger.Error("absence detection failed", map[string]any{"error": err.Error(), "date": dayStr})
+		return
+	}
+
+	for _, record := range result.Absent {
+		a.notify(ctx, record.UserID, fmt.Sprintf("You were marked absent on %s because no check-in was recorded for your shift.", dayStr))
+	}
+	for _, record := range result.AutoClosed {
+		a.notify(ctx, record.UserID, fmt.Sprintf(
+			"Your attendance on %s was closed automatically because you did not check out.",
+			record.Date.Format(time.DateOnly),
+		))
+	}
+
+	if len(result.Absent) == 0 && len(result.AutoClosed) == 0 {
+		return
+	}
+
+	adminRole := entity.UserRoleAdmin
+	_, err = a.notification.Broadcast(ctx, &entity.BroadcastNotificationRequest{
+		Role: &adminRole,
+		Message: fmt.Sprintf(
+			"Attendance for %s: %d absent, %d forgotten check-outs closed automatically.",
+			dayStr, len(result.Absent), len(result.AutoClosed),
+		),
+		Type: entity.NotificationTypeApp,
+	})
+	if err != nil {
+		a.Logger.Error("absence detection admin notification failed", map[string]any{"error": err.Error(), "date": dayStr})
+	}
+
+	a.Logger.Info("absence detection finished", map[string]any{
+		"date":        dayStr,
+		"absent":      len(result.Absent),
+		"auto_closed": len(result.AutoClosed),
+	})
+}
+
+// markSalariesOverdue moves pending salaries whose pay date has passed to
+// overdue and tells the employees and the admins.
+func (a *App) markSalariesOverdue(ctx context.Context, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	todayStr := today.Format(time.DateOnly)
+
+	salaries, err := a.salary.MarkOverdue(ctx, today)
+	if err != nil {
+		a.Logger.Error("overdue salary marking failed", map[string]any{"error": err.Error(), "date": todayStr})
+		return
+	}
+
+	if len(salaries) == 0 {
+		return
+	}
+
+	for _, salary := range salaries {
+		a.notify(ctx, salary.UserID, fmt.Sprintf(
+			"Your salary of %.2f %s due on %s is overdue.",
+			salary.Amount, salary.Currency, salary.PayDate.Format(time.DateOnly),
+		))
+	}
+
+	adminRole := entity.UserRoleAdmin
+	_, err = a.notification.Broadcast(ctx, &entity.BroadcastNotificationRequest{
+		Role:    &adminRole,
+		Message: fmt.Sprintf("%d pending salaries passed their pay date and are now overdue.", len(salaries)),
+		Type:    entity.NotificationTypeApp,
+	})
+	if err != nil {
+		a.Logger.Error("overdue salary admin notification failed", map[string]any{"error": err.Error(), "date": todayStr})
+	}
+
+	a.Logger.Info("overdue salary marking finished", map[string]any{"date": todayStr, "overdue": len(salaries)})
+}
+
+// evaluateBonusRules proposes the bonuses earned under the bonus rules in the
+// month before now and asks the super admins to review them. It runs daily so
+// corrections made after the month ended are still picked up; employees
+// already proposed a bonus by a rule for the month are skipped.
+func (a *App) evaluateBonusRules(ctx context.Context, now time.Time) {
+	month := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	monthStr := month.Format("2006-01")
+
+	run, err := a.bonusRule.Evaluate(ctx, month)
+	if err != nil {
+		a.Logger.Error("bonus rule evaluation failed", map[string]any{"error": err.Error(), "month": monthStr})
+		return
+	}
+
+	if len(run.Proposed) == 0 {
+		return
+	}
+
+	superAdminRole := entity.UserRoleSuperAdmin
+	_, err = a.notification.Broadcast(ctx, &entity.BroadcastNotificationRequest{
+		Role:    &superAdminRole,
+		Message: fmt.Sprintf("Bonus rules proposed %d bonuses for %s that are waiting for your review.", len(run.Proposed), month.Format("January 2006")),
+		Type:    entity.NotificationTypeApp,
+	})
+	if err != nil {
+		a.Logger.Error("bonus rule super admin notification failed", map[string]any{"error": err.Error(), "month": monthStr})
+	}
+
+	a.Logger.Info("bonus rule evaluation finished", map[string]any{"month": monthStr, "rules": run.Rules, "proposed": len(run.Proposed)})
+}
+
+// notify sends an in-app notification and only logs failures, so one
+// undeliverable message does not stop a job.
+func (a *App) notify(ctx context.Context, userID int64, message string) {
+	_, err := a.notification.Create(ctx, &entity.CreateNotificationRequest{
+		UserID:  userID,
+		Message: message,
+		Type:    entity.NotificationTypeApp,
+	})
+	if err != nil {
+		a.Logger.Error("job notification failed", map[string]any{"error": err.Error(), "user_id": userID})
+	}
+}
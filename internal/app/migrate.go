@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	"github.com/ruziba3vich/argus/migrations"
+)
+
+// RunMigrations connects to the database and runs a migrate command:
+// "up", "down [steps]" or "version". It backs the `migrate` subcommand.
+func RunMigrations(cfg *config.Config, args ...string) error {
+	db, err := postgres.New(cfg, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return Migrate(context.Background(), db, args...)
+}
+
+// Migrate runs a migrate command against the embedded migrations.
+func Migrate(ctx context.Context, db *postgres.Postgres, args ...string) error {
+	migrator, err := postgres.NewMigrator(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrations applied: %d", applied)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of down steps: %q", args[1])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrations reverted: %d", reverted)
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Schema version: %d", version)
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down [steps] or version", command)
+	}
+
+	return nil
+}
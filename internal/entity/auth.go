@@ -73,6 +73,17 @@ type (
 		AccessToken string `json:"access_token"`
 	}
 
+	// AuthResponse represents the token pair issued on register, login and refresh
+	AuthResponse struct {
+		AccessToken
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	// RefreshTokenRequest represents the request to rotate a refresh token
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+
 	// JWTClaims represents the JWT token claims
 	JWTClaims struct {
 		Sub  string `json:"sub"`
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -40,12 +41,15 @@ func GetUserRole(role string) UserRole {
 	}
 }
 
+// Currency is an ISO 4217 code from the currencies table. The constants are
+// the currencies every database starts with; more are added through the API.
 type Currency string
 
 const (
 	CurrencyUSD Currency = "USD"
 	CurrencyEUR Currency = "EUR"
 	CurrencyGBP Currency = "GBP"
+	CurrencyUZS Currency = "UZS"
 )
 
 type AttendanceStatus string
@@ -73,6 +77,64 @@ const (
 	TaskStatusCancelled  TaskStatus = "cancelled"
 )
 
+type LeaveType string
+
+const (
+	LeaveTypeVacation LeaveType = "vacation"
+	LeaveTypeSick     LeaveType = "sick"
+	LeaveTypeUnpaid   LeaveType = "unpaid"
+)
+
+type LeaveStatus string
+
+const (
+	LeaveStatusPending   LeaveStatus = "pending"
+	LeaveStatusApproved  LeaveStatus = "approved"
+	LeaveStatusRejected  LeaveStatus = "rejected"
+	LeaveStatusCancelled LeaveStatus = "cancelled"
+)
+
+type BonusStatus string
+
+const (
+	BonusStatusPending  BonusStatus = "pending"
+	BonusStatusApproved BonusStatus = "approved"
+	BonusStatusRejected BonusStatus = "rejected"
+)
+
+type BonusRuleKind string
+
+const (
+	BonusRuleKindPerfectAttendance BonusRuleKind = "perfect_attendance"
+	BonusRuleKindZeroLateDays      BonusRuleKind = "zero_late_days"
+	BonusRuleKindHighPriorityTasks BonusRuleKind = "high_priority_tasks"
+)
+
+type DeductionKind string
+
+const (
+	DeductionKindFine     DeductionKind = "fine"
+	DeductionKindAdvance  DeductionKind = "advance"
+	DeductionKindLateness DeductionKind = "lateness"
+)
+
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+type RateType string
+
+const (
+	RateTypeMonthly RateType = "monthly"
+	RateTypeHourly  RateType = "hourly"
+)
+
 type TaskPriority string
 
 const (
@@ -82,31 +144,50 @@ const (
 )
 
 // Bonuses
+// A bonus is proposed by an admin and only counts once a super admin, recorded
+// in SuperAdminID, has approved it.
 type Bonus struct {
-	ID           int64    `json:"id"`
-	SuperAdminID *int64   `json:"super_admin_id"` // Assuming nullable
-	UserID       int64    `json:"user_id"`
-	Amount       float64  `json:"amount"`
-	Currency     Currency `json:"currency"` // Enum
-	Reason       *string  `json:"reason"`   // Assuming nullable
+	ID                  int64         `json:"id"`
+	SuperAdminID        *int64        `json:"super_admin_id"` // Reviewer, set on approval or rejection
+	UserID              int64         `json:"user_id"`
+	Amount              float64       `json:"amount"`
+	Currency            Currency      `json:"currency"` // Enum
+	Reason              *string       `json:"reason"`   // Assuming nullable
+	Status              BonusStatus   `json:"status"`   // Enum
+	ProposedBy          *int64        `json:"proposed_by"`
+	RuleID              *int64        `json:"rule_id"` // Set when proposed by a bonus rule
+	ReviewedAt          *time.Time    `json:"reviewed_at"`
+	ReviewComment       *string       `json:"review_comment"`
+	PayoutStatus        *PayoutStatus `json:"payout_status"`
+	PayoutTransactionID *string       `json:"payout_transaction_id"`
+	PayrollRunID        *int64        `json:"payroll_run_id"`       // Set once paid with a payroll run salary
+	DeletedAt           *time.Time    `json:"deleted_at,omitempty"` // Set while the bonus is in the trash
 	BaseModel
 }
 
 type CreateBonusRequest struct {
-	SuperAdminID *int64   `json:"super_admin_id"`
-	UserID       int64    `json:"user_id"`
-	Amount       float64  `json:"amount"`
-	Currency     Currency `json:"currency"`
-	Reason       *string  `json:"reason"`
+	ProposedBy int64    `json:"-"` // Set from the access token
+	UserID     int64    `json:"user_id"`
+	Amount     float64  `json:"amount"`
+	Currency   Currency `json:"currency"`
+	Reason     *string  `json:"reason"`
 }
 
+// UpdateBonusRequest changes a bonus that is still pending.
 type UpdateBonusRequest struct {
-	ID           int64     `json:"id"`
-	SuperAdminID *int64    `json:"super_admin_id"`
-	UserID       *int64    `json:"user_id"` // Optional update
-	Amount       *float64  `json:"amount"`
-	Currency     *Currency `json:"currency"`
-	Reason       *string   `json:"reason"`
+	ID       int64     `json:"id"`
+	UserID   *int64    `json:"user_id"` // Optional update
+	Amount   *float64  `json:"amount"`
+	Currency *Currency `json:"currency"`
+	Reason   *string   `json:"reason"`
+}
+
+// ReviewBonusRequest approves or rejects a pending bonus.
+type ReviewBonusRequest struct {
+	ID           int64       `json:"-"`
+	SuperAdminID int64       `json:"-"`
+	Status       BonusStatus `json:"-"` // Approved or rejected, set by the route
+	Comment      *string     `json:"comment"`
 }
 
 type GetAllBonusesResponse struct {
@@ -114,6 +195,97 @@ type GetAllBonusesResponse struct {
 	Total uint64   `json:"total"`
 }
 
+// BonusRule proposes a bonus to every employee whose month of attendance or
+// tasks meets it. Threshold is the number of days attended for attendance
+// rules and of high-priority tasks completed before their due date for task
+// rules.
+type BonusRule struct {
+	ID        int64         `json:"id"`
+	Name      string        `json:"name"`
+	Kind      BonusRuleKind `json:"kind"` // Enum
+	Threshold int           `json:"threshold"`
+	Amount    float64       `json:"amount"`
+	Currency  Currency      `json:"currency"`
+	Active    bool          `json:"active"`
+	AdminID   *int64        `json:"admin_id"`
+	BaseModel
+}
+
+type CreateBonusRuleRequest struct {
+	Name      string        `json:"name"`
+	Kind      BonusRuleKind `json:"kind"`
+	Threshold int           `json:"threshold"`
+	Amount    float64       `json:"amount"`
+	Currency  Currency      `json:"currency"`
+	Active    *bool         `json:"active"` // Defaults to true
+	AdminID   int64         `json:"-"`
+}
+
+type UpdateBonusRuleRequest struct {
+	ID        int64     `json:"id"`
+	Name      *string   `json:"name"`
+	Threshold *int      `json:"threshold"`
+	Amount    *float64  `json:"amount"`
+	Currency  *Currency `json:"currency"`
+	Active    *bool     `json:"active"`
+}
+
+type GetAllBonusRulesResponse struct {
+	Items []*BonusRule `json:"items"`
+	Total uint64       `json:"total"`
+}
+
+// BonusRuleRun is what one evaluation of the active rules over a month
+// proposed. Employees already proposed a bonus by a rule for the month are
+// skipped, so Proposed only holds new bonuses.
+type BonusRuleRun struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Rules       int       `json:"rules"`
+	Proposed    []*Bonus  `json:"proposed"`
+}
+
+// Deductions
+// A deduction is a fine, salary advance or lateness penalty an admin takes off
+// an employee's pay. A lateness penalty usually points at the attendance
+// record it was issued for.
+type Deduction struct {
+	ID           int64         `json:"id"`
+	AdminID      *int64        `json:"admin_id"` // Issuing admin
+	UserID       int64         `json:"user_id"`
+	AttendanceID *int64        `json:"attendance_id"`
+	Kind         DeductionKind `json:"kind"` // Enum
+	Amount       float64       `json:"amount"`
+	Currency     Currency      `json:"currency"`
+	Reason       *string       `json:"reason"`
+	BaseModel
+}
+
+type CreateDeductionRequest struct {
+	AdminID      int64         `json:"-"` // Set from the access token
+	UserID       int64         `json:"user_id"`
+	AttendanceID *int64        `json:"attendance_id"` // Must belong to the user
+	Kind         DeductionKind `json:"kind"`          // Defaults to fine
+	Amount       float64       `json:"amount"`
+	Currency     Currency      `json:"currency"`
+	Reason       *string       `json:"reason"`
+}
+
+type UpdateDeductionRequest struct {
+	ID           int64          `json:"id"`
+	UserID       *int64         `json:"user_id"` // Optional update
+	AttendanceID *int64         `json:"attendance_id"`
+	Kind         *DeductionKind `json:"kind"`
+	Amount       *float64       `json:"amount"`
+	Currency     *Currency      `json:"currency"`
+	Reason       *string        `json:"reason"`
+}
+
+type GetAllDeductionsResponse struct {
+	Items []*Deduction `json:"items"`
+	Total uint64       `json:"total"`
+}
+
 // Notifications
 type Notification struct {
 	ID      int64            `json:"id"`
@@ -143,18 +315,36 @@ type GetAllNotificationsResponse struct {
 	Total uint64          `json:"total"`
 }
 
+// BroadcastNotificationRequest sends one message to many users.
+// An empty UserIDs list together with a nil Role targets every user.
+type BroadcastNotificationRequest struct {
+	UserIDs []int64          `json:"user_ids"`
+	Role    *UserRole        `json:"role"`
+	Message string           `json:"message"`
+	Type    NotificationType `json:"type"`
+}
+
+type BroadcastNotificationResponse struct {
+	Sent int64 `json:"sent"`
+}
+
+type UnreadNotificationsResponse struct {
+	Unread uint64 `json:"unread"`
+}
+
 // Users
 type User struct {
-	ID                 int64    `json:"id"`
-	FirstName          string   `json:"first_name"`
-	LastName           string   `json:"last_name"`
-	Role               UserRole `json:"role"` // Enum
-	Email              string   `json:"email"`
-	Phone              string   `json:"phone"`
-	PhotoURL           *string  `json:"photo_url"` // Assuming nullable
-	Bio                *string  `json:"bio"`       // Assuming nullable
-	HashedPassword     string   `json:"-"`         // Never expose in JSON
-	HashedRefreshToken *string  `json:"-"`         // Never expose in JSON, assuming nullable
+	ID                 int64      `json:"id"`
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Role               UserRole   `json:"role"` // Enum
+	Email              string     `json:"email"`
+	Phone              string     `json:"phone"`
+	PhotoURL           *string    `json:"photo_url"`            // Assuming nullable
+	Bio                *string    `json:"bio"`                  // Assuming nullable
+	HashedPassword     string     `json:"-"`                    // Never expose in JSON
+	HashedRefreshToken *string    `json:"-"`                    // Never expose in JSON, assuming nullable
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"` // Set while the user is in the trash
 	BaseModel
 }
 
@@ -189,14 +379,20 @@ type GetAllUsersResponse struct {
 
 // Salaries
 type Salary struct {
-	ID             int64        `json:"id"`
-	Amount         float64      `json:"amount"`
-	UserID         int64        `json:"user_id"`
-	AdminID        int64        `json:"admin_id"`
-	UpdaterAdminID *int64       `json:"updater_admin_id"` // Assuming nullable
-	PayDate        time.Time    `json:"pay_date"`
-	Currency       Currency     `json:"currency"` // Enum
-	Status         SalaryStatus `json:"status"`   // Enum
+	ID                  int64         `json:"id"`
+	Amount              float64       `json:"amount"`
+	UserID              int64         `json:"user_id"`
+	AdminID             int64         `json:"admin_id"`
+	UpdaterAdminID      *int64        `json:"updater_admin_id"` // Assuming nullable
+	PayDate             time.Time     `json:"pay_date"`
+	Currency            Currency      `json:"currency"` // Enum
+	Status              SalaryStatus  `json:"status"`   // Enum
+	PaidAt              *time.Time    `json:"paid_at"`
+	PayrollRunID        *int64        `json:"payroll_run_id"`
+	PayslipKey          *string       `json:"payslip_key"`
+	PayoutStatus        *PayoutStatus `json:"payout_status"`
+	PayoutTransactionID *string       `json:"payout_transaction_id"`
+	DeletedAt           *time.Time    `json:"deleted_at,omitempty"` // Set while the salary is in the trash
 	BaseModel
 }
 
@@ -221,21 +417,260 @@ type UpdateSalaryRequest struct {
 	Status         *SalaryStatus `json:"status"`
 }
 
+// SalaryTransitionRequest moves a salary to another status on behalf of an admin.
+type SalaryTransitionRequest struct {
+	ID      int64        `json:"-"`
+	AdminID int64        `json:"-"`
+	Status  SalaryStatus `json:"-"`
+	PayDate *time.Time   `json:"pay_date"` // New pay date when reopening
+}
+
+// Payslip is the breakdown of one paid salary handed to the employee. Base
+// salary plus bonuses less deductions gives the net amount.
+type Payslip struct {
+	SalaryID        int64          `json:"salary_id"`
+	UserID          int64          `json:"user_id"`
+	FirstName       string         `json:"first_name"`
+	LastName        string         `json:"last_name"`
+	Currency        Currency       `json:"currency"`
+	PeriodStart     time.Time      `json:"period_start"`
+	PeriodEnd       time.Time      `json:"period_end"`
+	PayDate         time.Time      `json:"pay_date"`
+	PaidAt          *time.Time     `json:"paid_at"`
+	BaseSalary      float64        `json:"base_salary"`
+	Bonuses         []*PayslipItem `json:"bonuses"`
+	Deductions      []*PayslipItem `json:"deductions"`
+	TotalBonuses    float64        `json:"total_bonuses"`
+	TotalDeductions float64        `json:"total_deductions"`
+	Net             float64        `json:"net"`
+}
+
+// PayslipItem is one bonus or deduction on a payslip, in the salary's currency.
+type PayslipItem struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+}
+
 type GetAllSalariesResponse struct {
 	Items []*Salary `json:"items"`
 	Total uint64    `json:"total"`
 }
 
+// Compensations
+type Compensation struct {
+	ID            int64     `json:"id"`
+	UserID        int64     `json:"user_id"`
+	RateType      RateType  `json:"rate_type"` // Enum
+	Amount        float64   `json:"amount"`    // Monthly pay or hourly rate
+	Currency      Currency  `json:"currency"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	AdminID       *int64    `json:"admin_id"`
+	Note          *string   `json:"note"`
+	BaseModel
+}
+
+type CreateCompensationRequest struct {
+	UserID        int64     `json:"user_id"`
+	RateType      RateType  `json:"rate_type"`
+	Amount        float64   `json:"amount"`
+	Currency      Currency  `json:"currency"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	AdminID       int64     `json:"-"`
+	Note          *string   `json:"note"`
+}
+
+type UpdateCompensationRequest struct {
+	ID            int64      `json:"id"`
+	RateType      *RateType  `json:"rate_type"`
+	Amount        *float64   `json:"amount"`
+	Currency      *Currency  `json:"currency"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+	Note          *string    `json:"note"`
+}
+
+type GetAllCompensationsResponse struct {
+	Items []*Compensation `json:"items"`
+	Total uint64          `json:"total"`
+}
+
+// Currencies
+type CurrencyDetails struct {
+	Code      Currency  `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateCurrencyRequest struct {
+	Code Currency `json:"code"`
+	Name string   `json:"name"`
+}
+
+type GetAllCurrenciesResponse struct {
+	Items []*CurrencyDetails `json:"items"`
+	Total uint64             `json:"total"`
+}
+
+// ExchangeRate says one unit of Base is worth Rate units of Quote on Date.
+type ExchangeRate struct {
+	ID    int64     `json:"id"`
+	Base  Currency  `json:"base"`
+	Quote Currency  `json:"quote"`
+	Date  time.Time `json:"date"`
+	Rate  float64   `json:"rate"`
+	BaseModel
+}
+
+// UploadExchangeRatesRequest sets the rates of one base currency on a date,
+// replacing the ones already stored for the same pairs and date.
+type UploadExchangeRatesRequest struct {
+	Base  Currency             `json:"base"`
+	Date  time.Time            `json:"date"`
+	Rates map[Currency]float64 `json:"rates"` // Units of each quote currency per unit of base
+}
+
+type GetAllExchangeRatesResponse struct {
+	Items []*ExchangeRate `json:"items"`
+	Total uint64          `json:"total"`
+}
+
+type CurrencyAmount struct {
+	Currency Currency `json:"currency"`
+	Amount   float64  `json:"amount"`
+}
+
+// PayoutReport totals salaries or bonuses in a reporting currency. Each
+// amount is converted at the latest rate known on its pay or grant date.
+type PayoutReport struct {
+	Currency Currency           `json:"currency"`
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"`
+	Count    uint64             `json:"count"`
+	Total    float64            `json:"total"`
+	ByStatus map[string]float64 `json:"by_status,omitempty"`
+	Original []*CurrencyAmount  `json:"original"` // Totals per currency before conversion
+}
+
+// Payouts
+type PayoutStatus string
+
+const (
+	PayoutStatusProcessing PayoutStatus = "processing"
+	PayoutStatusSucceeded  PayoutStatus = "succeeded"
+	PayoutStatusFailed     PayoutStatus = "failed"
+)
+
+// PayoutKind is what a payout pays out.
+type PayoutKind string
+
+const (
+	PayoutKindSalary PayoutKind = "salary"
+	PayoutKindBonus  PayoutKind = "bonus"
+)
+
+// PayoutCard is the card an employee is paid to. Card holds the card number or
+// a card token issued by Multicard and is never returned by the API.
+type PayoutCard struct {
+	UserID    int64     `json:"user_id"`
+	Card      string    `json:"-"`
+	Masked    string    `json:"masked"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SetPayoutCardRequest struct {
+	Card string `json:"card"`
+}
+
+// PayoutOrder is a salary or bonus claimed for a payout. Key stays the same
+// across attempts, so the provider never pays the same record twice.
+type PayoutOrder struct {
+	Kind      PayoutKind
+	RefID     int64
+	UserID    int64
+	Key       string
+	Card      string
+	Amount    float64  // In Currency
+	Currency  Currency // Salary or bonus currency
+	AmountUZS float64  // Amount converted to UZS, which cards are paid in
+}
+
+// Payout is the outcome of a payout, as recorded on the salary or bonus.
+type Payout struct {
+	Kind          PayoutKind   `json:"kind"`
+	RefID         int64        `json:"ref_id"`
+	Key           string       `json:"key"`
+	Status        PayoutStatus `json:"status"`
+	TransactionID *string      `json:"transaction_id"`
+	Error         *string      `json:"error"`
+	AmountUZS     float64      `json:"amount_uzs"`
+}
+
+// Payroll
+type PayrollRunRequest struct {
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	PayDate  time.Time         `json:"pay_date"`
+	Currency Currency          `json:"currency"`
+	BaseRate float64           `json:"base_rate"` // Monthly pay of employees without a compensation profile
+	Rates    map[int64]float64 `json:"rates"`     // Monthly pay by user ID, overriding compensation profiles
+	UserIDs  []int64           `json:"user_ids"`  // Every employee when empty
+	AdminID  int64             `json:"-"`
+}
+
+// PayrollLine is the computed pay of one employee in the run's currency. A
+// monthly rate is paid in proportion to the working days attended or spent on
+// paid leave, an hourly rate for the hours worked, the bonuses granted during
+// the period are added on top and the deductions issued during it taken off.
+type PayrollLine struct {
+	UserID        int64    `json:"user_id"`
+	FirstName     string   `json:"first_name"`
+	LastName      string   `json:"last_name"`
+	WorkingDays   int      `json:"working_days"`
+	AttendedDays  int      `json:"attended_days"`
+	PaidLeaveDays int      `json:"paid_leave_days"`
+	UnpaidDays    int      `json:"unpaid_days"` // Absences and unpaid leave
+	WorkedHours   float64  `json:"worked_hours"`
+	RateType      RateType `json:"rate_type"`
+	BaseRate      float64  `json:"base_rate"`
+	BasePay       float64  `json:"base_pay"`
+	Bonuses       float64  `json:"bonuses"`
+	Deductions    float64  `json:"deductions"`
+	Amount        float64  `json:"amount"`
+	SalaryID      *int64   `json:"salary_id"`         // Set once the run is committed
+	Skipped       string   `json:"skipped,omitempty"` // Why no salary is created
+	BonusIDs      []int64  `json:"-"`                 // Bonuses paid with the salary
+}
+
+type PayrollRun struct {
+	ID        int64          `json:"id"` // Zero for a preview
+	AdminID   int64          `json:"admin_id"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	PayDate   time.Time      `json:"pay_date"`
+	Currency  Currency       `json:"currency"`
+	Total     float64        `json:"total"`
+	Lines     []*PayrollLine `json:"lines,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type GetAllPayrollRunsResponse struct {
+	Items []*PayrollRun `json:"items"`
+	Total uint64        `json:"total"`
+}
+
 // Tasks
 type Task struct {
 	ID          int64        `json:"id"`
 	AssignedTo  *int64       `json:"assigned_to"` // Assuming nullable, refers to UserID
 	AdminID     int64        `json:"admin_id"`    // Refers to UserID
 	Title       string       `json:"title"`
-	Description *string      `json:"description"` // Assuming nullable
-	Status      TaskStatus   `json:"status"`      // Enum
-	Priority    TaskPriority `json:"priority"`    // Enum
-	DueDate     *time.Time   `json:"due_date"`    // Assuming nullable
+	Description *string      `json:"description"`          // Assuming nullable
+	Status      TaskStatus   `json:"status"`               // Enum
+	Priority    TaskPriority `json:"priority"`             // Enum
+	DueDate     *time.Time   `json:"due_date"`             // Assuming nullable
+	CompletedAt *time.Time   `json:"completed_at"`         // Set while the task is completed
+	DeletedAt   *time.Time   `json:"deleted_at,omitempty"` // Set while the task is in the trash
 	BaseModel
 }
 
@@ -244,7 +679,6 @@ type CreateTaskRequest struct {
 	AdminID     int64        `json:"admin_id"`
 	Title       string       `json:"title"`
 	Description *string      `json:"description"`
-	Status      TaskStatus   `json:"status"`
 	Priority    TaskPriority `json:"priority"`
 	DueDate     *time.Time   `json:"due_date"`
 }
@@ -255,27 +689,108 @@ type UpdateTaskRequest struct {
 	AdminID     *int64        `json:"admin_id"`
 	Title       *string       `json:"title"`
 	Description *string       `json:"description"`
-	Status      *TaskStatus   `json:"status"`
 	Priority    *TaskPriority `json:"priority"`
 	DueDate     *time.Time    `json:"due_date"`
 }
 
+// TaskTransitionRequest moves a task to another status on behalf of a user.
+type TaskTransitionRequest struct {
+	ID      int64      `json:"-"`
+	ActorID int64      `json:"-"`
+	Status  TaskStatus `json:"-"`
+}
+
+// TaskStatusChange is one move of a task between statuses. FromStatus is nil
+// for the status the task was created with.
+type TaskStatusChange struct {
+	ID         int64       `json:"id"`
+	TaskID     int64       `json:"task_id"`
+	FromStatus *TaskStatus `json:"from_status"`
+	ToStatus   TaskStatus  `json:"to_status"`
+	ActorID    *int64      `json:"actor_id"` // Nil once the user is purged
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
 type GetAllTasksResponse struct {
 	Items []*Task `json:"items"`
 	Total uint64  `json:"total"`
 }
 
+// Task comments
+type TaskComment struct {
+	ID       int64   `json:"id"`
+	TaskID   int64   `json:"task_id"`
+	UserID   *int64  `json:"user_id"` // Author, nil once the user is purged
+	Body     string  `json:"body"`
+	Mentions []int64 `json:"mentions,omitempty"` // Users notified by this create or edit
+	BaseModel
+}
+
+type CreateTaskCommentRequest struct {
+	TaskID int64  `json:"-"` // Set from the URL path
+	UserID int64  `json:"-"` // Set from the access token
+	Body   string `json:"body"`
+}
+
+type UpdateTaskCommentRequest struct {
+	ID   int64  `json:"-"` // Set from the URL path
+	Body string `json:"body"`
+}
+
+type GetAllTaskCommentsResponse struct {
+	Items []*TaskComment `json:"items"`
+	Total uint64         `json:"total"`
+}
+
+type TaskActivityKind string
+
+const (
+	TaskActivityKindComment      TaskActivityKind = "comment"
+	TaskActivityKindStatus       TaskActivityKind = "status"
+	TaskActivityKindReassignment TaskActivityKind = "reassignment"
+	TaskActivityKindFile         TaskActivityKind = "file"
+)
+
+// TaskActivity is one entry of the activity feed of a task. RefID is the ID
+// of the comment, status change, audit log or file it comes from.
+type TaskActivity struct {
+	Kind      TaskActivityKind `json:"kind"`
+	RefID     int64            `json:"ref_id"`
+	ActorID   *int64           `json:"actor_id"`
+	Text      *string          `json:"text"` // Comment body or file name
+	From      *string          `json:"from"` // Previous status or assignee
+	To        *string          `json:"to"`   // New status or assignee
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+type GetTaskActivityResponse struct {
+	Items []*TaskActivity `json:"items"`
+	Total uint64          `json:"total"`
+}
+
 // Files
+// File is a document attached to a task. The content lives in MinIO under
+// ObjectKey; files registered before uploads existed have none.
 type File struct {
-	ID     int64  `json:"id"`
-	Name   string `json:"name"`
-	TaskID *int64 `json:"task_id"`
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	TaskID     *int64  `json:"task_id"`
+	ObjectKey  *string `json:"object_key,omitempty"`
+	Size       *int64  `json:"size,omitempty"`
+	MimeType   *string `json:"mime_type,omitempty"`
+	UploadedBy *int64  `json:"uploaded_by,omitempty"`
 	BaseModel
 }
 
+// CreateFileRequest records an uploaded object. It is filled from the
+// multipart upload, not from JSON.
 type CreateFileRequest struct {
-	Name   string `json:"name"`
-	TaskID *int64 `json:"task_id"`
+	Name       string `json:"-"`
+	TaskID     *int64 `json:"-"`
+	ObjectKey  string `json:"-"`
+	Size       int64  `json:"-"`
+	MimeType   string `json:"-"`
+	UploadedBy int64  `json:"-"`
 }
 
 type UpdateFileRequest struct {
@@ -289,15 +804,109 @@ type GetAllFilesResponse struct {
 	Total uint64  `json:"total"`
 }
 
+// Leaves
+// Leave is a request for time off. Days counts the working days it covers.
+type Leave struct {
+	ID            int64       `json:"id"`
+	UserID        int64       `json:"user_id"`
+	Type          LeaveType   `json:"type"` // Enum
+	StartDate     time.Time   `json:"start_date"`
+	EndDate       time.Time   `json:"end_date"` // Inclusive
+	Days          int         `json:"days"`
+	Reason        *string     `json:"reason"`
+	Status        LeaveStatus `json:"status"` // Enum
+	ReviewerID    *int64      `json:"reviewer_id"`
+	ReviewedAt    *time.Time  `json:"reviewed_at"`
+	ReviewComment *string     `json:"review_comment"`
+	BaseModel
+}
+
+type CreateLeaveRequest struct {
+	UserID    int64     `json:"-"` // Always the caller
+	Type      LeaveType `json:"type"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Reason    *string   `json:"reason"`
+}
+
+// ReviewLeaveRequest approves or rejects a pending leave.
+type ReviewLeaveRequest struct {
+	ID         int64       `json:"-"`
+	ReviewerID int64       `json:"-"`
+	Status     LeaveStatus `json:"-"` // Approved or rejected, set by the route
+	Comment    *string     `json:"comment"`
+}
+
+type GetAllLeavesResponse struct {
+	Items []*Leave `json:"items"`
+	Total uint64   `json:"total"`
+}
+
+// LeaveAccrual maps a leave type to the days accrued per month of
+// employment. Types missing from it are not limited by a balance.
+type LeaveAccrual map[LeaveType]float64
+
+// LeaveBalance is what is left of one leave type. Pending requests are
+// already reserved so they cannot be requested twice.
+type LeaveBalance struct {
+	Type      LeaveType `json:"type"`
+	Accrued   float64   `json:"accrued"`
+	Used      float64   `json:"used"`
+	Pending   float64   `json:"pending"`
+	Available float64   `json:"available"`
+}
+
+type LeaveBalancesResponse struct {
+	UserID int64           `json:"user_id"`
+	Items  []*LeaveBalance `json:"items"`
+}
+
+// Shifts
+// Shift is one weekday of a user's weekly work schedule.
+type Shift struct {
+	ID           int64  `json:"id"`
+	UserID       int64  `json:"user_id"`
+	Weekday      int    `json:"weekday"`    // 0 = Sunday ... 6 = Saturday
+	StartTime    string `json:"start_time"` // HH:MM in Timezone
+	EndTime      string `json:"end_time"`   // HH:MM, before StartTime for overnight shifts
+	GraceMinutes int    `json:"grace_minutes"`
+	Timezone     string `json:"timezone"` // IANA name, e.g. Asia/Tashkent
+	BaseModel
+}
+
+type CreateShiftRequest struct {
+	UserID       int64  `json:"user_id"`
+	Weekday      *int   `json:"weekday"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	GraceMinutes int    `json:"grace_minutes"`
+	Timezone     string `json:"timezone"`
+}
+
+type UpdateShiftRequest struct {
+	ID           int64   `json:"id"`
+	Weekday      *int    `json:"weekday"`
+	StartTime    *string `json:"start_time"`
+	EndTime      *string `json:"end_time"`
+	GraceMinutes *int    `json:"grace_minutes"`
+	Timezone     *string `json:"timezone"`
+}
+
+type GetAllShiftsResponse struct {
+	Items []*Shift `json:"items"`
+	Total uint64   `json:"total"`
+}
+
 // Attendance represents a single attendance record in the database.
 type Attendance struct {
-	ID        int64            `json:"id"`
-	UserID    int64            `json:"user_id"` // Foreign key to the users table
-	Date      time.Time        `json:"date"`
-	InTime    time.Time        `json:"in_time"`
-	OutTime   *time.Time       `json:"out_time"` // Nullable: Represents when the user left
-	Status    AttendanceStatus `json:"status"`   // Enum type
-	BaseModel                  // Embeds CreatedAt and UpdatedAt
+	ID         int64            `json:"id"`
+	UserID     int64            `json:"user_id"` // Foreign key to the users table
+	Date       time.Time        `json:"date"`
+	InTime     time.Time        `json:"in_time"`
+	OutTime    *time.Time       `json:"out_time"`    // Nullable: Represents when the user left
+	Status     AttendanceStatus `json:"status"`      // Enum type
+	AutoClosed bool             `json:"auto_closed"` // Closed by the nightly job because the user forgot to check out
+	BaseModel                   // Embeds CreatedAt and UpdatedAt
 }
 
 // CreateAttendanceRequest is the structure for creating a new attendance record.
@@ -320,8 +929,88 @@ type UpdateAttendanceRequest struct {
 	Status  *AttendanceStatus `json:"status" validate:"omitempty,oneof=present absent late"`
 }
 
+// ShiftRule tells when the working day starts and ends; check-ins after the
+// start plus the grace period are late.
+type ShiftRule struct {
+	Start    time.Duration  // Offset from local midnight
+	Length   time.Duration  // How long the shift lasts, zero when unknown
+	Grace    time.Duration  // Allowed delay before a check-in counts as late
+	Location *time.Location // Time zone the shift is expressed in
+}
+
+// AttendanceDay is how one calendar day of a user's schedule went.
+type AttendanceDay struct {
+	Date          time.Time         `json:"date"`
+	Scheduled     bool              `json:"scheduled"`
+	Status        *AttendanceStatus `json:"status"` // Nil for days off, leave days and days that are not over yet without a check-in
+	Leave         *LeaveType        `json:"leave"`  // Set when the day is covered by approved leave
+	ExpectedHours float64           `json:"expected_hours"`
+	WorkedHours   float64           `json:"worked_hours"`
+	FirstIn       *time.Time        `json:"first_in"` // First check-in of the day, in the shift's time zone
+}
+
+// AttendanceDaysResponse lists the days of a date range with their totals.
+type AttendanceDaysResponse struct {
+	UserID        int64            `json:"user_id"`
+	From          time.Time        `json:"from"`
+	To            time.Time        `json:"to"`
+	ExpectedHours float64          `json:"expected_hours"`
+	WorkedHours   float64          `json:"worked_hours"`
+	Items         []*AttendanceDay `json:"items"`
+}
+
+// AttendanceSummary aggregates a user's attendance over a date range.
+type AttendanceSummary struct {
+	UserID         int64   `json:"user_id"`
+	FirstName      string  `json:"first_name"`
+	LastName       string  `json:"last_name"`
+	ScheduledDays  int     `json:"scheduled_days"`
+	PresentDays    int     `json:"present_days"` // Days with a check-in, late ones included
+	LateDays       int     `json:"late_days"`
+	AbsentDays     int     `json:"absent_days"`
+	LeaveDays      int     `json:"leave_days"`
+	WorkedHours    float64 `json:"worked_hours"`
+	ExpectedHours  float64 `json:"expected_hours"`
+	OvertimeHours  float64 `json:"overtime_hours"`
+	AverageArrival *string `json:"average_arrival"` // HH:MM in the shift's time zone, nil without check-ins
+}
+
+// AttendanceReportResponse is one page of per-user attendance summaries.
+type AttendanceReportResponse struct {
+	From  time.Time            `json:"from"`
+	To    time.Time            `json:"to"`
+	Items []*AttendanceSummary `json:"items"`
+	Total uint64               `json:"total"`
+}
+
+// CloseDayResponse lists what closing a working day changed.
+type CloseDayResponse struct {
+	Date       time.Time     `json:"date"`
+	Absent     []*Attendance `json:"absent"`      // Inserted for scheduled users who never checked in
+	AutoClosed []*Attendance `json:"auto_closed"` // Open records closed on behalf of the user
+}
+
 // GetAllAttendancesResponse is the structure for listing attendance records, including total count.
 type GetAllAttendancesResponse struct {
 	Items []*Attendance `json:"items"`
 	Total uint64        `json:"total"`
 }
+
+// AuditLog records one change to a row. Before is empty for a created row and
+// After for a deleted one. ActorID is the sub of the caller's access token,
+// nil for changes made by scheduled jobs.
+type AuditLog struct {
+	ID        int64           `json:"id"`
+	ActorID   *int64          `json:"actor_id"`
+	Action    AuditAction     `json:"action"` // create, update or delete
+	Entity    string          `json:"entity"` // Table of the changed row
+	EntityID  string          `json:"entity_id"`
+	Before    json.RawMessage `json:"before" swaggertype:"object"`
+	After     json.RawMessage `json:"after" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type GetAllAuditLogsResponse struct {
+	Items []*AuditLog `json:"items"`
+	Total uint64      `json:"total"`
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -8,6 +9,35 @@ import (
 var (
 	ErrorConflict = NewErrConflict("object")
 	ErrorNotFound = NewErrNotFound("object")
+
+	ErrorUserHistory = errors.New("user still has payroll history")
+
+	ErrorAlreadyCheckedIn = NewErrConflict("open attendance record")
+	ErrorNotCheckedIn     = NewErrNotFound("open attendance record")
+
+	ErrorLeaveOverlap    = NewErrConflict("overlapping leave")
+	ErrorLeaveNotPending = errors.New("leave request is not pending")
+	ErrorLeaveBalance    = errors.New("not enough leave balance")
+	ErrorLeaveNoWorkDays = errors.New("leave covers no working days")
+
+	ErrorBonusNotPending = errors.New("bonus is not pending")
+
+	ErrorDeductionAttendance = errors.New("attendance record belongs to another employee")
+
+	ErrorPayrollEmpty = errors.New("payroll run has nothing to pay")
+
+	ErrorSalaryTransition = errors.New("salary status transition is not allowed")
+	ErrorSalaryNotPaid    = errors.New("salary is not paid")
+	ErrorSalaryContract   = errors.New("salary does not match the compensation in effect")
+
+	ErrorTaskTransition = errors.New("task status transition is not allowed")
+
+	ErrorNoExchangeRate = errors.New("no exchange rate")
+
+	ErrorPayoutNoCard     = errors.New("employee has no payout card")
+	ErrorPayoutNotAllowed = errors.New("payout is not allowed")
+	ErrorPayoutInProgress = NewErrConflict("payout in progress")
+	ErrorPaidOut          = errors.New("paid records cannot be deleted")
 )
 
 // error not found
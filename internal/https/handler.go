@@ -1,16 +1,16 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
 	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
+	"github.com/ruziba3vich/argus/internal/infrastructure/multicard"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
-	"github.com/ruziba3vich/argus/internal/pkg/token"
 	"github.com/ruziba3vich/argus/internal/postgres"
 	"github.com/ruziba3vich/argus/internal/service"
 	logger "github.com/ruziba3vich/prodonik_lgger"
@@ -21,10 +21,21 @@ import (
 // HandlerOption represents the dependencies for all handlers
 type HandlerOption struct {
 	Attendance     service.AttendanceRepoInterface
+	Audit          service.AuditRepoInterface
 	Bonuses        service.BonusesRepoInterface
+	BonusRule      service.BonusRuleRepoInterface
+	Compensation   service.CompensationRepoInterface
+	Currency       service.CurrencyRepoInterface
+	Deductions     service.DeductionsRepoInterface
 	File           service.FileRepoInterface
+	Leave          service.LeaveRepoInterface
+	Notification   service.NotificationRepoInterface
+	Payout         service.PayoutRepoInterface
+	Payroll        service.PayrollRepoInterface
 	Salary         service.SalaryRepoInterface
+	Shift          service.ShiftRepoInterface
 	Task           service.TaskRepoInterface
+	TaskComment    service.TaskCommentRepoInterface
 	User           service.UserRepoInterface
 	Logger         *logger.Logger
 	Config         *config.Config
@@ -35,8 +46,9 @@ type HandlerOption struct {
 
 	// DEPENDENCIES GO HERE
 
-	DB    *postgres.Postgres
-	MinIO *minio.MinIOClient
+	DB        *postgres.Postgres
+	MinIO     *minio.MinIOClient
+	Multicard *multicard.Client
 }
 
 type BaseHandler struct {
@@ -53,23 +65,34 @@ func (h *BaseHandler) GetAuthData(ctx context.Context) (map[string]string, bool)
 	return data, ok
 }
 
-func (h *BaseHandler) GetUserID(ctx *gin.Context) string {
-	jwtToken := ctx.Request.Header.Get("Authorization")
-
-	fmt.Println("T>>>>>>>>>>>>>>>>>>>>>> ", jwtToken, " <<<<<<<<<>>>>>>> ", h.Config.Token.SigningKey)
+// GetAuthClaims returns the string claims stored by middleware.AuthContext.
+func (h *BaseHandler) GetAuthClaims(ctx *gin.Context) (map[string]string, bool) {
+	value, exists := ctx.Get(middleware.RequestAuthKey)
+	if !exists {
+		return nil, false
+	}
 
-	claims, err := token.ExtractClaims(h.Config.Token.SigningKey, jwtToken)
+	data, ok := value.(map[string]string)
+	return data, ok
+}
 
-	if err != nil {
-		fmt.Println("error while extracting claims from token")
+// GetUserID returns the sub claim of the caller's access token, or "" if the
+// request is not authenticated.
+func (h *BaseHandler) GetUserID(ctx *gin.Context) string {
+	claims, ok := h.GetAuthClaims(ctx)
+	if !ok {
 		return ""
 	}
 
-	userID, ok := claims["sub"].(string)
-	if !ok || userID == "" {
-		fmt.Println("error while extracting userID from claims ")
+	return claims["sub"]
+}
+
+// GetUserRole returns the role claim of the caller's access token.
+func (h *BaseHandler) GetUserRole(ctx *gin.Context) entity.UserRole {
+	claims, ok := h.GetAuthClaims(ctx)
+	if !ok {
 		return ""
 	}
 
-	return userID
+	return entity.GetUserRole(claims["role"])
 }
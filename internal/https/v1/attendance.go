@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -35,8 +36,13 @@ var (
 	OK                  = Status{Code: 200, Status: "OK", Description: "Request successful"}
 	Created             = Status{Code: 201, Status: "Created", Description: "Resource created successfully"}
 	BadRequest          = Status{Code: 400, Status: "Bad Request", Description: "Invalid request data"}
+	Unauthorized        = Status{Code: 401, Status: "Unauthorized", Description: "Authentication required"}
+	Forbidden           = Status{Code: 403, Status: "Forbidden", Description: "Access to the resource is denied"}
 	NotFound            = Status{Code: 404, Status: "Not Found", Description: "Resource not found"}
+	Conflict            = Status{Code: 409, Status: "Conflict", Description: "Request conflicts with the current state of the resource"}
+	PayloadTooLarge     = Status{Code: 413, Status: "Payload Too Large", Description: "Request body exceeds the allowed size"}
 	InternalServerError = Status{Code: 500, Status: "Internal Server Error", Description: "An unexpected error occurred"}
+	BadGateway          = Status{Code: 502, Status: "Bad Gateway", Description: "An upstream service failed"}
 )
 
 var ErrorNotFound = fmt.Errorf("record not found") // Simple error simulation
@@ -48,6 +54,7 @@ type attendanceRoutes struct {
 	log                  *logger.Logger
 	cfg                  *config.Config
 	enforcer             *casbin.CachedEnforcer
+	shift                *entity.ShiftRule
 }
 
 func NewAttendanceRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
@@ -58,12 +65,24 @@ func NewAttendanceRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOp
 		enforcer:     option.Enforcer,
 	}
 
+	shift, err := helper.ParseShiftRule(option.Config.Attendance.ShiftStart, "", 0, option.Config.Attendance.Timezone)
+	if err != nil {
+		option.Logger.Error("invalid attendance shift configuration, check-ins will not be marked late", map[string]any{"error": err.Error()})
+	}
+	r.shift = shift
+
 	policies := [][]string{
-		{"admin", "/v1/attendance/", "GET"},
-		{"admin", "/v1/attendance/", "POST"},
-		{"admin", "/v1/attendance/", "PUT"},
-		{"admin", "/v1/attendance/", "DELETE"},
-		{"user", "/v1/attendance/", "GET"},
+		{"admin", "/v1/attendance", "GET"},
+		{"admin", "/v1/attendance/:id", "GET"},
+		{"admin", "/v1/attendance", "POST"},
+		{"admin", "/v1/attendance/:id", "PUT"},
+		{"admin", "/v1/attendance/:id", "DELETE"},
+		{"user", "/v1/attendance", "GET"},
+		{"user", "/v1/attendance/:id", "GET"},
+		{"user", "/v1/attendance/check-in", "POST"},
+		{"user", "/v1/attendance/check-out", "POST"},
+		{"user", "/v1/attendance/days", "GET"},
+		{"user", "/v1/attendance/report", "GET"},
 	}
 
 	for _, policy := range policies {
@@ -75,11 +94,15 @@ func NewAttendanceRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOp
 
 	attendanceGroup := apiV1Group.Group("/attendance")
 	{
-		// attendanceGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger)) // Uncomment if you have an Authorizer middleware
+		attendanceGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		attendanceGroup.POST("", r.createAttendance)
+		attendanceGroup.POST("/check-in", r.checkIn)
+		attendanceGroup.POST("/check-out", r.checkOut)
+		attendanceGroup.GET("/days", r.getAttendanceDays)
+		attendanceGroup.GET("/report", r.getAttendanceReport)
 		attendanceGroup.GET("/:id", r.getAttendanceByID)
-		attendanceGroup.GET("/", r.getAllAttendances)
+		attendanceGroup.GET("", r.getAllAttendances)
 		attendanceGroup.PUT("/:id", r.updateAttendance)
 		attendanceGroup.DELETE("/:id", r.deleteAttendance)
 	}
@@ -185,6 +208,12 @@ func (r *attendanceRoutes) getAttendanceByID(c *gin.Context) {
 		return
 	}
 
+	// Plain users may only see their own records
+	if r.GetUserRole(c) == entity.UserRoleUser && strconv.FormatInt(attendance.UserID, 10) != r.GetUserID(c) {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Attendance record with ID %d not found", id), nil)
+		return
+	}
+
 	r.handleResponse(c, OK, nil, attendance)
 }
 
@@ -232,6 +261,11 @@ func (r *attendanceRoutes) getAllAttendances(c *gin.Context) {
 		filter["status"] = status
 	}
 
+	// Plain users may only list their own records, whatever user_id they asked for
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		filter["user_id"] = r.GetUserID(c)
+	}
+
 	offset := (page - 1) * limit
 	if offset < 0 {
 		offset = 0 // Ensure offset is not negative
@@ -327,3 +361,204 @@ func (r *attendanceRoutes) deleteAttendance(c *gin.Context) {
 
 	r.handleResponse(c, OK, "Attendance record deleted successfully", nil)
 }
+
+// @Router /attendance/check-in [post]
+// @Summary Check in
+// @Description Opens today's attendance record of the authenticated user with the server time. The first check-in after the shift start is marked late. A record still open from an earlier day has to be checked out first
+// @Tags ATTENDANCE
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} Response{data=entity.Attendance} "Checked in successfully"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 409 {object} Response{data=string} "Conflict - Already checked in"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *attendanceRoutes) checkIn(c *gin.Context) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	attendance, err := r.attendanceUC.CheckIn(c, userID, r.shift)
+	if err != nil {
+		if errors.Is(err, entity.ErrorAlreadyCheckedIn) {
+			r.handleResponse(c, Conflict, "Already checked in, check out first", nil)
+			return
+		}
+		r.log.Error("Error while checking in", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error while checking in", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Checked in successfully", attendance)
+}
+
+// @Router /attendance/check-out [post]
+// @Summary Check out
+// @Description Closes the open attendance record of the authenticated user with the server time
+// @Tags ATTENDANCE
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.Attendance} "Checked out successfully"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - No open attendance record"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *attendanceRoutes) checkOut(c *gin.Context) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	attendance, err := r.attendanceUC.CheckOut(c, userID)
+	if err != nil {
+		if errors.Is(err, entity.ErrorNotCheckedIn) {
+			r.handleResponse(c, NotFound, "No open attendance record, check in first", nil)
+			return
+		}
+		r.log.Error("Error while checking out", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error while checking out", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Checked out successfully", attendance)
+}
+
+// maxReportDays bounds the date ranges accepted by the attendance reports.
+const maxReportDays = 366
+
+// parseDateRange reads the from and to query parameters (YYYY-MM-DD). It
+// defaults to the current month up to today and returns a message when the
+// range is invalid.
+func parseDateRange(c *gin.Context) (time.Time, time.Time, string) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			return from, to, "Invalid from format. Use YYYY-MM-DD"
+		}
+		from = parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			return from, to, "Invalid to format. Use YYYY-MM-DD"
+		}
+		to = parsed
+	}
+
+	if to.Before(from) {
+		return from, to, "Invalid date range, to is before from"
+	}
+	if to.Sub(from) >= maxReportDays*24*time.Hour {
+		return from, to, fmt.Sprintf("Date range is too long, at most %d days are allowed", maxReportDays)
+	}
+
+	return from, to, ""
+}
+
+// @Router /attendance/days [get]
+// @Summary Get attendance by day
+// @Description Classifies every day of the range as present, late or absent against the user's work schedule and compares worked with expected hours. Plain users always get their own days
+// @Tags ATTENDANCE
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "User ID, required for admins"
+// @Param from query string false "First day (YYYY-MM-DD), defaults to the start of the current month"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} Response{data=entity.AttendanceDaysResponse} "Attendance by day"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *attendanceRoutes) getAttendanceDays(c *gin.Context) {
+	var userID int64
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		id, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+		if err != nil || id <= 0 {
+			r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+			return
+		}
+		userID = id
+	} else {
+		id, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+		if err != nil || id <= 0 {
+			r.handleResponse(c, BadRequest, "Missing or invalid user_id", nil)
+			return
+		}
+		userID = id
+	}
+
+	from, to, msg := parseDateRange(c)
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	days, err := r.attendanceUC.Days(c, userID, from, to)
+	if err != nil {
+		r.log.Error("Error while getting attendance days", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving attendance days", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, days)
+}
+
+// @Router /attendance/report [get]
+// @Summary Get attendance summary report
+// @Description Summarises each user's attendance over the range: present, late, absent and leave days, worked, expected and overtime hours and the average arrival time. Plain users always get their own summary
+// @Tags ATTENDANCE
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by User ID"
+// @Param from query string false "First day (YYYY-MM-DD), defaults to the start of the current month"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of users per page" default(10)
+// @Success 200 {object} Response{data=entity.AttendanceReportResponse} "Attendance summary report"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *attendanceRoutes) getAttendanceReport(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+
+	filter := make(map[string]string)
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+		filter["id"] = userIDStr
+	}
+
+	// Plain users may only see their own summary, whatever user_id they asked for
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		filter["id"] = r.GetUserID(c)
+	}
+
+	from, to, msg := parseDateRange(c)
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	report, err := r.attendanceUC.Report(c, uint64(limit), uint64(offset), filter, from, to)
+	if err != nil {
+		r.log.Error("Error while building attendance report", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error building attendance report", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, report)
+}
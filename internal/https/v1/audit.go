@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type auditRoutes struct {
+	handlers.BaseHandler
+	auditUC  service.AuditRepoInterface
+	log      *logger.Logger
+	cfg      *config.Config
+	enforcer *casbin.CachedEnforcer
+}
+
+// NewAuditRoutes sets up the routes that read the audit trail.
+func NewAuditRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &auditRoutes{
+		auditUC:  option.Audit,
+		log:      option.Logger,
+		cfg:      option.Config,
+		enforcer: option.Enforcer,
+	}
+
+	// Define authorization policies for audit endpoints
+	policies := [][]string{
+		{"super_admin", "/v1/audit-logs", "GET"},
+		{"super_admin", "/v1/audit-logs/:id", "GET"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during audit enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	auditGroup := apiV1Group.Group("/audit-logs")
+	{
+		auditGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		auditGroup.GET("", r.getAllAuditLogs)
+		auditGroup.GET("/:id", r.getAuditLogByID)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *auditRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// @Router /audit-logs [get]
+// @Summary Get the audit trail
+// @Description Lists who created, updated, deleted, restored or purged what, newest first, with the row as it was before and after the change (Super admins only)
+// @Tags AUDIT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "Filter by the user who made the change"
+// @Param action query string false "Filter by Action (create, update, delete, restore, purge)"
+// @Param entity query string false "Filter by the table of the changed row, e.g. salaries"
+// @Param entity_id query string false "Filter by the key of the changed row"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllAuditLogsResponse} "Successfully retrieved audit logs"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *auditRoutes) getAllAuditLogs(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if actorIDStr := c.Query("actor_id"); actorIDStr != "" {
+		if _, err := strconv.ParseInt(actorIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid actor_id format", nil)
+			return
+		}
+		filter["actor_id"] = actorIDStr
+	}
+	if action := c.Query("action"); action != "" {
+		switch entity.AuditAction(action) {
+		case entity.AuditActionCreate, entity.AuditActionUpdate, entity.AuditActionDelete,
+			entity.AuditActionRestore, entity.AuditActionPurge:
+			filter["action"] = action
+		default:
+			r.handleResponse(c, BadRequest, "Invalid action, expected create, update, delete, restore or purge", nil)
+			return
+		}
+	}
+	if entityName := strings.TrimSpace(c.Query("entity")); entityName != "" {
+		filter["entity"] = entityName
+	}
+	if entityID := strings.TrimSpace(c.Query("entity_id")); entityID != "" {
+		filter["entity_id"] = entityID
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(time.DateOnly, fromStr)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid from format. Use YYYY-MM-DD", nil)
+			return
+		}
+		from = &parsed
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(time.DateOnly, toStr)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid to format. Use YYYY-MM-DD", nil)
+			return
+		}
+		to = &parsed
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		r.handleResponse(c, BadRequest, "Invalid date range, to is before from", nil)
+		return
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	auditLogs, err := r.auditUC.List(c, uint64(limit), uint64(offset), filter, from, to)
+	if err != nil {
+		r.log.Error("Error while getting audit logs", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving audit logs", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, auditLogs)
+}
+
+// @Router /audit-logs/{id} [get]
+// @Summary Get an audit log by ID
+// @Description Retrieves a single entry of the audit trail (Super admins only)
+// @Tags AUDIT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Audit log ID"
+// @Success 200 {object} Response{data=entity.AuditLog} "Audit log details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 404 {object} Response{data=string} "Not Found - Audit log not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *auditRoutes) getAuditLogByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid audit log ID format", nil)
+		return
+	}
+
+	auditLog, err := r.auditUC.Get(c, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "no audit log found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Audit log with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting audit log by ID", map[string]any{"error": err.Error(), "audit_log_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving audit log", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, auditLog)
+}
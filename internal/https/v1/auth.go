@@ -0,0 +1,287 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/pkg/token"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type authRoutes struct {
+	handlers.BaseHandler
+	userUC service.UserRepoInterface
+	log    *logger.Logger
+	cfg    *config.Config
+}
+
+// NewAuthRoutes sets up the public authentication routes.
+func NewAuthRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &authRoutes{
+		userUC: option.User,
+		log:    option.Logger,
+		cfg:    option.Config,
+	}
+
+	authGroup := apiV1Group.Group("/auth")
+	{
+		authGroup.POST("/register", r.register)
+		authGroup.POST("/login", r.login)
+		authGroup.POST("/refresh", r.refresh)
+		authGroup.POST("/logout", r.logout)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *authRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// issueTokens generates a new access/refresh pair for the user and stores the
+// hash of the refresh token, invalidating any previously issued one.
+func (r *authRoutes) issueTokens(c *gin.Context, user *entity.User) (*entity.AuthResponse, error) {
+	accessToken, refreshToken, err := token.GenerateToken(r.cfg, strconv.FormatInt(user.ID, 10), "access", map[string]interface{}{
+		"role": string(user.Role),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while generating tokens: %w", err)
+	}
+
+	hashedRefreshToken := helper.HashToken(refreshToken)
+	if err := r.userUC.Update(c, &entity.UpdateUserRequest{
+		ID:                 user.ID,
+		HashedRefreshToken: &hashedRefreshToken,
+	}); err != nil {
+		return nil, fmt.Errorf("error while storing refresh token: %w", err)
+	}
+
+	return &entity.AuthResponse{
+		AccessToken:  entity.AccessToken{AccessToken: accessToken},
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// @Router /auth/register [post]
+// @Summary Register a new user
+// @Description Creates a new account with the user role and returns an access/refresh token pair
+// @Tags AUTH
+// @Accept json
+// @Produce json
+// @Param user body entity.RegisterRequest true "Registration details"
+// @Success 201 {object} Response{data=entity.AuthResponse} "User registered successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or user already exists"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *authRoutes) register(c *gin.Context) {
+	var req entity.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	createdUser, err := r.userUC.Create(c, &entity.CreateUserRequest{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Role:      entity.UserRoleUser,
+		Email:     req.Email,
+		Phone:     req.PhoneNumber,
+		Password:  req.Password,
+	})
+	if err != nil {
+		r.log.Error("Error while registering user", map[string]any{"error": err.Error()})
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, BadRequest, "User with this email or phone already exists", err.Error())
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while registering user", err.Error())
+		return
+	}
+
+	tokens, err := r.issueTokens(c, createdUser)
+	if err != nil {
+		r.log.Error("Error while issuing tokens", map[string]any{"error": err.Error(), "user_id": createdUser.ID})
+		r.handleResponse(c, InternalServerError, "Error while issuing tokens", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "User registered successfully", tokens)
+}
+
+// @Router /auth/login [post]
+// @Summary Log in
+// @Description Verifies the email and password and returns an access/refresh token pair
+// @Tags AUTH
+// @Accept json
+// @Produce json
+// @Param credentials body entity.LoginRequest true "Login credentials"
+// @Success 200 {object} Response{data=entity.AuthResponse} "Logged in successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input"
+// @Failure 401 {object} Response{data=string} "Unauthorized - Invalid email or password"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *authRoutes) login(c *gin.Context) {
+	var req entity.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	user, err := r.userUC.Get(c, map[string]string{"email": req.Email})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, Unauthorized, "Invalid email or password", nil)
+			return
+		}
+		r.log.Error("Error while getting user by email", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while logging in", err.Error())
+		return
+	}
+
+	if err := helper.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		r.handleResponse(c, Unauthorized, "Invalid email or password", nil)
+		return
+	}
+
+	tokens, err := r.issueTokens(c, user)
+	if err != nil {
+		r.log.Error("Error while issuing tokens", map[string]any{"error": err.Error(), "user_id": user.ID})
+		r.handleResponse(c, InternalServerError, "Error while issuing tokens", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Logged in successfully", tokens)
+}
+
+// @Router /auth/refresh [post]
+// @Summary Refresh tokens
+// @Description Exchanges a valid refresh token for a new access/refresh token pair. The used refresh token is revoked.
+// @Tags AUTH
+// @Accept json
+// @Produce json
+// @Param token body entity.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} Response{data=entity.AuthResponse} "Tokens refreshed successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input"
+// @Failure 401 {object} Response{data=string} "Unauthorized - Invalid or revoked refresh token"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *authRoutes) refresh(c *gin.Context) {
+	var req entity.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	claims, err := token.ParseJwtToken(req.RefreshToken, r.cfg.Token.SigningKey)
+	if err != nil {
+		r.handleResponse(c, Unauthorized, "Invalid refresh token", err.Error())
+		return
+	}
+
+	// access tokens carry a type claim, refresh tokens do not
+	if _, isAccess := claims["type"]; isAccess {
+		r.handleResponse(c, Unauthorized, "Invalid refresh token", nil)
+		return
+	}
+
+	sub, ok := claims["sub"].(string)
+	if !ok || sub == "" {
+		r.handleResponse(c, Unauthorized, "Invalid refresh token", nil)
+		return
+	}
+
+	user, err := r.userUC.Get(c, map[string]string{"id": sub})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, Unauthorized, "Invalid refresh token", nil)
+			return
+		}
+		r.log.Error("Error while getting user by ID", map[string]any{"error": err.Error(), "user_id": sub})
+		r.handleResponse(c, InternalServerError, "Error while refreshing tokens", err.Error())
+		return
+	}
+
+	if user.HashedRefreshToken == nil || *user.HashedRefreshToken != helper.HashToken(req.RefreshToken) {
+		r.handleResponse(c, Unauthorized, "Refresh token has been revoked", nil)
+		return
+	}
+
+	tokens, err := r.issueTokens(c, user)
+	if err != nil {
+		r.log.Error("Error while issuing tokens", map[string]any{"error": err.Error(), "user_id": user.ID})
+		r.handleResponse(c, InternalServerError, "Error while issuing tokens", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Tokens refreshed successfully", tokens)
+}
+
+// @Router /auth/logout [post]
+// @Summary Log out
+// @Description Revokes the caller's refresh token
+// @Tags AUTH
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=string} "Logged out successfully"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *authRoutes) logout(c *gin.Context) {
+	// logout is outside the authorizer, so a refresh token would get here
+	// too; only access tokens carry the access type claim
+	claims, ok := r.GetAuthClaims(c)
+	if !ok || claims["type"] != "access" {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	userID, err := strconv.ParseInt(claims["sub"], 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	revoked := ""
+	if err := r.userUC.Update(c, &entity.UpdateUserRequest{
+		ID:                 userID,
+		HashedRefreshToken: &revoked,
+	}); err != nil {
+		r.log.Error("Error while revoking refresh token", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error while logging out", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Logged out successfully", nil)
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -31,6 +32,7 @@ type HandlerOption struct {
 type bonusesRoutes struct {
 	handlers.BaseHandler // Inherit common methods like handleResponse
 	bonusesUC            service.BonusesRepoInterface
+	currencyUC           service.CurrencyRepoInterface
 	log                  *logger.Logger
 	cfg                  *config.Config
 	enforcer             *casbin.CachedEnforcer
@@ -39,18 +41,25 @@ type bonusesRoutes struct {
 // NewBonusesRoutes sets up the routes for bonus management.
 func NewBonusesRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
 	r := &bonusesRoutes{
-		bonusesUC: option.Bonuses,
-		log:       option.Logger,
-		cfg:       option.Config,
-		enforcer:  option.Enforcer,
+		bonusesUC:  option.Bonuses,
+		currencyUC: option.Currency,
+		log:        option.Logger,
+		cfg:        option.Config,
+		enforcer:   option.Enforcer,
 	}
 
 	// Define authorization policies for bonus endpoints
 	policies := [][]string{
-		{"admin", "/v1/bonuses/", "GET"},
-		{"admin", "/v1/bonuses/", "POST"},
-		{"admin", "/v1/bonuses/", "PUT"},
-		{"admin", "/v1/bonuses/", "DELETE"},
+		{"admin", "/v1/bonuses", "GET"},
+		{"admin", "/v1/bonuses/report", "GET"},
+		{"admin", "/v1/bonuses/:id", "GET"},
+		{"admin", "/v1/bonuses", "POST"},
+		{"admin", "/v1/bonuses/:id", "PUT"},
+		{"admin", "/v1/bonuses/:id", "DELETE"},
+		{"admin", "/v1/bonuses/:id/restore", "PUT"},
+		{"admin", "/v1/bonuses/:id/purge", "DELETE"},
+		{"super_admin", "/v1/bonuses/:id/approve", "PUT"},
+		{"super_admin", "/v1/bonuses/:id/reject", "PUT"},
 		// Assuming regular users cannot see a list of all bonuses
 		// A separate endpoint like /users/me/bonuses might be used for that
 	}
@@ -64,13 +73,18 @@ func NewBonusesRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOptio
 
 	bonusesGroup := apiV1Group.Group("/bonuses")
 	{
-		// bonusesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+		bonusesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		bonusesGroup.POST("", r.createBonus)
+		bonusesGroup.GET("/report", r.getBonusReport)
 		bonusesGroup.GET("/:id", r.getBonusByID)
 		bonusesGroup.GET("", r.getAllBonuses)
 		bonusesGroup.PUT("/:id", r.updateBonus)
 		bonusesGroup.DELETE("/:id", r.deleteBonus)
+		bonusesGroup.PUT("/:id/restore", r.restoreBonus)
+		bonusesGroup.DELETE("/:id/purge", r.purgeBonus)
+		bonusesGroup.PUT("/:id/approve", r.approveBonus)
+		bonusesGroup.PUT("/:id/reject", r.rejectBonus)
 	}
 }
 
@@ -106,18 +120,36 @@ func (r *bonusesRoutes) handleResponse(c *gin.Context, status Status, customMess
 	})
 }
 
+// callerID resolves the authenticated user from the sub claim and writes a 401
+// response when it is missing.
+func (r *bonusesRoutes) callerID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return 0, false
+	}
+
+	return userID, true
+}
+
 // @Router /bonuses [post]
-// @Summary Create a new bonus
-// @Description Creates a new bonus record for a user
+// @Summary Propose a new bonus
+// @Description Proposes a bonus for a user. It stays pending, and is not paid or counted by payroll and reports, until a super admin approves it
 // @Tags BONUSES
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param bonus body entity.CreateBonusRequest true "Bonus details"
-// @Success 201 {object} Response{data=entity.Bonus} "Bonus created successfully"
+// @Success 201 {object} Response{data=entity.Bonus} "Bonus proposed successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *bonusesRoutes) createBonus(c *gin.Context) {
+	proposedBy, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
 	var req entity.CreateBonusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
@@ -129,6 +161,8 @@ func (r *bonusesRoutes) createBonus(c *gin.Context) {
 		return
 	}
 
+	req.ProposedBy = proposedBy
+
 	createdBonus, err := r.bonusesUC.Create(c, &req)
 	if err != nil {
 		r.log.Error("Error while creating bonus", map[string]any{"error": err.Error()})
@@ -136,7 +170,7 @@ func (r *bonusesRoutes) createBonus(c *gin.Context) {
 		return
 	}
 
-	r.handleResponse(c, Created, "Bonus created successfully", createdBonus)
+	r.handleResponse(c, Created, "Bonus proposed successfully", createdBonus)
 }
 
 // @Router /bonuses/{id} [get]
@@ -183,7 +217,9 @@ func (r *bonusesRoutes) getBonusByID(c *gin.Context) {
 // @Security BearerAuth
 // @Param user_id query int false "Filter by User ID"
 // @Param superadminid query int false "Filter by Super Admin ID"
+// @Param status query string false "Filter by Status (pending, approved, rejected)"
 // @Param search query string false "Search by reason"
+// @Param deleted query bool false "List the bonuses in the trash instead"
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of records per page" default(10)
 // @Success 200 {object} Response{data=entity.GetAllBonusesResponse} "Successfully retrieved bonuses"
@@ -210,6 +246,24 @@ func (r *bonusesRoutes) getAllBonuses(c *gin.Context) {
 			return
 		}
 	}
+	if status := c.Query("status"); status != "" {
+		switch entity.BonusStatus(status) {
+		case entity.BonusStatusPending, entity.BonusStatusApproved, entity.BonusStatusRejected:
+			filter["status"] = status
+		default:
+			r.handleResponse(c, BadRequest, "Invalid status, expected pending, approved or rejected", nil)
+			return
+		}
+	}
+
+	if deleted := c.Query("deleted"); deleted != "" {
+		showDeleted, err := strconv.ParseBool(deleted)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid deleted format", nil)
+			return
+		}
+		filter["deleted"] = strconv.FormatBool(showDeleted)
+	}
 
 	offset := (page - 1) * limit
 	if offset < 0 {
@@ -228,7 +282,7 @@ func (r *bonusesRoutes) getAllBonuses(c *gin.Context) {
 
 // @Router /bonuses/{id} [put]
 // @Summary Update a bonus record
-// @Description Updates an existing bonus by its ID.
+// @Description Updates a pending bonus by its ID. Reviewed bonuses cannot be changed.
 // @Tags BONUSES
 // @Accept json
 // @Produce json
@@ -236,7 +290,7 @@ func (r *bonusesRoutes) getAllBonuses(c *gin.Context) {
 // @Param id path int true "Bonus ID to update"
 // @Param bonus body entity.UpdateBonusRequest true "Updated bonus details"
 // @Success 200 {object} Response{data=string} "Bonus updated successfully"
-// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID, request body or bonus not pending"
 // @Failure 404 {object} Response{data=string} "Not Found - Bonus not found"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *bonusesRoutes) updateBonus(c *gin.Context) {
@@ -267,6 +321,10 @@ func (r *bonusesRoutes) updateBonus(c *gin.Context) {
 			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
 			return
 		}
+		if errors.Is(err, entity.ErrorBonusNotPending) {
+			r.handleResponse(c, BadRequest, "Only pending bonuses can be updated", nil)
+			return
+		}
 		r.handleResponse(c, InternalServerError, "Error while updating bonus", err.Error())
 		return
 	}
@@ -276,7 +334,7 @@ func (r *bonusesRoutes) updateBonus(c *gin.Context) {
 
 // @Router /bonuses/{id} [delete]
 // @Summary Delete a bonus
-// @Description Deletes a bonus by its unique ID
+// @Description Moves a bonus to the trash, from where it can be restored or purged. Bonuses paid out on their own or by a payroll run are kept
 // @Tags BONUSES
 // @Accept json
 // @Produce json
@@ -285,6 +343,7 @@ func (r *bonusesRoutes) updateBonus(c *gin.Context) {
 // @Success 200 {object} Response{data=string} "Bonus deleted successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
 // @Failure 404 {object} Response{data=string} "Not Found - Bonus not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Bonus is paid out"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *bonusesRoutes) deleteBonus(c *gin.Context) {
 	idStr := strings.TrimSpace(c.Param("id"))
@@ -302,9 +361,224 @@ func (r *bonusesRoutes) deleteBonus(c *gin.Context) {
 			r.handleResponse(c, NotFound, fmt.Sprintf("Bonus with ID %d not found", id), nil)
 			return
 		}
+		if errors.Is(err, entity.ErrorPaidOut) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Bonus with ID %d is paid out and cannot be deleted", id), err.Error())
+			return
+		}
 		r.handleResponse(c, InternalServerError, "Error while deleting bonus", err.Error())
 		return
 	}
 
 	r.handleResponse(c, OK, "Bonus deleted successfully", nil)
 }
+
+// @Router /bonuses/{id}/restore [put]
+// @Summary Restore a deleted bonus
+// @Description Takes a bonus out of the trash
+// @Tags BONUSES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus ID to restore"
+// @Success 200 {object} Response{data=string} "Bonus restored successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus not in the trash"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusesRoutes) restoreBonus(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus ID format", nil)
+		return
+	}
+
+	err = r.bonusesUC.Restore(c, id)
+	if err != nil {
+		r.log.Error("Error while restoring bonus", map[string]any{"error": err.Error(), "bonus_id": id})
+
+		if strings.Contains(err.Error(), "no deleted bonus found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted bonus with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while restoring bonus", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Bonus restored successfully", nil)
+}
+
+// @Router /bonuses/{id}/purge [delete]
+// @Summary Purge a deleted bonus
+// @Description Removes a bonus in the trash for good. Bonuses paid out on their own or by a payroll run are kept
+// @Tags BONUSES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus ID to purge"
+// @Success 200 {object} Response{data=string} "Bonus purged successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus not in the trash"
+// @Failure 409 {object} Response{data=string} "Conflict - Bonus is paid out"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusesRoutes) purgeBonus(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus ID format", nil)
+		return
+	}
+
+	err = r.bonusesUC.Purge(c, id)
+	if err != nil {
+		r.log.Error("Error while purging bonus", map[string]any{"error": err.Error(), "bonus_id": id})
+
+		if strings.Contains(err.Error(), "no deleted bonus found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted bonus with ID %d not found", id), nil)
+			return
+		}
+		if errors.Is(err, entity.ErrorPaidOut) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Bonus with ID %d is paid out and cannot be purged", id), err.Error())
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while purging bonus", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Bonus purged successfully", nil)
+}
+
+// @Router /bonuses/report [get]
+// @Summary Get a bonus payout report
+// @Description Totals the approved bonuses granted in the range in one reporting currency. Each bonus is converted at the latest exchange rate known on the day it was granted (Admins only)
+// @Tags BONUSES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Reporting currency" default(USD)
+// @Param from query string false "First day (YYYY-MM-DD), defaults to the start of the current month"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param user_id query int false "Filter by User ID"
+// @Success 200 {object} Response{data=entity.PayoutReport} "Bonus payout report"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters or missing exchange rate"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusesRoutes) getBonusReport(c *gin.Context) {
+	filter := make(map[string]string)
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+		filter["user_id"] = userIDStr
+	}
+
+	from, to, msg := parseDateRange(c)
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	currency := entity.Currency(strings.ToUpper(c.DefaultQuery("currency", string(entity.CurrencyUSD))))
+	msg, err := checkCurrency(c, r.currencyUC, currency)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": currency})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	report, err := r.bonusesUC.Report(c, filter, from, to, currency)
+	if err != nil {
+		if errors.Is(err, entity.ErrorNoExchangeRate) {
+			r.handleResponse(c, BadRequest, "Missing exchange rate: "+err.Error(), nil)
+			return
+		}
+		r.log.Error("Error while building bonus report", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error building bonus report", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, report)
+}
+
+// @Router /bonuses/{id}/approve [put]
+// @Summary Approve a bonus
+// @Description Approves a pending bonus so payroll, reports and payouts count it (Super admins only)
+// @Tags BONUSES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus ID"
+// @Param review body entity.ReviewBonusRequest false "Optional review comment"
+// @Success 200 {object} Response{data=entity.Bonus} "Bonus approved"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or not pending"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusesRoutes) approveBonus(c *gin.Context) {
+	r.reviewBonus(c, entity.BonusStatusApproved)
+}
+
+// @Router /bonuses/{id}/reject [put]
+// @Summary Reject a bonus
+// @Description Rejects a pending bonus (Super admins only)
+// @Tags BONUSES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus ID"
+// @Param review body entity.ReviewBonusRequest false "Optional review comment"
+// @Success 200 {object} Response{data=entity.Bonus} "Bonus rejected"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or not pending"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusesRoutes) rejectBonus(c *gin.Context) {
+	r.reviewBonus(c, entity.BonusStatusRejected)
+}
+
+func (r *bonusesRoutes) reviewBonus(c *gin.Context, status entity.BonusStatus) {
+	superAdminID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus ID format", nil)
+		return
+	}
+
+	var req entity.ReviewBonusRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	req.ID = id
+	req.SuperAdminID = superAdminID
+	req.Status = status
+
+	bonus, err := r.bonusesUC.Review(c, &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no bonus found with ID"):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Bonus with ID %d not found", id), nil)
+		case errors.Is(err, entity.ErrorBonusNotPending):
+			r.handleResponse(c, BadRequest, "Only pending bonuses can be reviewed", nil)
+		default:
+			r.log.Error("Error while reviewing bonus", map[string]any{"error": err.Error(), "bonus_id": id})
+			r.handleResponse(c, InternalServerError, "Error while reviewing bonus", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, fmt.Sprintf("Bonus %s", status), bonus)
+}
@@ -0,0 +1,407 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type bonusRuleRoutes struct {
+	handlers.BaseHandler
+	bonusRuleUC service.BonusRuleRepoInterface
+	currencyUC  service.CurrencyRepoInterface
+	log         *logger.Logger
+	cfg         *config.Config
+	enforcer    *casbin.CachedEnforcer
+}
+
+// NewBonusRuleRoutes sets up the routes for the rules that propose bonuses.
+func NewBonusRuleRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &bonusRuleRoutes{
+		bonusRuleUC: option.BonusRule,
+		currencyUC:  option.Currency,
+		log:         option.Logger,
+		cfg:         option.Config,
+		enforcer:    option.Enforcer,
+	}
+
+	// Define authorization policies for bonus rule endpoints
+	policies := [][]string{
+		{"admin", "/v1/bonus-rules", "GET"},
+		{"admin", "/v1/bonus-rules/:id", "GET"},
+		{"admin", "/v1/bonus-rules", "POST"},
+		{"admin", "/v1/bonus-rules/evaluate", "POST"},
+		{"admin", "/v1/bonus-rules/:id", "PUT"},
+		{"admin", "/v1/bonus-rules/:id", "DELETE"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during bonus rules enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	bonusRulesGroup := apiV1Group.Group("/bonus-rules")
+	{
+		bonusRulesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		bonusRulesGroup.POST("", r.createBonusRule)
+		bonusRulesGroup.POST("/evaluate", r.evaluateBonusRules)
+		bonusRulesGroup.GET("/:id", r.getBonusRuleByID)
+		bonusRulesGroup.GET("", r.getAllBonusRules)
+		bonusRulesGroup.PUT("/:id", r.updateBonusRule)
+		bonusRulesGroup.DELETE("/:id", r.deleteBonusRule)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *bonusRuleRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// validateBonusRule returns a message describing the first invalid field, or
+// an empty string when the given fields are valid. Nil fields are skipped.
+func validateBonusRule(kind *entity.BonusRuleKind, threshold *int, amount *float64) string {
+	if kind != nil {
+		switch *kind {
+		case entity.BonusRuleKindPerfectAttendance, entity.BonusRuleKindZeroLateDays, entity.BonusRuleKindHighPriorityTasks:
+		default:
+			return "Invalid kind, expected perfect_attendance, zero_late_days or high_priority_tasks"
+		}
+	}
+	if threshold != nil && *threshold < 1 {
+		return "Threshold must be at least 1"
+	}
+	if amount != nil && *amount <= 0 {
+		return "Amount must be greater than zero"
+	}
+
+	return ""
+}
+
+// knownCurrency reports whether code is a stored currency, writing an error
+// response when it is not.
+func (r *bonusRuleRoutes) knownCurrency(c *gin.Context, code entity.Currency) bool {
+	msg, err := checkCurrency(c, r.currencyUC, code)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": code})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return false
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return false
+	}
+
+	return true
+}
+
+// @Router /bonus-rules [post]
+// @Summary Create a bonus rule
+// @Description Creates a rule that proposes a bonus to every employee meeting it over a month. Threshold is the number of days attended for perfect_attendance and zero_late_days, and of high-priority tasks completed before their due date for high_priority_tasks
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rule body entity.CreateBonusRuleRequest true "Bonus rule details"
+// @Success 201 {object} Response{data=entity.BonusRule} "Bonus rule created successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) createBonusRule(c *gin.Context) {
+	adminID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || adminID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	var req entity.CreateBonusRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.Kind == "" || req.Amount == 0 || req.Currency == "" {
+		r.handleResponse(c, BadRequest, "Missing required fields: name, kind, amount, currency", nil)
+		return
+	}
+	if req.Threshold == 0 {
+		req.Threshold = 1
+	}
+	if msg := validateBonusRule(&req.Kind, &req.Threshold, &req.Amount); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+	if !r.knownCurrency(c, req.Currency) {
+		return
+	}
+
+	req.AdminID = adminID
+
+	createdRule, err := r.bonusRuleUC.Create(c, &req)
+	if err != nil {
+		r.log.Error("Error while creating bonus rule", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while creating bonus rule", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Bonus rule created successfully", createdRule)
+}
+
+// @Router /bonus-rules/evaluate [post]
+// @Summary Evaluate the bonus rules
+// @Description Checks the active rules against a month of attendance and tasks and proposes pending bonuses for super admins to review. Employees already proposed a bonus by a rule for the month are skipped, so it is safe to run again
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param month query string false "Month (YYYY-MM), defaults to the previous month"
+// @Success 200 {object} Response{data=entity.BonusRuleRun} "Bonuses proposed"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid month"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) evaluateBonusRules(c *gin.Context) {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+	if monthStr := c.Query("month"); monthStr != "" {
+		parsed, err := time.Parse("2006-01", monthStr)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid month, expected YYYY-MM", nil)
+			return
+		}
+		month = parsed
+	}
+	if !month.Before(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)) {
+		r.handleResponse(c, BadRequest, "Only months that are over can be evaluated", nil)
+		return
+	}
+
+	run, err := r.bonusRuleUC.Evaluate(c, month)
+	if err != nil {
+		r.log.Error("Error while evaluating bonus rules", map[string]any{"error": err.Error(), "month": month.Format("2006-01")})
+		r.handleResponse(c, InternalServerError, "Error while evaluating bonus rules", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, fmt.Sprintf("%d bonuses proposed", len(run.Proposed)), run)
+}
+
+// @Router /bonus-rules/{id} [get]
+// @Summary Get a bonus rule by ID
+// @Description Retrieves a single bonus rule by its unique ID
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus rule ID"
+// @Success 200 {object} Response{data=entity.BonusRule} "Bonus rule details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus rule not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) getBonusRuleByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus rule ID format", nil)
+		return
+	}
+
+	rule, err := r.bonusRuleUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		r.log.Error("Error while getting bonus rule by ID", map[string]any{"error": err.Error(), "bonus_rule_id": id})
+
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Bonus rule with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error retrieving bonus rule", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, rule)
+}
+
+// @Router /bonus-rules [get]
+// @Summary Get all bonus rules
+// @Description Retrieves bonus rules with optional filtering and pagination
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind query string false "Filter by Kind (perfect_attendance, zero_late_days, high_priority_tasks)"
+// @Param active query bool false "Filter by Active"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllBonusRulesResponse} "Successfully retrieved bonus rules"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) getAllBonusRules(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if kind := c.Query("kind"); kind != "" {
+		if msg := validateBonusRule((*entity.BonusRuleKind)(&kind), nil, nil); msg != "" {
+			r.handleResponse(c, BadRequest, msg, nil)
+			return
+		}
+		filter["kind"] = kind
+	}
+	if activeStr := c.Query("active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid active format", nil)
+			return
+		}
+		filter["active"] = strconv.FormatBool(active)
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	rules, err := r.bonusRuleUC.List(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting all bonus rules", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving bonus rules", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, rules)
+}
+
+// @Router /bonus-rules/{id} [put]
+// @Summary Update a bonus rule
+// @Description Updates an existing bonus rule by its ID. Bonuses it already proposed keep their amount
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus rule ID to update"
+// @Param rule body entity.UpdateBonusRuleRequest true "Updated bonus rule details"
+// @Success 200 {object} Response{data=string} "Bonus rule updated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus rule not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) updateBonusRule(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus rule ID format", nil)
+		return
+	}
+
+	var req entity.UpdateBonusRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.Name != nil {
+		if *req.Name = strings.TrimSpace(*req.Name); *req.Name == "" {
+			r.handleResponse(c, BadRequest, "Name cannot be empty", nil)
+			return
+		}
+	}
+	if msg := validateBonusRule(nil, req.Threshold, req.Amount); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+	if req.Currency != nil && !r.knownCurrency(c, *req.Currency) {
+		return
+	}
+
+	req.ID = id // Set the ID from the URL path
+
+	err = r.bonusRuleUC.Update(c, &req)
+	if err != nil {
+		r.log.Error("Error while updating bonus rule", map[string]any{"error": err.Error(), "bonus_rule_id": id})
+
+		if strings.Contains(err.Error(), "no bonus rule found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Bonus rule with ID %d not found", id), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "no fields to update") {
+			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while updating bonus rule", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Bonus rule updated successfully", nil)
+}
+
+// @Router /bonus-rules/{id} [delete]
+// @Summary Delete a bonus rule
+// @Description Deletes a bonus rule by its unique ID. Bonuses it already proposed are kept
+// @Tags BONUS-RULES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus rule ID to delete"
+// @Success 200 {object} Response{data=string} "Bonus rule deleted successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus rule not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *bonusRuleRoutes) deleteBonusRule(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid bonus rule ID format", nil)
+		return
+	}
+
+	err = r.bonusRuleUC.Delete(c, id)
+	if err != nil {
+		r.log.Error("Error while deleting bonus rule", map[string]any{"error": err.Error(), "bonus_rule_id": id})
+
+		if strings.Contains(err.Error(), "no bonus rule found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Bonus rule with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while deleting bonus rule", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Bonus rule deleted successfully", nil)
+}
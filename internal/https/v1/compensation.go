@@ -0,0 +1,409 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type compensationRoutes struct {
+	handlers.BaseHandler
+	compensationUC service.CompensationRepoInterface
+	currencyUC     service.CurrencyRepoInterface
+	log            *logger.Logger
+	cfg            *config.Config
+	enforcer       *casbin.CachedEnforcer
+}
+
+// NewCompensationRoutes sets up the routes for employees' compensation profiles.
+func NewCompensationRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &compensationRoutes{
+		compensationUC: option.Compensation,
+		currencyUC:     option.Currency,
+		log:            option.Logger,
+		cfg:            option.Config,
+		enforcer:       option.Enforcer,
+	}
+
+	// Define authorization policies for compensation endpoints
+	policies := [][]string{
+		{"admin", "/v1/compensations", "GET"},
+		{"admin", "/v1/compensations/current", "GET"},
+		{"admin", "/v1/compensations/:id", "GET"},
+		{"admin", "/v1/compensations", "POST"},
+		{"admin", "/v1/compensations/:id", "PUT"},
+		{"admin", "/v1/compensations/:id", "DELETE"},
+		// Users see their own compensation through /v1/me/compensation
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during compensations enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	compensationsGroup := apiV1Group.Group("/compensations")
+	{
+		compensationsGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		compensationsGroup.POST("", r.createCompensation)
+		compensationsGroup.GET("/current", r.getCurrentCompensation)
+		compensationsGroup.GET("/:id", r.getCompensationByID)
+		compensationsGroup.GET("", r.getAllCompensations)
+		compensationsGroup.PUT("/:id", r.updateCompensation)
+		compensationsGroup.DELETE("/:id", r.deleteCompensation)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *compensationRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// validateCompensation checks the optional compensation fields and returns a message for the first invalid one.
+// The currency is checked against the database by checkCurrency.
+func validateCompensation(rateType *entity.RateType, amount *float64) string {
+	if rateType != nil && *rateType != entity.RateTypeMonthly && *rateType != entity.RateTypeHourly {
+		return "Invalid rate_type, expected monthly or hourly"
+	}
+	if amount != nil && *amount <= 0 {
+		return "Invalid amount, must be positive"
+	}
+
+	return ""
+}
+
+// knownCurrency reports whether code is a stored currency, writing an error
+// response when it is not.
+func (r *compensationRoutes) knownCurrency(c *gin.Context, code entity.Currency) bool {
+	msg, err := checkCurrency(c, r.currencyUC, code)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": code})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return false
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return false
+	}
+
+	return true
+}
+
+// @Router /compensations [post]
+// @Summary Create a compensation profile
+// @Description Records what an employee earns from a date on, as a monthly amount or an hourly rate. It stays in effect until the employee's next profile starts
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param compensation body entity.CreateCompensationRequest true "Compensation details"
+// @Success 201 {object} Response{data=entity.Compensation} "Compensation created successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 409 {object} Response{data=string} "Conflict - The user already has a profile starting that day"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) createCompensation(c *gin.Context) {
+	adminID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || adminID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	var req entity.CreateCompensationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.UserID == 0 || req.RateType == "" || req.Amount == 0 || req.Currency == "" || req.EffectiveFrom.IsZero() {
+		r.handleResponse(c, BadRequest, "Missing required fields: user_id, rate_type, amount, currency, effective_from", nil)
+		return
+	}
+	if msg := validateCompensation(&req.RateType, &req.Amount); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+	if !r.knownCurrency(c, req.Currency) {
+		return
+	}
+
+	req.AdminID = adminID
+
+	createdCompensation, err := r.compensationUC.Create(c, &req)
+	if err != nil {
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("User %d already has a compensation starting on %s", req.UserID, req.EffectiveFrom.Format(time.DateOnly)), nil)
+			return
+		}
+		r.log.Error("Error while creating compensation", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while creating compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Compensation created successfully", createdCompensation)
+}
+
+// @Router /compensations/current [get]
+// @Summary Get the compensation in effect
+// @Description Retrieves the compensation profile of a user in effect on a date
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int true "User ID"
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} Response{data=entity.Compensation} "Compensation in effect"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 404 {object} Response{data=string} "Not Found - No compensation in effect"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) getCurrentCompensation(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, BadRequest, "Missing or invalid user_id", nil)
+		return
+	}
+
+	date := time.Now()
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err = time.Parse(time.DateOnly, dateStr)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid date format. Use YYYY-MM-DD", nil)
+			return
+		}
+	}
+
+	compensation, err := r.compensationUC.Current(c, userID, date)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("User %d has no compensation in effect on %s", userID, date.Format(time.DateOnly)), nil)
+			return
+		}
+		r.log.Error("Error while getting current compensation", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, compensation)
+}
+
+// @Router /compensations/{id} [get]
+// @Summary Get a compensation profile by ID
+// @Description Retrieves a single compensation profile by its unique ID
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Compensation ID"
+// @Success 200 {object} Response{data=entity.Compensation} "Compensation details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Compensation not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) getCompensationByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid compensation ID format", nil)
+		return
+	}
+
+	compensation, err := r.compensationUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		r.log.Error("Error while getting compensation by ID", map[string]any{"error": err.Error(), "compensation_id": id})
+
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Compensation with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error retrieving compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, compensation)
+}
+
+// @Router /compensations [get]
+// @Summary Get compensation profiles
+// @Description Retrieves compensation profiles ordered by user and newest first, so filtering by user gives the history of their pay
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by User ID"
+// @Param rate_type query string false "Filter by Rate Type (monthly, hourly)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllCompensationsResponse} "Successfully retrieved compensations"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) getAllCompensations(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err == nil {
+			filter["user_id"] = userIDStr
+		} else {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+	}
+	if rateType := c.Query("rate_type"); rateType != "" {
+		if msg := validateCompensation((*entity.RateType)(&rateType), nil); msg != "" {
+			r.handleResponse(c, BadRequest, msg, nil)
+			return
+		}
+		filter["rate_type"] = rateType
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	compensations, err := r.compensationUC.List(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting all compensations", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving compensations", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, compensations)
+}
+
+// @Router /compensations/{id} [put]
+// @Summary Update a compensation profile
+// @Description Updates an existing compensation profile by its ID. Record a raise as a new profile instead, so the history is kept
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Compensation ID to update"
+// @Param compensation body entity.UpdateCompensationRequest true "Updated compensation details"
+// @Success 200 {object} Response{data=string} "Compensation updated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
+// @Failure 404 {object} Response{data=string} "Not Found - Compensation not found"
+// @Failure 409 {object} Response{data=string} "Conflict - The user already has a profile starting that day"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) updateCompensation(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid compensation ID format", nil)
+		return
+	}
+
+	var req entity.UpdateCompensationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if msg := validateCompensation(req.RateType, req.Amount); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+	if req.Currency != nil && !r.knownCurrency(c, *req.Currency) {
+		return
+	}
+
+	req.ID = id // Set the ID from the URL path
+
+	err = r.compensationUC.Update(c, &req)
+	if err != nil {
+		r.log.Error("Error while updating compensation", map[string]any{"error": err.Error(), "compensation_id": id})
+
+		if strings.Contains(err.Error(), "no compensation found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Compensation with ID %d not found", id), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "no fields to update") {
+			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
+			return
+		}
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, Conflict, "The user already has a compensation starting that day", nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while updating compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Compensation updated successfully", nil)
+}
+
+// @Router /compensations/{id} [delete]
+// @Summary Delete a compensation profile
+// @Description Deletes a compensation profile by its unique ID, putting the previous profile of the user back in effect
+// @Tags COMPENSATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Compensation ID to delete"
+// @Success 200 {object} Response{data=string} "Compensation deleted successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Compensation not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *compensationRoutes) deleteCompensation(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid compensation ID format", nil)
+		return
+	}
+
+	err = r.compensationUC.Delete(c, id)
+	if err != nil {
+		r.log.Error("Error while deleting compensation", map[string]any{"error": err.Error(), "compensation_id": id})
+
+		if strings.Contains(err.Error(), "no compensation found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Compensation with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while deleting compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Compensation deleted successfully", nil)
+}
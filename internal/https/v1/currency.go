@@ -0,0 +1,289 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+// currencyCodePattern matches an ISO 4217 code such as UZS.
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type currencyRoutes struct {
+	handlers.BaseHandler
+	currencyUC service.CurrencyRepoInterface
+	log        *logger.Logger
+	cfg        *config.Config
+	enforcer   *casbin.CachedEnforcer
+}
+
+// NewCurrencyRoutes sets up the routes for currencies and exchange rates.
+func NewCurrencyRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &currencyRoutes{
+		currencyUC: option.Currency,
+		log:        option.Logger,
+		cfg:        option.Config,
+		enforcer:   option.Enforcer,
+	}
+
+	// Define authorization policies for currency endpoints
+	policies := [][]string{
+		{"user", "/v1/currencies", "GET"},
+		{"admin", "/v1/currencies", "POST"},
+		{"admin", "/v1/exchange-rates", "GET"},
+		{"admin", "/v1/exchange-rates", "POST"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during currency enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	currenciesGroup := apiV1Group.Group("/currencies")
+	{
+		currenciesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		currenciesGroup.GET("", r.getAllCurrencies)
+		currenciesGroup.POST("", r.createCurrency)
+	}
+
+	ratesGroup := apiV1Group.Group("/exchange-rates")
+	{
+		ratesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		ratesGroup.GET("", r.getAllExchangeRates)
+		ratesGroup.POST("", r.uploadExchangeRates)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *currencyRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// checkCurrency returns a message when code is not one of the currencies
+// stored in the database.
+func checkCurrency(c *gin.Context, currencyUC service.CurrencyRepoInterface, code entity.Currency) (string, error) {
+	if _, err := currencyUC.GetCurrency(c, code); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Sprintf("Unknown currency %s, add it through /v1/currencies first", code), nil
+		}
+		return "", err
+	}
+
+	return "", nil
+}
+
+// @Router /currencies [get]
+// @Summary Get currencies
+// @Description Retrieves every currency salaries, bonuses and compensations can be paid in
+// @Tags CURRENCIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.GetAllCurrenciesResponse} "Successfully retrieved currencies"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *currencyRoutes) getAllCurrencies(c *gin.Context) {
+	currencies, err := r.currencyUC.ListCurrencies(c)
+	if err != nil {
+		r.log.Error("Error while getting currencies", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving currencies", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, currencies)
+}
+
+// @Router /currencies [post]
+// @Summary Add a currency
+// @Description Adds a currency by its ISO 4217 code, such as UZS. Upload its exchange rates before reporting in it (Admins only)
+// @Tags CURRENCIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param currency body entity.CreateCurrencyRequest true "Currency details"
+// @Success 201 {object} Response{data=entity.CurrencyDetails} "Currency created successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 409 {object} Response{data=string} "Conflict - The currency already exists"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *currencyRoutes) createCurrency(c *gin.Context) {
+	var req entity.CreateCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	req.Code = entity.Currency(strings.ToUpper(strings.TrimSpace(string(req.Code))))
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Code == "" || req.Name == "" {
+		r.handleResponse(c, BadRequest, "Missing required fields: code, name", nil)
+		return
+	}
+	if !currencyCodePattern.MatchString(string(req.Code)) {
+		r.handleResponse(c, BadRequest, "Invalid code, expected three letters such as UZS", nil)
+		return
+	}
+
+	currency, err := r.currencyUC.CreateCurrency(c, &req)
+	if err != nil {
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Currency %s already exists", req.Code), nil)
+			return
+		}
+		r.log.Error("Error while creating currency", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while creating currency", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Currency created successfully", currency)
+}
+
+// @Router /exchange-rates [get]
+// @Summary Get exchange rates
+// @Description Retrieves stored exchange rates, newest first (Admins only)
+// @Tags CURRENCIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param base query string false "Filter by base currency"
+// @Param quote query string false "Filter by quote currency"
+// @Param date query string false "Filter by date (YYYY-MM-DD)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllExchangeRatesResponse} "Successfully retrieved exchange rates"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *currencyRoutes) getAllExchangeRates(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if base := c.Query("base"); base != "" {
+		filter["base"] = strings.ToUpper(base)
+	}
+	if quote := c.Query("quote"); quote != "" {
+		filter["quote"] = strings.ToUpper(quote)
+	}
+	if date := c.Query("date"); date != "" {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid date format. Use YYYY-MM-DD", nil)
+			return
+		}
+		filter["rate_date"] = date
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	rates, err := r.currencyUC.ListRates(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting exchange rates", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving exchange rates", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, rates)
+}
+
+// @Router /exchange-rates [post]
+// @Summary Upload exchange rates
+// @Description Stores how many units of each quote currency one unit of the base currency is worth on a date. Rates already stored for the same pair and date are replaced. Amounts are converted at the latest rate known on their date, through a third currency when there is no direct or inverse rate (Admins only)
+// @Tags CURRENCIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rates body entity.UploadExchangeRatesRequest true "Base currency, date and rates"
+// @Success 201 {object} Response{data=[]entity.ExchangeRate} "Exchange rates stored successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or unknown currency"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *currencyRoutes) uploadExchangeRates(c *gin.Context) {
+	var req entity.UploadExchangeRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.Base == "" || req.Date.IsZero() || len(req.Rates) == 0 {
+		r.handleResponse(c, BadRequest, "Missing required fields: base, date, rates", nil)
+		return
+	}
+
+	codes := []entity.Currency{req.Base}
+	for quote := range req.Rates {
+		codes = append(codes, quote)
+	}
+	for _, code := range codes {
+		msg, err := checkCurrency(c, r.currencyUC, code)
+		if err != nil {
+			r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": code})
+			r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+			return
+		}
+		if msg != "" {
+			r.handleResponse(c, BadRequest, msg, nil)
+			return
+		}
+	}
+	for quote, rate := range req.Rates {
+		if quote == req.Base {
+			r.handleResponse(c, BadRequest, fmt.Sprintf("Invalid rate for %s, it is the base currency", quote), nil)
+			return
+		}
+		if rate <= 0 {
+			r.handleResponse(c, BadRequest, fmt.Sprintf("Invalid rate for %s, must be positive", quote), nil)
+			return
+		}
+	}
+
+	rates, err := r.currencyUC.UploadRates(c, &req)
+	if err != nil {
+		r.log.Error("Error while uploading exchange rates", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while storing exchange rates", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Exchange rates stored successfully", rates)
+}
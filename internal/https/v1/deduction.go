@@ -0,0 +1,437 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type deductionsRoutes struct {
+	handlers.BaseHandler
+	deductionsUC service.DeductionsRepoInterface
+	currencyUC   service.CurrencyRepoInterface
+	log          *logger.Logger
+	cfg          *config.Config
+	enforcer     *casbin.CachedEnforcer
+}
+
+// NewDeductionsRoutes sets up the routes for fines, advances and lateness
+// penalties.
+func NewDeductionsRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &deductionsRoutes{
+		deductionsUC: option.Deductions,
+		currencyUC:   option.Currency,
+		log:          option.Logger,
+		cfg:          option.Config,
+		enforcer:     option.Enforcer,
+	}
+
+	// Define authorization policies for deduction endpoints
+	policies := [][]string{
+		{"admin", "/v1/deductions", "GET"},
+		{"admin", "/v1/deductions/report", "GET"},
+		{"admin", "/v1/deductions/:id", "GET"},
+		{"admin", "/v1/deductions", "POST"},
+		{"admin", "/v1/deductions/:id", "PUT"},
+		{"admin", "/v1/deductions/:id", "DELETE"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during deductions enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	deductionsGroup := apiV1Group.Group("/deductions")
+	{
+		deductionsGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		deductionsGroup.POST("", r.createDeduction)
+		deductionsGroup.GET("/report", r.getDeductionReport)
+		deductionsGroup.GET("/:id", r.getDeductionByID)
+		deductionsGroup.GET("", r.getAllDeductions)
+		deductionsGroup.PUT("/:id", r.updateDeduction)
+		deductionsGroup.DELETE("/:id", r.deleteDeduction)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *deductionsRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// callerID resolves the authenticated user from the sub claim and writes a 401
+// response when it is missing.
+func (r *deductionsRoutes) callerID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// knownCurrency reports whether code is a stored currency, writing an error
+// response when it is not.
+func (r *deductionsRoutes) knownCurrency(c *gin.Context, code entity.Currency) bool {
+	msg, err := checkCurrency(c, r.currencyUC, code)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": code})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return false
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return false
+	}
+
+	return true
+}
+
+// validDeductionKind reports whether kind is one of the known deduction kinds.
+func validDeductionKind(kind entity.DeductionKind) bool {
+	switch kind {
+	case entity.DeductionKindFine, entity.DeductionKindAdvance, entity.DeductionKindLateness:
+		return true
+	}
+	return false
+}
+
+// @Router /deductions [post]
+// @Summary Issue a deduction
+// @Description Issues a fine, salary advance or lateness penalty to a user. It is taken off the net pay of payroll runs and payslips covering the day it is issued. A linked attendance record must belong to the same user
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param deduction body entity.CreateDeductionRequest true "Deduction details"
+// @Success 201 {object} Response{data=entity.Deduction} "Deduction issued successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input, missing data, unknown currency or attendance of another user"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) createDeduction(c *gin.Context) {
+	adminID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.CreateDeductionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.UserID == 0 || req.Amount <= 0 || req.Currency == "" {
+		r.handleResponse(c, BadRequest, "Missing or invalid required fields: user_id, amount, currency", nil)
+		return
+	}
+	if req.Kind != "" && !validDeductionKind(req.Kind) {
+		r.handleResponse(c, BadRequest, "Invalid kind, expected fine, advance or lateness", nil)
+		return
+	}
+	if !r.knownCurrency(c, req.Currency) {
+		return
+	}
+
+	req.AdminID = adminID
+
+	createdDeduction, err := r.deductionsUC.Create(c, &req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no attendance found with ID"):
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+		case errors.Is(err, entity.ErrorDeductionAttendance):
+			r.handleResponse(c, BadRequest, "The attendance record belongs to another user", nil)
+		default:
+			r.log.Error("Error while creating deduction", map[string]any{"error": err.Error()})
+			r.handleResponse(c, InternalServerError, "Error while creating deduction", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, Created, "Deduction issued successfully", createdDeduction)
+}
+
+// @Router /deductions/{id} [get]
+// @Summary Get a deduction by ID
+// @Description Retrieves a single deduction record by its unique ID
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Deduction ID"
+// @Success 200 {object} Response{data=entity.Deduction} "Deduction details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Deduction not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) getDeductionByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid deduction ID format", nil)
+		return
+	}
+
+	deduction, err := r.deductionsUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		r.log.Error("Error while getting deduction by ID", map[string]any{"error": err.Error(), "deduction_id": id})
+
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deduction with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error retrieving deduction", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, deduction)
+}
+
+// @Router /deductions [get]
+// @Summary Get all deductions
+// @Description Retrieves a list of deductions with optional filtering, pagination, and search
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by User ID"
+// @Param admin_id query int false "Filter by issuing Admin ID"
+// @Param attendance_id query int false "Filter by Attendance ID"
+// @Param kind query string false "Filter by Kind (fine, advance, lateness)"
+// @Param search query string false "Search by reason"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllDeductionsResponse} "Successfully retrieved deductions"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) getAllDeductions(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	search := c.Query("search")
+	filter := make(map[string]string)
+
+	for _, key := range []string{"user_id", "admin_id", "attendance_id"} {
+		if value := c.Query(key); value != "" {
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				r.handleResponse(c, BadRequest, fmt.Sprintf("Invalid %s format", key), nil)
+				return
+			}
+			filter[key] = value
+		}
+	}
+	if kind := c.Query("kind"); kind != "" {
+		if !validDeductionKind(entity.DeductionKind(kind)) {
+			r.handleResponse(c, BadRequest, "Invalid kind, expected fine, advance or lateness", nil)
+			return
+		}
+		filter["kind"] = kind
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	deductions, err := r.deductionsUC.List(c, uint64(limit), uint64(offset), filter, search)
+	if err != nil {
+		r.log.Error("Error while getting all deductions", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving deductions", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, deductions)
+}
+
+// @Router /deductions/{id} [put]
+// @Summary Update a deduction record
+// @Description Updates a deduction by its ID. A changed user or attendance record is checked again
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Deduction ID to update"
+// @Param deduction body entity.UpdateDeductionRequest true "Updated deduction details"
+// @Success 200 {object} Response{data=string} "Deduction updated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID, request body, unknown currency or attendance of another user"
+// @Failure 404 {object} Response{data=string} "Not Found - Deduction not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) updateDeduction(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid deduction ID format", nil)
+		return
+	}
+
+	var req entity.UpdateDeductionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.Amount != nil && *req.Amount <= 0 {
+		r.handleResponse(c, BadRequest, "Amount must be positive", nil)
+		return
+	}
+	if req.Kind != nil && !validDeductionKind(*req.Kind) {
+		r.handleResponse(c, BadRequest, "Invalid kind, expected fine, advance or lateness", nil)
+		return
+	}
+	if req.Currency != nil && !r.knownCurrency(c, *req.Currency) {
+		return
+	}
+
+	req.ID = id // Set the ID from the URL path
+
+	err = r.deductionsUC.Update(c, &req)
+	if err != nil {
+		r.log.Error("Error while updating deduction", map[string]any{"error": err.Error(), "deduction_id": id})
+
+		switch {
+		case strings.Contains(err.Error(), "no deduction found with ID"):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deduction with ID %d not found", id), nil)
+		case strings.Contains(err.Error(), "no fields to update"):
+			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
+		case strings.Contains(err.Error(), "no attendance found with ID"):
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+		case errors.Is(err, entity.ErrorDeductionAttendance):
+			r.handleResponse(c, BadRequest, "The attendance record belongs to another user", nil)
+		default:
+			r.handleResponse(c, InternalServerError, "Error while updating deduction", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, "Deduction updated successfully", nil)
+}
+
+// @Router /deductions/{id} [delete]
+// @Summary Delete a deduction
+// @Description Deletes a deduction by its unique ID
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Deduction ID to delete"
+// @Success 200 {object} Response{data=string} "Deduction deleted successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Deduction not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) deleteDeduction(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid deduction ID format", nil)
+		return
+	}
+
+	err = r.deductionsUC.Delete(c, id)
+	if err != nil {
+		r.log.Error("Error while deleting deduction", map[string]any{"error": err.Error(), "deduction_id": id})
+
+		if strings.Contains(err.Error(), "no deduction found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deduction with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while deleting deduction", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Deduction deleted successfully", nil)
+}
+
+// @Router /deductions/report [get]
+// @Summary Get a deduction report
+// @Description Totals the deductions issued in the range in one reporting currency. Each deduction is converted at the latest exchange rate known on the day it was issued (Admins only)
+// @Tags DEDUCTIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Reporting currency" default(USD)
+// @Param from query string false "First day (YYYY-MM-DD), defaults to the start of the current month"
+// @Param to query string false "Last day (YYYY-MM-DD), defaults to today"
+// @Param user_id query int false "Filter by User ID"
+// @Param kind query string false "Filter by Kind (fine, advance, lateness)"
+// @Success 200 {object} Response{data=entity.PayoutReport} "Deduction report"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters or missing exchange rate"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *deductionsRoutes) getDeductionReport(c *gin.Context) {
+	filter := make(map[string]string)
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+		filter["user_id"] = userIDStr
+	}
+	if kind := c.Query("kind"); kind != "" {
+		if !validDeductionKind(entity.DeductionKind(kind)) {
+			r.handleResponse(c, BadRequest, "Invalid kind, expected fine, advance or lateness", nil)
+			return
+		}
+		filter["kind"] = kind
+	}
+
+	from, to, msg := parseDateRange(c)
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	currency := entity.Currency(strings.ToUpper(c.DefaultQuery("currency", string(entity.CurrencyUSD))))
+	if !r.knownCurrency(c, currency) {
+		return
+	}
+
+	report, err := r.deductionsUC.Report(c, filter, from, to, currency)
+	if err != nil {
+		if errors.Is(err, entity.ErrorNoExchangeRate) {
+			r.handleResponse(c, BadRequest, "Missing exchange rate: "+err.Error(), nil)
+			return
+		}
+		r.log.Error("Error while building deduction report", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error building deduction report", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, report)
+}
@@ -1,7 +1,15 @@
 package v1
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
 	"strconv"
 	"strings"
 
@@ -10,6 +18,7 @@ import (
 	"github.com/ruziba3vich/argus/api/middleware"
 	"github.com/ruziba3vich/argus/internal/entity"
 	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 	"github.com/ruziba3vich/argus/internal/pkg/helper"
 	"github.com/ruziba3vich/argus/internal/service"
@@ -32,15 +41,26 @@ type HandlerOption struct {
 type fileRoutes struct {
 	handlers.BaseHandler
 	fileUC   service.FileRepoInterface
+	taskUC   service.TaskRepoInterface
+	minIO    *minio.MinIOClient
 	log      *logger.Logger
 	cfg      *config.Config
 	enforcer *casbin.CachedEnforcer
 }
 
+// multipartOverhead is the room left on top of minio.MaxFileSize for the
+// boundaries, headers and other fields of an upload form.
+const multipartOverhead = 1 << 20
+
+// maxFormField is the most that is read of a plain field of an upload form.
+const maxFormField = 1 << 10
+
 // NewFileRoutes sets up the routes for file management.
 func NewFileRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
 	r := &fileRoutes{
 		fileUC:   option.File, // Assuming option.File exists
+		taskUC:   option.Task,
+		minIO:    option.MinIO,
 		log:      option.Logger,
 		cfg:      option.Config,
 		enforcer: option.Enforcer,
@@ -48,12 +68,15 @@ func NewFileRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	// Define authorization policies for file endpoints
 	policies := [][]string{
-		{"admin", "/v1/files/", "GET"},
-		{"admin", "/v1/files/", "POST"},
-		{"admin", "/v1/files/", "PUT"},
-		{"admin", "/v1/files/", "DELETE"},
-		// You might add user-specific policies if they can access their own files
-		// For example: {"user", "/v1/files/", "GET"}
+		{"admin", "/v1/files", "GET"},
+		{"admin", "/v1/files/:id", "GET"},
+		{"admin", "/v1/files", "POST"},
+		{"admin", "/v1/files/:id", "PUT"},
+		{"admin", "/v1/files/:id", "DELETE"},
+		{"user", "/v1/files/:id/download", "GET"},
+		// Users reach the files of the tasks assigned to them through the task
+		{"user", "/v1/tasks/:id/files", "GET"},
+		{"user", "/v1/tasks/:id/files", "POST"},
 	}
 
 	for _, policy := range policies {
@@ -65,13 +88,22 @@ func NewFileRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	fileGroup := apiV1Group.Group("/files")
 	{
-		// fileGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+		fileGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		fileGroup.POST("", r.createFile)
 		fileGroup.GET("/:id", r.getFileByID)
 		fileGroup.GET("", r.getAllFiles)
 		fileGroup.PUT("/:id", r.updateFile)
 		fileGroup.DELETE("/:id", r.deleteFile)
+		fileGroup.GET("/:id/download", r.downloadFile)
+	}
+
+	taskFileGroup := apiV1Group.Group("/tasks/:id/files")
+	{
+		taskFileGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		taskFileGroup.GET("", r.getTaskFiles)
+		taskFileGroup.POST("", r.uploadTaskFile)
 	}
 }
 
@@ -108,36 +140,296 @@ func (r *fileRoutes) handleResponse(c *gin.Context, status Status, customMessage
 }
 
 // @Router /files [post]
-// @Summary Create a new file record
-// @Description Creates a new file record, optionally linked to a task
+// @Summary Upload a file
+// @Description Uploads a file to storage, optionally attaching it to a task. The content type is detected from the content, not taken from the client (Admins only)
 // @Tags FILES
-// @Accept json
+// @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
-// @Param file body entity.CreateFileRequest true "File details"
-// @Success 201 {object} Response{data=entity.File} "File created successfully"
-// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Param file formData file true "File to upload, at most 10 MiB"
+// @Param task_id formData int false "Task to attach the file to, sent before the file"
+// @Success 201 {object} Response{data=entity.File} "File uploaded successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Missing file or invalid task_id"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 413 {object} Response{data=string} "Payload Too Large - File exceeds the size limit"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *fileRoutes) createFile(c *gin.Context) {
-	var req entity.CreateFileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, minio.MaxFileSize+multipartOverhead)
+
+	part, fields, ok := r.filePart(c)
+	if !ok {
+		return
+	}
+
+	var taskID *int64
+	if taskIDStr := fields["task_id"]; taskIDStr != "" {
+		id, err := strconv.ParseInt(taskIDStr, 10, 64)
+		if err != nil || id <= 0 {
+			r.handleResponse(c, BadRequest, "Invalid task_id format", nil)
+			return
+		}
+
+		if _, err := r.taskUC.Get(c, map[string]string{"id": taskIDStr}); err != nil {
+			if strings.Contains(err.Error(), "no rows in result set") {
+				r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+				return
+			}
+			r.log.Error("Error while getting task by ID", map[string]any{"error": err.Error(), "task_id": id})
+			r.handleResponse(c, InternalServerError, "Error retrieving task", err.Error())
+			return
+		}
+		taskID = &id
+	}
+
+	r.upload(c, part, taskID)
+}
+
+// @Router /tasks/{id}/files [post]
+// @Summary Upload a file to a task
+// @Description Uploads a file and attaches it to a task. The content type is detected from the content, not taken from the client. Plain users may only upload to tasks assigned to them
+// @Tags FILES
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param file formData file true "File to upload, at most 10 MiB"
+// @Success 201 {object} Response{data=entity.File} "File uploaded successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Missing file or invalid ID"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 413 {object} Response{data=string} "Payload Too Large - File exceeds the size limit"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *fileRoutes) uploadTaskFile(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, minio.MaxFileSize+multipartOverhead)
+
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	part, _, ok := r.filePart(c)
+	if !ok {
+		return
+	}
+
+	r.upload(c, part, &task.ID)
+}
+
+// @Router /tasks/{id}/files [get]
+// @Summary Get the files of a task
+// @Description Lists the files attached to a task, newest first. Plain users may only list the files of tasks assigned to them
+// @Tags FILES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param search query string false "Search by file name"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllFilesResponse} "Successfully retrieved files"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *fileRoutes) getTaskFiles(c *gin.Context) {
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	search := c.Query("search")
+	filter := map[string]string{"taskid": strconv.FormatInt(task.ID, 10)}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	files, err := r.fileUC.List(c, uint64(limit), uint64(offset), filter, search)
+	if err != nil {
+		r.log.Error("Error while getting task files", map[string]any{"error": err.Error(), "task_id": task.ID})
+		r.handleResponse(c, InternalServerError, "Error retrieving files", err.Error())
 		return
 	}
 
-	if req.Name == "" {
-		r.handleResponse(c, BadRequest, "Missing required field: name", nil)
+	r.handleResponse(c, OK, nil, files)
+}
+
+// filePart reads the multipart form of the request up to its file part and
+// returns it along with the plain fields sent before it, writing an error
+// response when there is none. The request body must already be limited with
+// http.MaxBytesReader.
+func (r *fileRoutes) filePart(c *gin.Context) (*multipart.Part, map[string]string, bool) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		r.handleResponse(c, BadRequest, "Expected a multipart/form-data body", err.Error())
+		return nil, nil, false
+	}
+
+	fields := make(map[string]string)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			switch {
+			case errors.As(err, &maxBytesErr):
+				r.handleResponse(c, PayloadTooLarge, minio.ErrFileTooLarge.Error(), nil)
+			case errors.Is(err, io.EOF):
+				r.handleResponse(c, BadRequest, "Missing required field: file", nil)
+			default:
+				r.handleResponse(c, BadRequest, "Invalid multipart body", err.Error())
+			}
+			return nil, nil, false
+		}
+
+		if part.FormName() == "file" {
+			return part, fields, true
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxFormField))
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid multipart body", err.Error())
+			return nil, nil, false
+		}
+		fields[part.FormName()] = strings.TrimSpace(string(value))
+	}
+}
+
+// upload streams part into MinIO and records it, attached to taskID when it
+// is set. Nothing of the file is buffered beyond its first 512 bytes, which
+// the content type is detected from.
+func (r *fileRoutes) upload(c *gin.Context, part *multipart.Part, taskID *int64) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	name := path.Base(strings.ReplaceAll(part.FileName(), "\\", "/"))
+	if name == "." || name == "/" || len(name) > 255 {
+		r.handleResponse(c, BadRequest, "Invalid file name", nil)
+		return
+	}
+
+	// Trust the content over the type the client claims
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			r.handleResponse(c, PayloadTooLarge, minio.ErrFileTooLarge.Error(), nil)
+			return
+		}
+		r.handleResponse(c, BadRequest, "Error reading uploaded file", err.Error())
+		return
+	}
+	if n == 0 {
+		r.handleResponse(c, BadRequest, "The uploaded file is empty", nil)
+		return
+	}
+	mimeType := http.DetectContentType(head[:n])
+
+	key, err := fileObjectKey(taskID, name)
+	if err != nil {
+		r.handleResponse(c, InternalServerError, "Error while uploading file", err.Error())
+		return
+	}
+
+	body := &cappedReader{r: io.MultiReader(bytes.NewReader(head[:n]), part), max: minio.MaxFileSize}
+	if err := r.minIO.UploadFile(c, body, key, -1, mimeType); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if body.n > body.max || errors.As(err, &maxBytesErr) {
+			r.handleResponse(c, PayloadTooLarge, minio.ErrFileTooLarge.Error(), nil)
+			return
+		}
+		r.log.Error("Error while uploading file to storage", map[string]any{"error": err.Error(), "object_key": key})
+		r.handleResponse(c, BadGateway, minio.ErrFileUploadFailed.Error(), nil)
 		return
 	}
 
-	createdFile, err := r.fileUC.Create(c, &req)
+	createdFile, err := r.fileUC.Create(c, &entity.CreateFileRequest{
+		Name:       name,
+		TaskID:     taskID,
+		ObjectKey:  key,
+		Size:       body.n,
+		MimeType:   mimeType,
+		UploadedBy: userID,
+	})
 	if err != nil {
-		r.log.Error("Error while creating file", map[string]any{"error": err.Error()})
+		r.log.Error("Error while creating file", map[string]any{"error": err.Error(), "object_key": key})
+		if err := r.minIO.DeleteFile(c, key); err != nil {
+			r.log.Error("Error while removing orphaned upload", map[string]any{"error": err.Error(), "object_key": key})
+		}
 		r.handleResponse(c, InternalServerError, "Error while creating file", err.Error())
 		return
 	}
 
-	r.handleResponse(c, Created, "File created successfully", createdFile)
+	r.handleResponse(c, Created, "File uploaded successfully", createdFile)
+}
+
+// cappedReader counts the bytes read through it and fails the read that goes
+// past max, so an upload of unknown size is cut off at the limit.
+type cappedReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	if c.n > c.max {
+		return n, minio.ErrFileTooLarge
+	}
+
+	return n, err
+}
+
+// task loads the task of the path and writes a 404 response when it does not
+// exist or the caller may not see it.
+func (r *fileRoutes) task(c *gin.Context) (*entity.Task, bool) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return nil, false
+	}
+
+	task, err := r.taskUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+			return nil, false
+		}
+		r.log.Error("Error while getting task by ID", map[string]any{"error": err.Error(), "task_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving task", err.Error())
+		return nil, false
+	}
+
+	if !canAccessTask(c, &r.BaseHandler, task) {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+		return nil, false
+	}
+
+	return task, true
+}
+
+// fileObjectKey returns a fresh MinIO key for an upload named name. The
+// random part keeps uploads of the same name apart, the name is kept at the
+// end so objects stay recognisable in the bucket.
+func fileObjectKey(taskID *int64, name string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	folder := "unattached"
+	if taskID != nil {
+		folder = strconv.FormatInt(*taskID, 10)
+	}
+
+	return fmt.Sprintf("files/%s/%s-%s", folder, hex.EncodeToString(random), name), nil
 }
 
 // @Router /files/{id} [get]
@@ -175,6 +467,93 @@ func (r *fileRoutes) getFileByID(c *gin.Context) {
 	r.handleResponse(c, OK, nil, file)
 }
 
+// @Router /files/{id}/download [get]
+// @Summary Download a file
+// @Description Redirects to a short-lived presigned URL of the file in storage, or streams it through the API when proxy is true. Plain users may only download the files of tasks assigned to them
+// @Tags FILES
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path int true "File ID"
+// @Param proxy query bool false "Stream the file instead of redirecting to storage"
+// @Success 200 {file} file "File content, when proxied"
+// @Success 302 {string} string "Redirect to the presigned URL"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - File not found or not stored"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+// @Failure 502 {object} Response{data=string} "Bad Gateway - Storage unavailable"
+func (r *fileRoutes) downloadFile(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid file ID format", nil)
+		return
+	}
+
+	file, err := r.fileUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("File with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting file by ID", map[string]any{"error": err.Error(), "file_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving file", err.Error())
+		return
+	}
+
+	// Plain users reach a file only through a task assigned to them, so
+	// files without a task are for admins alone
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		if file.TaskID == nil {
+			r.handleResponse(c, NotFound, fmt.Sprintf("File with ID %d not found", id), nil)
+			return
+		}
+
+		task, err := r.taskUC.Get(c, map[string]string{"id": strconv.FormatInt(*file.TaskID, 10)})
+		if err != nil && !strings.Contains(err.Error(), "no rows in result set") {
+			r.log.Error("Error while getting task by ID", map[string]any{"error": err.Error(), "task_id": *file.TaskID})
+			r.handleResponse(c, InternalServerError, "Error retrieving task", err.Error())
+			return
+		}
+		if err != nil || !canAccessTask(c, &r.BaseHandler, task) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("File with ID %d not found", id), nil)
+			return
+		}
+	}
+
+	if file.ObjectKey == nil {
+		r.handleResponse(c, NotFound, fmt.Sprintf("File with ID %d has no stored content", id), nil)
+		return
+	}
+
+	if proxy, _ := strconv.ParseBool(c.Query("proxy")); proxy {
+		object, info, err := r.minIO.GetFile(c, *file.ObjectKey)
+		if err != nil {
+			r.log.Error("Error while reading file from storage", map[string]any{"error": err.Error(), "object_key": *file.ObjectKey})
+			r.handleResponse(c, BadGateway, "Error reading file from storage", nil)
+			return
+		}
+		defer object.Close()
+
+		c.DataFromReader(OK.Code, info.Size, info.ContentType, object, map[string]string{
+			"Content-Disposition":    minio.Attachment(file.Name),
+			"X-Content-Type-Options": "nosniff",
+		})
+		return
+	}
+
+	presignedURL, err := r.minIO.PresignedURL(c, *file.ObjectKey, file.Name)
+	if err != nil {
+		r.log.Error("Error while presigning file URL", map[string]any{"error": err.Error(), "object_key": *file.ObjectKey})
+		r.handleResponse(c, InternalServerError, "Error while preparing download", err.Error())
+		return
+	}
+
+	// The URL grants access on its own, keep it out of shared caches
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, presignedURL.String())
+}
+
 // @Router /files [get]
 // @Summary Get all files
 // @Description Retrieves a list of files with optional filtering, pagination, and search
@@ -268,7 +647,7 @@ func (r *fileRoutes) updateFile(c *gin.Context) {
 
 // @Router /files/{id} [delete]
 // @Summary Delete a file
-// @Description Deletes a file by its unique ID
+// @Description Deletes a file by its unique ID, along with its content in storage
 // @Tags FILES
 // @Accept json
 // @Produce json
@@ -286,6 +665,17 @@ func (r *fileRoutes) deleteFile(c *gin.Context) {
 		return
 	}
 
+	file, err := r.fileUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("File with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting file by ID", map[string]any{"error": err.Error(), "file_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving file", err.Error())
+		return
+	}
+
 	err = r.fileUC.Delete(c, id)
 	if err != nil {
 		r.log.Error("Error while deleting file", map[string]any{"error": err.Error(), "file_id": id})
@@ -298,5 +688,12 @@ func (r *fileRoutes) deleteFile(c *gin.Context) {
 		return
 	}
 
+	// The row is gone either way, a leftover object only takes up space
+	if file.ObjectKey != nil {
+		if err := r.minIO.DeleteFile(c, *file.ObjectKey); err != nil {
+			r.log.Error("Error while deleting file from storage", map[string]any{"error": err.Error(), "object_key": *file.ObjectKey})
+		}
+	}
+
 	r.handleResponse(c, OK, "File deleted successfully", nil)
 }
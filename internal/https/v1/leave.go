@@ -0,0 +1,436 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type leaveRoutes struct {
+	handlers.BaseHandler
+	leaveUC  service.LeaveRepoInterface
+	log      *logger.Logger
+	cfg      *config.Config
+	enforcer *casbin.CachedEnforcer
+	accrual  entity.LeaveAccrual
+}
+
+// NewLeaveRoutes sets up the routes for leave requests and balances.
+func NewLeaveRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &leaveRoutes{
+		leaveUC:  option.Leave,
+		log:      option.Logger,
+		cfg:      option.Config,
+		enforcer: option.Enforcer,
+		// Unpaid leave is not limited by a balance
+		accrual: entity.LeaveAccrual{
+			entity.LeaveTypeVacation: option.Config.Leave.VacationDaysPerMonth,
+			entity.LeaveTypeSick:     option.Config.Leave.SickDaysPerMonth,
+		},
+	}
+
+	// Define authorization policies for leave endpoints
+	policies := [][]string{
+		{"user", "/v1/leaves", "POST"},
+		{"user", "/v1/leaves", "GET"},
+		{"user", "/v1/leaves/balance", "GET"},
+		{"user", "/v1/leaves/:id", "GET"},
+		{"user", "/v1/leaves/:id/cancel", "PUT"},
+		{"admin", "/v1/leaves/:id/approve", "PUT"},
+		{"admin", "/v1/leaves/:id/reject", "PUT"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during leaves enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	leavesGroup := apiV1Group.Group("/leaves")
+	{
+		leavesGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		leavesGroup.POST("", r.createLeave)
+		leavesGroup.GET("", r.getAllLeaves)
+		leavesGroup.GET("/balance", r.getBalance)
+		leavesGroup.GET("/:id", r.getLeaveByID)
+		leavesGroup.PUT("/:id/cancel", r.cancelLeave)
+		leavesGroup.PUT("/:id/approve", r.approveLeave)
+		leavesGroup.PUT("/:id/reject", r.rejectLeave)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *leaveRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// callerID returns the authenticated user's ID and writes a 401 response when it is missing.
+func (r *leaveRoutes) callerID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// @Router /leaves [post]
+// @Summary Request leave
+// @Description Files a pending leave request for the authenticated user. Only working days of the range are counted and vacation and sick leave must fit the accrued balance
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param leave body entity.CreateLeaveRequest true "Leave details"
+// @Success 201 {object} Response{data=entity.Leave} "Leave requested successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input, no working days or not enough balance"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 409 {object} Response{data=string} "Conflict - Overlaps another leave request"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) createLeave(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.CreateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.StartDate.IsZero() || req.EndDate.IsZero() {
+		r.handleResponse(c, BadRequest, "Missing required fields: start_date, end_date", nil)
+		return
+	}
+	switch req.Type {
+	case entity.LeaveTypeVacation, entity.LeaveTypeSick, entity.LeaveTypeUnpaid:
+	default:
+		r.handleResponse(c, BadRequest, "Invalid type, expected vacation, sick or unpaid", nil)
+		return
+	}
+	if req.EndDate.Before(req.StartDate) {
+		r.handleResponse(c, BadRequest, "Invalid date range, end_date is before start_date", nil)
+		return
+	}
+
+	req.UserID = userID
+
+	leave, err := r.leaveUC.Create(c, &req, r.accrual)
+	if err != nil {
+		switch {
+		case errors.Is(err, entity.ErrorLeaveOverlap):
+			r.handleResponse(c, Conflict, "The leave overlaps another pending or approved leave", nil)
+		case errors.Is(err, entity.ErrorLeaveBalance):
+			r.handleResponse(c, BadRequest, "Not enough leave balance", nil)
+		case errors.Is(err, entity.ErrorLeaveNoWorkDays):
+			r.handleResponse(c, BadRequest, "The leave covers no working days", nil)
+		default:
+			r.log.Error("Error while creating leave", map[string]any{"error": err.Error(), "user_id": userID})
+			r.handleResponse(c, InternalServerError, "Error while requesting leave", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, Created, "Leave requested successfully", leave)
+}
+
+// @Router /leaves [get]
+// @Summary Get leave requests
+// @Description Retrieves leave requests with optional filtering and pagination. Plain users only get their own
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by User ID (admins only)"
+// @Param status query string false "Filter by Status (pending, approved, rejected, cancelled)"
+// @Param type query string false "Filter by Type (vacation, sick, unpaid)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllLeavesResponse} "Successfully retrieved leave requests"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) getAllLeaves(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		userID, ok := r.callerID(c)
+		if !ok {
+			return
+		}
+		filter["user_id"] = strconv.FormatInt(userID, 10)
+	} else if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err == nil {
+			filter["user_id"] = userIDStr
+		} else {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if leaveType := c.Query("type"); leaveType != "" {
+		filter["type"] = leaveType
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	leaves, err := r.leaveUC.List(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting leave requests", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving leave requests", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, leaves)
+}
+
+// @Router /leaves/balance [get]
+// @Summary Get leave balance
+// @Description Returns accrued, used, pending and available days per leave type. Plain users always get their own balance
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "User ID (admins only), defaults to the caller"
+// @Success 200 {object} Response{data=entity.LeaveBalancesResponse} "Leave balance"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - User not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) getBalance(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" && r.GetUserRole(c) != entity.UserRoleUser {
+		id, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil || id <= 0 {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+		userID = id
+	}
+
+	balances, err := r.leaveUC.Balances(c, userID, r.accrual)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("User with ID %d not found", userID), nil)
+			return
+		}
+		r.log.Error("Error while getting leave balance", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving leave balance", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, balances)
+}
+
+// @Router /leaves/{id} [get]
+// @Summary Get a leave request by ID
+// @Description Retrieves a single leave request. Plain users can only see their own
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave ID"
+// @Success 200 {object} Response{data=entity.Leave} "Leave request details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Leave request not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) getLeaveByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid leave ID format", nil)
+		return
+	}
+
+	params := map[string]string{"id": idStr}
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		params["user_id"] = r.GetUserID(c)
+	}
+
+	leave, err := r.leaveUC.Get(c, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Leave request with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting leave by ID", map[string]any{"error": err.Error(), "leave_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving leave request", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, leave)
+}
+
+// @Router /leaves/{id}/cancel [put]
+// @Summary Cancel my leave request
+// @Description Withdraws a pending leave request of the authenticated user
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave ID"
+// @Success 200 {object} Response{data=entity.Leave} "Leave request cancelled"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or the request is not pending"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Leave request not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) cancelLeave(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid leave ID format", nil)
+		return
+	}
+
+	leave, err := r.leaveUC.Cancel(c, id, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Leave request with ID %d not found", id), nil)
+		case errors.Is(err, entity.ErrorLeaveNotPending):
+			r.handleResponse(c, BadRequest, "Only pending leave requests can be cancelled", nil)
+		default:
+			r.log.Error("Error while cancelling leave", map[string]any{"error": err.Error(), "leave_id": id})
+			r.handleResponse(c, InternalServerError, "Error while cancelling leave request", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, "Leave request cancelled", leave)
+}
+
+// @Router /leaves/{id}/approve [put]
+// @Summary Approve a leave request
+// @Description Approves a pending leave request (Admins only). Absences already recorded for the covered days are removed
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave ID"
+// @Param review body entity.ReviewLeaveRequest false "Optional review comment"
+// @Success 200 {object} Response{data=entity.Leave} "Leave request approved"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID, not pending or not enough balance"
+// @Failure 404 {object} Response{data=string} "Not Found - Leave request not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) approveLeave(c *gin.Context) {
+	r.reviewLeave(c, entity.LeaveStatusApproved)
+}
+
+// @Router /leaves/{id}/reject [put]
+// @Summary Reject a leave request
+// @Description Rejects a pending leave request (Admins only)
+// @Tags LEAVES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Leave ID"
+// @Param review body entity.ReviewLeaveRequest false "Optional review comment"
+// @Success 200 {object} Response{data=entity.Leave} "Leave request rejected"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or not pending"
+// @Failure 404 {object} Response{data=string} "Not Found - Leave request not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *leaveRoutes) rejectLeave(c *gin.Context) {
+	r.reviewLeave(c, entity.LeaveStatusRejected)
+}
+
+func (r *leaveRoutes) reviewLeave(c *gin.Context, status entity.LeaveStatus) {
+	reviewerID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid leave ID format", nil)
+		return
+	}
+
+	var req entity.ReviewLeaveRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	req.ID = id
+	req.ReviewerID = reviewerID
+	req.Status = status
+
+	leave, err := r.leaveUC.Review(c, &req, r.accrual)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Leave request with ID %d not found", id), nil)
+		case errors.Is(err, entity.ErrorLeaveNotPending):
+			r.handleResponse(c, BadRequest, "Only pending leave requests can be reviewed", nil)
+		case errors.Is(err, entity.ErrorLeaveBalance):
+			r.handleResponse(c, BadRequest, "Not enough leave balance", nil)
+		default:
+			r.log.Error("Error while reviewing leave", map[string]any{"error": err.Error(), "leave_id": id})
+			r.handleResponse(c, InternalServerError, "Error while reviewing leave request", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, fmt.Sprintf("Leave request %s", status), leave)
+}
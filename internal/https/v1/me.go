@@ -0,0 +1,622 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+// meRoutes serves the caller's own records. The owner is always taken from the
+// sub claim of the access token and can never be overridden by query parameters.
+type meRoutes struct {
+	handlers.BaseHandler
+	userUC         service.UserRepoInterface
+	attendanceUC   service.AttendanceRepoInterface
+	salaryUC       service.SalaryRepoInterface
+	bonusesUC      service.BonusesRepoInterface
+	deductionsUC   service.DeductionsRepoInterface
+	taskUC         service.TaskRepoInterface
+	notificationUC service.NotificationRepoInterface
+	shiftUC        service.ShiftRepoInterface
+	compensationUC service.CompensationRepoInterface
+	payoutUC       service.PayoutRepoInterface
+	minIO          *minio.MinIOClient
+	log            *logger.Logger
+	cfg            *config.Config
+	enforcer       *casbin.CachedEnforcer
+}
+
+// NewMeRoutes sets up the self-service routes for the authenticated user.
+func NewMeRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &meRoutes{
+		userUC:         option.User,
+		attendanceUC:   option.Attendance,
+		salaryUC:       option.Salary,
+		bonusesUC:      option.Bonuses,
+		deductionsUC:   option.Deductions,
+		taskUC:         option.Task,
+		notificationUC: option.Notification,
+		shiftUC:        option.Shift,
+		compensationUC: option.Compensation,
+		payoutUC:       option.Payout,
+		minIO:          option.MinIO,
+		log:            option.Logger,
+		cfg:            option.Config,
+		enforcer:       option.Enforcer,
+	}
+
+	// Admins and super admins inherit these through the role hierarchy
+	policies := [][]string{
+		{"user", "/v1/me", "GET"},
+		{"user", "/v1/me/attendance", "GET"},
+		{"user", "/v1/me/salaries", "GET"},
+		{"user", "/v1/me/salaries/:id/payslip", "GET"},
+		{"user", "/v1/me/bonuses", "GET"},
+		{"user", "/v1/me/deductions", "GET"},
+		{"user", "/v1/me/tasks", "GET"},
+		{"user", "/v1/me/notifications", "GET"},
+		{"user", "/v1/me/shifts", "GET"},
+		{"user", "/v1/me/compensation", "GET"},
+		{"user", "/v1/me/payout-card", "GET"},
+		{"user", "/v1/me/payout-card", "PUT"},
+		{"user", "/v1/me/payout-card", "DELETE"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during me enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	meGroup := apiV1Group.Group("/me")
+	{
+		meGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		meGroup.GET("", r.getProfile)
+		meGroup.GET("/attendance", r.getAttendance)
+		meGroup.GET("/salaries", r.getSalaries)
+		meGroup.GET("/salaries/:id/payslip", r.getPayslip)
+		meGroup.GET("/bonuses", r.getBonuses)
+		meGroup.GET("/deductions", r.getDeductions)
+		meGroup.GET("/tasks", r.getTasks)
+		meGroup.GET("/notifications", r.getNotifications)
+		meGroup.GET("/shifts", r.getShifts)
+		meGroup.GET("/compensation", r.getCompensation)
+		meGroup.GET("/payout-card", r.getPayoutCard)
+		meGroup.PUT("/payout-card", r.setPayoutCard)
+		meGroup.DELETE("/payout-card", r.deletePayoutCard)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *meRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// callerID resolves the authenticated user from the sub claim and writes a 401
+// response when it is missing.
+func (r *meRoutes) callerID(c *gin.Context) (string, bool) {
+	sub := r.GetUserID(c)
+	if id, err := strconv.ParseInt(sub, 10, 64); err != nil || id <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return "", false
+	}
+
+	return sub, true
+}
+
+// @Router /me [get]
+// @Summary Get my profile
+// @Description Retrieves the profile of the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=v1.UserResponse} "User details"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - User not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getProfile(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	user, err := r.userUC.Get(c, map[string]string{"id": userID})
+	if err != nil {
+		r.log.Error("Error while getting own profile", map[string]any{"error": err.Error(), "user_id": userID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, "User not found", nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error retrieving profile", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, UserResponse{
+		ID:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      user.Role,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		PhotoURL:  user.PhotoURL,
+		Bio:       user.Bio,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	})
+}
+
+// @Router /me/attendance [get]
+// @Summary Get my attendance history
+// @Description Retrieves the attendance records of the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Filter by Date (YYYY-MM-DD)"
+// @Param status query string false "Filter by Status (present, absent, late)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllAttendancesResponse} "Successfully retrieved attendance records"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getAttendance(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	filter := map[string]string{"user_id": userID}
+
+	if dateStr := c.Query("date"); dateStr != "" {
+		if _, err := time.Parse("2006-01-02", dateStr); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid date format. Use YYYY-MM-DD", nil)
+			return
+		}
+		filter["date"] = dateStr
+	}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	attendances, err := r.attendanceUC.List(c, uint64(limit), uint64((page-1)*limit), filter, "")
+	if err != nil {
+		r.log.Error("Error while getting own attendance records", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving attendance records", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, attendances)
+}
+
+// @Router /me/salaries [get]
+// @Summary Get my salaries
+// @Description Retrieves the salary records of the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by Status (paid, pending, overdue)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllSalariesResponse} "Successfully retrieved salaries"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getSalaries(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	filter := map[string]string{"user_id": userID}
+
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	salaries, err := r.salaryUC.List(c, uint64(limit), uint64((page-1)*limit), filter, "")
+	if err != nil {
+		r.log.Error("Error while getting own salaries", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving salaries", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, salaries)
+}
+
+// @Router /me/salaries/{id}/payslip [get]
+// @Summary Download my payslip
+// @Description Downloads the payslip PDF of one of the authenticated user's paid salaries
+// @Tags ME
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {file} file "Payslip PDF"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary or payslip not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getPayslip(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	// Someone else's salary is reported as missing rather than forbidden
+	salary, err := r.salaryUC.Get(c, map[string]string{"id": idStr, "user_id": userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting own salary", map[string]any{"error": err.Error(), "user_id": userID, "salary_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving salary", err.Error())
+		return
+	}
+	if salary.PayslipKey == nil {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d has no payslip yet", id), nil)
+		return
+	}
+
+	if err := servePayslip(c, r.minIO, salary); err != nil {
+		r.log.Error("Error while downloading own payslip", map[string]any{"error": err.Error(), "user_id": userID, "salary_id": id})
+		r.handleResponse(c, InternalServerError, "Error while downloading payslip", err.Error())
+	}
+}
+
+// @Router /me/bonuses [get]
+// @Summary Get my bonuses
+// @Description Retrieves the approved bonuses granted to the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllBonusesResponse} "Successfully retrieved bonuses"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getBonuses(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+
+	bonuses, err := r.bonusesUC.List(c, uint64(limit), uint64((page-1)*limit), map[string]string{"user_id": userID, "status": string(entity.BonusStatusApproved)}, "")
+	if err != nil {
+		r.log.Error("Error while getting own bonuses", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving bonuses", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, bonuses)
+}
+
+// @Router /me/deductions [get]
+// @Summary Get my deductions
+// @Description Retrieves the fines, advances and lateness penalties issued to the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllDeductionsResponse} "Successfully retrieved deductions"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getDeductions(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+
+	deductions, err := r.deductionsUC.List(c, uint64(limit), uint64((page-1)*limit), map[string]string{"user_id": userID}, "")
+	if err != nil {
+		r.log.Error("Error while getting own deductions", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving deductions", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, deductions)
+}
+
+// @Router /me/tasks [get]
+// @Summary Get my tasks
+// @Description Retrieves the tasks assigned to the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by Status (e.g., 'pending', 'in_progress')"
+// @Param priority query string false "Filter by Priority (e.g., 'high', 'low')"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllTasksResponse} "Successfully retrieved tasks"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getTasks(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	filter := map[string]string{"assignedto": userID}
+
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+	if priority := c.Query("priority"); priority != "" {
+		filter["priority"] = priority
+	}
+
+	tasks, err := r.taskUC.List(c, uint64(limit), uint64((page-1)*limit), filter, "")
+	if err != nil {
+		r.log.Error("Error while getting own tasks", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving tasks", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, tasks)
+}
+
+// @Router /me/notifications [get]
+// @Summary Get my notifications
+// @Description Retrieves the notifications of the authenticated user
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param read query bool false "Filter by read state"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllNotificationsResponse} "Successfully retrieved notifications"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getNotifications(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	filter := map[string]string{"user_id": userID}
+
+	if readStr := c.Query("read"); readStr != "" {
+		if _, err := strconv.ParseBool(readStr); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid read format", nil)
+			return
+		}
+		filter["read"] = readStr
+	}
+
+	notifications, err := r.notificationUC.List(c, uint64(limit), uint64((page-1)*limit), filter, "")
+	if err != nil {
+		r.log.Error("Error while getting own notifications", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving notifications", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, notifications)
+}
+
+// @Router /me/shifts [get]
+// @Summary Get my work schedule
+// @Description Retrieves the weekly shifts of the authenticated user, ordered by weekday
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.GetAllShiftsResponse} "Successfully retrieved shifts"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getShifts(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	// A week has at most seven shifts, so a single page covers it
+	shifts, err := r.shiftUC.List(c, 7, 0, map[string]string{"user_id": userID})
+	if err != nil {
+		r.log.Error("Error while getting own shifts", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving shifts", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, shifts)
+}
+
+// @Router /me/compensation [get]
+// @Summary Get my compensation
+// @Description Retrieves the compensation profile of the authenticated user in effect today
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.Compensation} "Compensation in effect"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - No compensation in effect"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getCompensation(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.ParseInt(userID, 10, 64)
+	compensation, err := r.compensationUC.Current(c, id, time.Now())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, "You have no compensation in effect", nil)
+			return
+		}
+		r.log.Error("Error while getting own compensation", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving compensation", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, compensation)
+}
+
+// @Router /me/payout-card [get]
+// @Summary Get my payout card
+// @Description Retrieves the masked card the authenticated user's salaries and bonuses are paid out to
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.PayoutCard} "Payout card"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - No payout card"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) getPayoutCard(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.ParseInt(userID, 10, 64)
+	card, err := r.payoutUC.GetCard(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, "You have no payout card", nil)
+			return
+		}
+		r.log.Error("Error while getting own payout card", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving payout card", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, card)
+}
+
+// @Router /me/payout-card [put]
+// @Summary Set my payout card
+// @Description Sets the card the authenticated user's salaries and bonuses are paid out to, replacing the previous one
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param card body entity.SetPayoutCardRequest true "16-digit card number"
+// @Success 200 {object} Response{data=entity.PayoutCard} "Payout card set"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid card number"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) setPayoutCard(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	var req entity.SetPayoutCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	req.Card = strings.ReplaceAll(strings.TrimSpace(req.Card), " ", "")
+	if !helper.ValidateCardNumber(req.Card) {
+		r.handleResponse(c, BadRequest, "Card must be a valid 16-digit card number", nil)
+		return
+	}
+
+	id, _ := strconv.ParseInt(userID, 10, 64)
+	card, err := r.payoutUC.SetCard(c, id, req.Card)
+	if err != nil {
+		r.log.Error("Error while setting own payout card", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error setting payout card", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Payout card set successfully", card)
+}
+
+// @Router /me/payout-card [delete]
+// @Summary Delete my payout card
+// @Description Removes the card the authenticated user is paid out to
+// @Tags ME
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=string} "Payout card deleted"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - No payout card"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *meRoutes) deletePayoutCard(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	id, _ := strconv.ParseInt(userID, 10, 64)
+	if err := r.payoutUC.DeleteCard(c, id); err != nil {
+		if strings.Contains(err.Error(), "no payout card found") {
+			r.handleResponse(c, NotFound, "You have no payout card", nil)
+			return
+		}
+		r.log.Error("Error while deleting own payout card", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error deleting payout card", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Payout card deleted successfully", nil)
+}
@@ -0,0 +1,295 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type notificationRoutes struct {
+	handlers.BaseHandler
+	notificationUC service.NotificationRepoInterface
+	log            *logger.Logger
+	cfg            *config.Config
+	enforcer       *casbin.CachedEnforcer
+}
+
+// NewNotificationRoutes sets up the routes for notifications.
+func NewNotificationRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &notificationRoutes{
+		notificationUC: option.Notification,
+		log:            option.Logger,
+		cfg:            option.Config,
+		enforcer:       option.Enforcer,
+	}
+
+	// Define authorization policies for notification endpoints
+	policies := [][]string{
+		{"admin", "/v1/notifications", "POST"},
+		{"user", "/v1/notifications", "GET"},
+		{"user", "/v1/notifications/unread-count", "GET"},
+		{"user", "/v1/notifications/read-all", "PUT"},
+		{"user", "/v1/notifications/:id/read", "PUT"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during notification enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	notificationGroup := apiV1Group.Group("/notifications")
+	{
+		notificationGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		notificationGroup.POST("", r.broadcastNotification)
+		notificationGroup.GET("", r.getMyNotifications)
+		notificationGroup.GET("/unread-count", r.getUnreadCount)
+		notificationGroup.PUT("/read-all", r.markAllRead)
+		notificationGroup.PUT("/:id/read", r.markRead)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *notificationRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// callerID returns the authenticated user's ID and writes a 401 response when it is missing.
+func (r *notificationRoutes) callerID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// @Router /notifications [post]
+// @Summary Broadcast a notification
+// @Description Sends the same notification to the given users, to every user of a role, or to everyone when neither is set (Admins only)
+// @Tags NOTIFICATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param notification body entity.BroadcastNotificationRequest true "Notification details"
+// @Success 201 {object} Response{data=entity.BroadcastNotificationResponse} "Notification sent successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *notificationRoutes) broadcastNotification(c *gin.Context) {
+	var req entity.BroadcastNotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if strings.TrimSpace(req.Message) == "" {
+		r.handleResponse(c, BadRequest, "Missing required field: message", nil)
+		return
+	}
+	switch req.Type {
+	case "":
+		req.Type = entity.NotificationTypeApp
+	case entity.NotificationTypeApp, entity.NotificationTypeEmail, entity.NotificationTypeSMS:
+	default:
+		r.handleResponse(c, BadRequest, "Invalid type, expected app, email or sms", nil)
+		return
+	}
+
+	sent, err := r.notificationUC.Broadcast(c, &req)
+	if err != nil {
+		r.log.Error("Error while broadcasting notification", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while sending notification", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Notification sent successfully", entity.BroadcastNotificationResponse{Sent: sent})
+}
+
+// @Router /notifications [get]
+// @Summary Get my notifications
+// @Description Retrieves the notifications of the authenticated user
+// @Tags NOTIFICATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param read query bool false "Filter by read state"
+// @Param search query string false "Search by message"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllNotificationsResponse} "Successfully retrieved notifications"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *notificationRoutes) getMyNotifications(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	search := c.Query("search")
+	filter := map[string]string{"user_id": strconv.FormatInt(userID, 10)}
+
+	if readStr := c.Query("read"); readStr != "" {
+		if _, err := strconv.ParseBool(readStr); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid read format", nil)
+			return
+		}
+		filter["read"] = readStr
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := r.notificationUC.List(c, uint64(limit), uint64(offset), filter, search)
+	if err != nil {
+		r.log.Error("Error while getting notifications", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error retrieving notifications", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, notifications)
+}
+
+// @Router /notifications/unread-count [get]
+// @Summary Get my unread notification count
+// @Description Returns the number of unread notifications of the authenticated user
+// @Tags NOTIFICATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=entity.UnreadNotificationsResponse} "Unread notification count"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *notificationRoutes) getUnreadCount(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	count, err := r.notificationUC.CountUnread(c, userID)
+	if err != nil {
+		r.log.Error("Error while counting unread notifications", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error counting unread notifications", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, entity.UnreadNotificationsResponse{Unread: count})
+}
+
+// @Router /notifications/read-all [put]
+// @Summary Mark all my notifications as read
+// @Description Marks every unread notification of the authenticated user as read
+// @Tags NOTIFICATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=string} "Notifications marked as read"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *notificationRoutes) markAllRead(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	if _, err := r.notificationUC.MarkAllRead(c, userID); err != nil {
+		r.log.Error("Error while marking notifications as read", map[string]any{"error": err.Error(), "user_id": userID})
+		r.handleResponse(c, InternalServerError, "Error while marking notifications as read", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Notifications marked as read", nil)
+}
+
+// @Router /notifications/{id}/read [put]
+// @Summary Mark a notification as read
+// @Description Marks one of the authenticated user's notifications as read
+// @Tags NOTIFICATIONS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} Response{data=string} "Notification marked as read"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Notification not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *notificationRoutes) markRead(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid notification ID format", nil)
+		return
+	}
+
+	// Looking the notification up by owner as well keeps other users' rows invisible
+	_, err = r.notificationUC.Get(c, map[string]string{"id": idStr, "user_id": strconv.FormatInt(userID, 10)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Notification with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting notification by ID", map[string]any{"error": err.Error(), "notification_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving notification", err.Error())
+		return
+	}
+
+	read := true
+	if err := r.notificationUC.Update(c, &entity.UpdateNotificationRequest{ID: id, Read: &read}); err != nil {
+		r.log.Error("Error while marking notification as read", map[string]any{"error": err.Error(), "notification_id": id})
+		r.handleResponse(c, InternalServerError, "Error while marking notification as read", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Notification marked as read", nil)
+}
@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/infrastructure/multicard"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type payoutRoutes struct {
+	handlers.BaseHandler
+	payoutUC  service.PayoutRepoInterface
+	multicard *multicard.Client
+	log       *logger.Logger
+	cfg       *config.Config
+	enforcer  *casbin.CachedEnforcer
+}
+
+// NewPayoutRoutes sets up the routes that pay salaries and bonuses out to
+// employees' cards.
+func NewPayoutRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &payoutRoutes{
+		payoutUC:  option.Payout,
+		multicard: option.Multicard,
+		log:       option.Logger,
+		cfg:       option.Config,
+		enforcer:  option.Enforcer,
+	}
+
+	// Define authorization policies for payout endpoints
+	policies := [][]string{
+		{"admin", "/v1/payouts/salaries/:id", "POST"},
+		{"admin", "/v1/payouts/bonuses/:id", "POST"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during payout enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	payoutsGroup := apiV1Group.Group("/payouts")
+	{
+		payoutsGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		payoutsGroup.POST("/salaries/:id", r.paySalary)
+		payoutsGroup.POST("/bonuses/:id", r.payBonus)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *payoutRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// @Router /payouts/salaries/{id} [post]
+// @Summary Pay out a salary
+// @Description Sends a paid salary to the employee's payout card through Multicard. Repeating the request never pays twice: a payout that failed or was left unknown is retried under the same idempotency key
+// @Tags PAYOUT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {object} Response{data=entity.Payout} "Salary paid out"
+// @Failure 400 {object} Response{data=string} "Bad Request - Salary not paid, no payout card, no exchange rate or payout rejected"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Already paid out or payout in progress"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+// @Failure 502 {object} Response{data=string} "Bad Gateway - Multicard unavailable"
+func (r *payoutRoutes) paySalary(c *gin.Context) {
+	r.pay(c, entity.PayoutKindSalary, "Salary")
+}
+
+// @Router /payouts/bonuses/{id} [post]
+// @Summary Pay out a bonus
+// @Description Sends an approved bonus to the employee's payout card through Multicard. Repeating the request never pays twice: a payout that failed or was left unknown is retried under the same idempotency key
+// @Tags PAYOUT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bonus ID"
+// @Success 200 {object} Response{data=entity.Payout} "Bonus paid out"
+// @Failure 400 {object} Response{data=string} "Bad Request - Bonus not approved, no payout card, no exchange rate or payout rejected"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden"
+// @Failure 404 {object} Response{data=string} "Not Found - Bonus not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Already paid out, paid with a payroll run or payout in progress"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+// @Failure 502 {object} Response{data=string} "Bad Gateway - Multicard unavailable"
+func (r *payoutRoutes) payBonus(c *gin.Context) {
+	r.pay(c, entity.PayoutKindBonus, "Bonus")
+}
+
+// pay claims the salary or bonus, sends it to Multicard and records the
+// outcome.
+func (r *payoutRoutes) pay(c *gin.Context, kind entity.PayoutKind, label string) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, fmt.Sprintf("Invalid %s ID format", kind), nil)
+		return
+	}
+
+	order, err := r.payoutUC.Claim(c, kind, id)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "found with ID"):
+			r.handleResponse(c, NotFound, fmt.Sprintf("%s with ID %d not found", label, id), nil)
+		case errors.Is(err, entity.ErrorPayoutInProgress):
+			r.handleResponse(c, Conflict, "A payout of this record is already in progress", nil)
+		case errors.Is(err, entity.ErrorPayoutNotAllowed):
+			if strings.Contains(err.Error(), "already paid") {
+				r.handleResponse(c, Conflict, err.Error(), nil)
+				return
+			}
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+		case errors.Is(err, entity.ErrorPayoutNoCard):
+			r.handleResponse(c, BadRequest, "The employee has no payout card", nil)
+		case errors.Is(err, entity.ErrorNoExchangeRate):
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+		default:
+			r.log.Error("Error while claiming payout", map[string]any{"error": err.Error(), "kind": kind, "id": id})
+			r.handleResponse(c, InternalServerError, "Error while starting payout", err.Error())
+		}
+		return
+	}
+
+	result, err := r.multicard.Payout(c, &multicard.PayoutRequest{
+		Key:     order.Key,
+		Card:    order.Card,
+		Amount:  int64(math.Round(order.AmountUZS * 100)),
+		Details: fmt.Sprintf("%s #%d", label, id),
+	})
+	if err != nil {
+		r.log.Error("Error while paying out", map[string]any{"error": err.Error(), "kind": kind, "id": id, "key": order.Key})
+
+		// Whether an unanswered payout went through is unknown, so it stays
+		// processing until it goes stale and can be retried under its key
+		status, respStatus, message := entity.PayoutStatusProcessing, BadGateway, "Multicard is unavailable, the payout will be safe to retry later"
+		if errors.Is(err, multicard.ErrRejected) {
+			status, respStatus, message = entity.PayoutStatusFailed, BadRequest, "Multicard rejected the payout"
+		}
+		if _, finishErr := r.payoutUC.Finish(c, order, status, "", err.Error()); finishErr != nil {
+			r.log.Error("Error while recording failed payout", map[string]any{"error": finishErr.Error(), "kind": kind, "id": id})
+		}
+		r.handleResponse(c, respStatus, message, err.Error())
+		return
+	}
+
+	status := entity.PayoutStatusProcessing
+	switch result.Status {
+	case "success":
+		status = entity.PayoutStatusSucceeded
+	case "error", "revert":
+		status = entity.PayoutStatusFailed
+	}
+
+	payout, err := r.payoutUC.Finish(c, order, status, result.TransactionID, "")
+	if err != nil {
+		// The money has moved, so the transaction ID must not get lost
+		r.log.Error("Error while recording payout", map[string]any{
+			"error": err.Error(), "kind": kind, "id": id, "transaction_id": result.TransactionID,
+		})
+		r.handleResponse(c, InternalServerError, "Payout was sent but could not be recorded", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, payout)
+}
@@ -0,0 +1,287 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type payrollRoutes struct {
+	handlers.BaseHandler
+	payrollUC  service.PayrollRepoInterface
+	currencyUC service.CurrencyRepoInterface
+	log        *logger.Logger
+	cfg        *config.Config
+	enforcer   *casbin.CachedEnforcer
+}
+
+// NewPayrollRoutes sets up the routes for payroll runs.
+func NewPayrollRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &payrollRoutes{
+		payrollUC:  option.Payroll,
+		currencyUC: option.Currency,
+		log:        option.Logger,
+		cfg:        option.Config,
+		enforcer:   option.Enforcer,
+	}
+
+	// Define authorization policies for payroll endpoints
+	policies := [][]string{
+		{"admin", "/v1/payroll/preview", "POST"},
+		{"admin", "/v1/payroll/runs", "POST"},
+		{"admin", "/v1/payroll/runs", "GET"},
+		{"admin", "/v1/payroll/runs/:id", "GET"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during payroll enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	payrollGroup := apiV1Group.Group("/payroll")
+	{
+		payrollGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		payrollGroup.POST("/preview", r.previewPayroll)
+		payrollGroup.POST("/runs", r.runPayroll)
+		payrollGroup.GET("/runs", r.getAllPayrollRuns)
+		payrollGroup.GET("/runs/:id", r.getPayrollRunByID)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *payrollRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// bindPayrollRun reads and validates a payroll run request, writing an error
+// response when it is not usable.
+func (r *payrollRoutes) bindPayrollRun(c *gin.Context) (*entity.PayrollRunRequest, bool) {
+	adminID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || adminID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return nil, false
+	}
+
+	var req entity.PayrollRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return nil, false
+	}
+
+	if req.From.IsZero() || req.To.IsZero() || req.PayDate.IsZero() || req.Currency == "" {
+		r.handleResponse(c, BadRequest, "Missing required fields: from, to, pay_date, currency", nil)
+		return nil, false
+	}
+	if req.To.Before(req.From) {
+		r.handleResponse(c, BadRequest, "Invalid period, to is before from", nil)
+		return nil, false
+	}
+	if req.To.Sub(req.From).Hours()/24 >= maxReportDays {
+		r.handleResponse(c, BadRequest, fmt.Sprintf("Period is too long, at most %d days are allowed", maxReportDays), nil)
+		return nil, false
+	}
+	msg, err := checkCurrency(c, r.currencyUC, req.Currency)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": req.Currency})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return nil, false
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return nil, false
+	}
+	if req.BaseRate < 0 {
+		r.handleResponse(c, BadRequest, "Invalid base_rate, must not be negative", nil)
+		return nil, false
+	}
+	for userID, rate := range req.Rates {
+		if rate < 0 {
+			r.handleResponse(c, BadRequest, fmt.Sprintf("Invalid rate of user %d, must not be negative", userID), nil)
+			return nil, false
+		}
+	}
+
+	req.AdminID = adminID
+
+	return &req, true
+}
+
+// @Router /payroll/preview [post]
+// @Summary Preview a payroll run
+// @Description Computes every employee's pay for the period from their compensation profile (or the given rates), attended days and hours, approved leave and the approved bonuses up to the end of the period that no earlier run or payout has paid, without creating salaries. Compensation and bonuses in other currencies are converted at the latest exchange rates known at the end of the period (Admins only)
+// @Tags PAYROLL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param run body entity.PayrollRunRequest true "Payroll period and rates"
+// @Success 200 {object} Response{data=entity.PayrollRun} "Payroll preview"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *payrollRoutes) previewPayroll(c *gin.Context) {
+	req, ok := r.bindPayrollRun(c)
+	if !ok {
+		return
+	}
+
+	run, err := r.payrollUC.Preview(c, req)
+	if err != nil {
+		r.log.Error("Error while previewing payroll", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while computing payroll", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, run)
+}
+
+// @Router /payroll/runs [post]
+// @Summary Run payroll
+// @Description Computes the payroll like the preview and creates a pending salary for every employee with something to pay in one transaction. Employees already paid by a run overlapping the period are skipped. The bonuses a salary pays are marked with the run and can no longer be paid out on their own (Admins only)
+// @Tags PAYROLL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param run body entity.PayrollRunRequest true "Payroll period and rates"
+// @Success 201 {object} Response{data=entity.PayrollRun} "Payroll run created successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or nothing to pay"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 409 {object} Response{data=string} "Conflict - A bonus of the run is being paid out on its own"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *payrollRoutes) runPayroll(c *gin.Context) {
+	req, ok := r.bindPayrollRun(c)
+	if !ok {
+		return
+	}
+
+	run, err := r.payrollUC.Run(c, req)
+	if err != nil {
+		if errors.Is(err, entity.ErrorPayrollEmpty) {
+			r.handleResponse(c, BadRequest, "Nobody has anything to be paid for the period", nil)
+			return
+		}
+		if errors.Is(err, entity.ErrorPayoutInProgress) {
+			r.handleResponse(c, Conflict, "A bonus was paid out while the payroll was computed, run it again", err.Error())
+			return
+		}
+		r.log.Error("Error while running payroll", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while running payroll", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Payroll run created successfully", run)
+}
+
+// @Router /payroll/runs [get]
+// @Summary Get payroll runs
+// @Description Retrieves committed payroll runs, newest first. Their salaries are listed by /salaries?payroll_run_id= (Admins only)
+// @Tags PAYROLL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param admin_id query int false "Filter by Admin ID"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllPayrollRunsResponse} "Successfully retrieved payroll runs"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *payrollRoutes) getAllPayrollRuns(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if adminIDStr := c.Query("admin_id"); adminIDStr != "" {
+		if _, err := strconv.ParseInt(adminIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid admin_id format", nil)
+			return
+		}
+		filter["admin_id"] = adminIDStr
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	runs, err := r.payrollUC.List(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting payroll runs", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving payroll runs", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, runs)
+}
+
+// @Router /payroll/runs/{id} [get]
+// @Summary Get a payroll run by ID
+// @Description Retrieves a committed payroll run (Admins only)
+// @Tags PAYROLL
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Payroll Run ID"
+// @Success 200 {object} Response{data=entity.PayrollRun} "Successfully retrieved payroll run"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Payroll run not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *payrollRoutes) getPayrollRunByID(c *gin.Context) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid payroll run ID format", nil)
+		return
+	}
+
+	run, err := r.payrollUC.Get(c, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Payroll run with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting payroll run by ID", map[string]any{"error": err.Error(), "payroll_run_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving payroll run", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, run)
+}
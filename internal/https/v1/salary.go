@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,8 +12,10 @@ import (
 	"github.com/ruziba3vich/argus/api/middleware"
 	"github.com/ruziba3vich/argus/internal/entity"
 	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/pkg/payslip"
 	"github.com/ruziba3vich/argus/internal/service"
 	logger "github.com/ruziba3vich/prodonik_lgger"
 )
@@ -34,6 +37,8 @@ type HandlerOption struct {
 type salaryRoutes struct {
 	handlers.BaseHandler // Inherit common methods like handleResponse
 	salaryUC             service.SalaryRepoInterface
+	currencyUC           service.CurrencyRepoInterface
+	minIO                *minio.MinIOClient
 	log                  *logger.Logger
 	cfg                  *config.Config
 	enforcer             *casbin.CachedEnforcer
@@ -42,18 +47,29 @@ type salaryRoutes struct {
 // NewSalaryRoutes sets up the routes for salary management.
 func NewSalaryRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
 	r := &salaryRoutes{
-		salaryUC: option.Salary, // Assuming option.Salary exists
-		log:      option.Logger,
-		cfg:      option.Config,
-		enforcer: option.Enforcer,
+		salaryUC:   option.Salary, // Assuming option.Salary exists
+		currencyUC: option.Currency,
+		minIO:      option.MinIO,
+		log:        option.Logger,
+		cfg:        option.Config,
+		enforcer:   option.Enforcer,
 	}
 
 	// Define authorization policies for salary endpoints
 	policies := [][]string{
-		{"admin", "/v1/salaries/", "GET"},
-		{"admin", "/v1/salaries/", "POST"},
-		{"admin", "/v1/salaries/", "PUT"},
-		{"admin", "/v1/salaries/", "DELETE"},
+		{"admin", "/v1/salaries", "GET"},
+		{"admin", "/v1/salaries/report", "GET"},
+		{"admin", "/v1/salaries/:id", "GET"},
+		{"admin", "/v1/salaries", "POST"},
+		{"admin", "/v1/salaries/:id", "PUT"},
+		{"admin", "/v1/salaries/:id", "DELETE"},
+		{"admin", "/v1/salaries/:id/restore", "PUT"},
+		{"admin", "/v1/salaries/:id/purge", "DELETE"},
+		{"admin", "/v1/salaries/:id/pay", "PUT"},
+		{"admin", "/v1/salaries/:id/mark-overdue", "PUT"},
+		{"admin", "/v1/salaries/:id/reopen", "PUT"},
+		{"admin", "/v1/salaries/:id/payslip", "GET"},
+		{"admin", "/v1/salaries/:id/payslip", "POST"},
 		// Users might see their own salaries via a different, more secure route like /users/me/salaries
 	}
 
@@ -66,13 +82,21 @@ func NewSalaryRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption
 
 	salaryGroup := apiV1Group.Group("/salaries")
 	{
-		// salaryGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+		salaryGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		salaryGroup.POST("", r.createSalary)
+		salaryGroup.GET("/report", r.getSalaryReport)
 		salaryGroup.GET("/:id", r.getSalaryByID)
 		salaryGroup.GET("", r.getAllSalaries)
 		salaryGroup.PUT("/:id", r.updateSalary)
 		salaryGroup.DELETE("/:id", r.deleteSalary)
+		salaryGroup.PUT("/:id/restore", r.restoreSalary)
+		salaryGroup.DELETE("/:id/purge", r.purgeSalary)
+		salaryGroup.PUT("/:id/pay", r.paySalary)
+		salaryGroup.PUT("/:id/mark-overdue", r.markSalaryOverdue)
+		salaryGroup.PUT("/:id/reopen", r.reopenSalary)
+		salaryGroup.GET("/:id/payslip", r.downloadPayslip)
+		salaryGroup.POST("/:id/payslip", r.generatePayslip)
 	}
 }
 
@@ -110,7 +134,7 @@ func (r *salaryRoutes) handleResponse(c *gin.Context, status Status, customMessa
 
 // @Router /salaries [post]
 // @Summary Create a new salary record
-// @Description Creates a new salary record for a user
+// @Description Creates a new pending salary record for a user. It is paid through /salaries/{id}/pay. When the user has a compensation profile, the salary must be in its currency and, for a monthly rate, not exceed the contractual amount
 // @Tags SALARIES
 // @Accept json
 // @Produce json
@@ -126,13 +150,26 @@ func (r *salaryRoutes) createSalary(c *gin.Context) {
 		return
 	}
 
-	if req.UserID == 0 || req.AdminID == 0 || req.Amount <= 0 || req.PayDate.IsZero() || req.Currency == "" || req.Status == "" {
-		r.handleResponse(c, BadRequest, "Missing or invalid required fields: user_id, admin_id, amount, pay_date, currency, status", nil)
+	if req.UserID == 0 || req.AdminID == 0 || req.Amount <= 0 || req.PayDate.IsZero() || req.Currency == "" {
+		r.handleResponse(c, BadRequest, "Missing or invalid required fields: user_id, admin_id, amount, pay_date, currency", nil)
+		return
+	}
+
+	// Every salary starts pending and moves on through the status operations
+	if req.Status == "" {
+		req.Status = entity.SalaryStatusPending
+	}
+	if req.Status != entity.SalaryStatusPending {
+		r.handleResponse(c, BadRequest, "New salaries are pending, use /salaries/{id}/pay to pay them", nil)
 		return
 	}
 
 	createdSalary, err := r.salaryUC.Create(c, &req)
 	if err != nil {
+		if errors.Is(err, entity.ErrorSalaryContract) {
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+			return
+		}
 		r.log.Error("Error while creating salary", map[string]any{"error": err.Error()})
 		r.handleResponse(c, InternalServerError, "Error while creating salary", err.Error())
 		return
@@ -187,7 +224,9 @@ func (r *salaryRoutes) getSalaryByID(c *gin.Context) {
 // @Param admin_id query int false "Filter by Admin ID"
 // @Param status query string false "Filter by Status (e.g., 'paid', 'pending')"
 // @Param pay_date query string false "Filter by Pay Date (YYYY-MM-DD)"
+// @Param payroll_run_id query int false "Filter by Payroll Run ID"
 // @Param search query string false "Search by currency or status"
+// @Param deleted query bool false "List the salaries in the trash instead"
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of records per page" default(10)
 // @Success 200 {object} Response{data=entity.GetAllSalariesResponse} "Successfully retrieved salaries"
@@ -229,6 +268,24 @@ func (r *salaryRoutes) getAllSalaries(c *gin.Context) {
 		}
 	}
 
+	if runIDStr := c.Query("payroll_run_id"); runIDStr != "" {
+		if _, err := strconv.ParseInt(runIDStr, 10, 64); err == nil {
+			filter["payroll_run_id"] = runIDStr
+		} else {
+			r.handleResponse(c, BadRequest, "Invalid payroll_run_id format", nil)
+			return
+		}
+	}
+
+	if deleted := c.Query("deleted"); deleted != "" {
+		showDeleted, err := strconv.ParseBool(deleted)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid deleted format", nil)
+			return
+		}
+		filter["deleted"] = strconv.FormatBool(showDeleted)
+	}
+
 	offset := (page - 1) * limit
 	if offset < 0 {
 		offset = 0
@@ -246,7 +303,7 @@ func (r *salaryRoutes) getAllSalaries(c *gin.Context) {
 
 // @Router /salaries/{id} [put]
 // @Summary Update a salary record
-// @Description Updates an existing salary record by its ID.
+// @Description Updates an existing salary record by its ID. The status is changed through /pay, /mark-overdue and /reopen instead. Hand-entered salaries are checked against the compensation in effect like on create. Only pending and overdue salaries whose payout has not started can be edited, and payroll run salaries keep their currency
 // @Tags SALARIES
 // @Accept json
 // @Produce json
@@ -256,6 +313,7 @@ func (r *salaryRoutes) getAllSalaries(c *gin.Context) {
 // @Success 200 {object} Response{data=string} "Salary updated successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
 // @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is paid, being paid out or its run currency would change"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *salaryRoutes) updateSalary(c *gin.Context) {
 	idStr := strings.TrimSpace(c.Param("id"))
@@ -271,6 +329,11 @@ func (r *salaryRoutes) updateSalary(c *gin.Context) {
 		return
 	}
 
+	if req.Status != nil {
+		r.handleResponse(c, BadRequest, "The status is changed through /pay, /mark-overdue and /reopen", nil)
+		return
+	}
+
 	req.ID = id // Set the ID from the URL path
 
 	err = r.salaryUC.Update(c, &req)
@@ -285,6 +348,14 @@ func (r *salaryRoutes) updateSalary(c *gin.Context) {
 			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
 			return
 		}
+		if errors.Is(err, entity.ErrorSalaryContract) {
+			r.handleResponse(c, BadRequest, err.Error(), nil)
+			return
+		}
+		if errors.Is(err, entity.ErrorSalaryTransition) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Salary with ID %d cannot be changed this way", id), err.Error())
+			return
+		}
 		r.handleResponse(c, InternalServerError, "Error while updating salary", err.Error())
 		return
 	}
@@ -294,7 +365,7 @@ func (r *salaryRoutes) updateSalary(c *gin.Context) {
 
 // @Router /salaries/{id} [delete]
 // @Summary Delete a salary record
-// @Description Deletes a salary record by its unique ID
+// @Description Moves a salary to the trash, from where it can be restored or purged. Paid salaries and ones whose payout has started are kept
 // @Tags SALARIES
 // @Accept json
 // @Produce json
@@ -303,6 +374,7 @@ func (r *salaryRoutes) updateSalary(c *gin.Context) {
 // @Success 200 {object} Response{data=string} "Salary deleted successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
 // @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is paid out"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *salaryRoutes) deleteSalary(c *gin.Context) {
 	idStr := strings.TrimSpace(c.Param("id"))
@@ -320,9 +392,377 @@ func (r *salaryRoutes) deleteSalary(c *gin.Context) {
 			r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d not found", id), nil)
 			return
 		}
+		if errors.Is(err, entity.ErrorPaidOut) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Salary with ID %d is paid out and cannot be deleted", id), err.Error())
+			return
+		}
 		r.handleResponse(c, InternalServerError, "Error while deleting salary", err.Error())
 		return
 	}
 
 	r.handleResponse(c, OK, "Salary deleted successfully", nil)
 }
+
+// @Router /salaries/{id}/restore [put]
+// @Summary Restore a deleted salary
+// @Description Takes a salary out of the trash
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID to restore"
+// @Success 200 {object} Response{data=string} "Salary restored successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not in the trash"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) restoreSalary(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	err = r.salaryUC.Restore(c, id)
+	if err != nil {
+		r.log.Error("Error while restoring salary", map[string]any{"error": err.Error(), "salary_id": id})
+
+		if strings.Contains(err.Error(), "no deleted salary found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted salary with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while restoring salary", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Salary restored successfully", nil)
+}
+
+// @Router /salaries/{id}/purge [delete]
+// @Summary Purge a deleted salary
+// @Description Removes a salary in the trash for good. Paid salaries and ones whose payout has started are kept
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID to purge"
+// @Success 200 {object} Response{data=string} "Salary purged successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not in the trash"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is paid out"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) purgeSalary(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	err = r.salaryUC.Purge(c, id)
+	if err != nil {
+		r.log.Error("Error while purging salary", map[string]any{"error": err.Error(), "salary_id": id})
+
+		if strings.Contains(err.Error(), "no deleted salary found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted salary with ID %d not found", id), nil)
+			return
+		}
+		if errors.Is(err, entity.ErrorPaidOut) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("Salary with ID %d is paid out and cannot be purged", id), err.Error())
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while purging salary", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Salary purged successfully", nil)
+}
+
+// transition moves the salary of the path to status on behalf of the
+// authenticated admin and writes the response.
+func (r *salaryRoutes) transition(c *gin.Context, status entity.SalaryStatus, req *entity.SalaryTransitionRequest, message string) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	adminID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || adminID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	req.ID = id
+	req.AdminID = adminID
+	req.Status = status
+
+	salary, err := r.salaryUC.Transition(c, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no salary found with ID"):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d not found", id), nil)
+		case errors.Is(err, entity.ErrorSalaryTransition):
+			r.handleResponse(c, Conflict, fmt.Sprintf("Salary with ID %d cannot be moved to %s", id, status), err.Error())
+		default:
+			r.log.Error("Error while changing salary status", map[string]any{"error": err.Error(), "salary_id": id, "status": status})
+			r.handleResponse(c, InternalServerError, "Error while changing salary status", err.Error())
+		}
+		return
+	}
+
+	// The salary is paid whatever happens to its payslip, which can be
+	// generated again through POST /salaries/{id}/payslip
+	if salary.Status == entity.SalaryStatusPaid {
+		if err := issuePayslip(c, r.salaryUC, r.minIO, salary); err != nil {
+			r.log.Error("Error while generating payslip", map[string]any{"error": err.Error(), "salary_id": id})
+		}
+	}
+
+	r.handleResponse(c, OK, message, salary)
+}
+
+// @Router /salaries/{id}/pay [put]
+// @Summary Pay a salary
+// @Description Marks a pending or overdue salary as paid, recording the paying admin and time, and generates its payslip
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {object} Response{data=entity.Salary} "Salary paid successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is already paid"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) paySalary(c *gin.Context) {
+	r.transition(c, entity.SalaryStatusPaid, &entity.SalaryTransitionRequest{}, "Salary paid successfully")
+}
+
+// @Router /salaries/{id}/mark-overdue [put]
+// @Summary Mark a salary overdue
+// @Description Marks a pending salary as overdue. Pending salaries past their pay date are also marked overdue every night
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {object} Response{data=entity.Salary} "Salary marked overdue"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is not pending"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) markSalaryOverdue(c *gin.Context) {
+	r.transition(c, entity.SalaryStatusOverdue, &entity.SalaryTransitionRequest{}, "Salary marked overdue")
+}
+
+// @Router /salaries/{id}/reopen [put]
+// @Summary Reopen a salary
+// @Description Moves a paid or overdue salary back to pending, optionally with a new pay date. The payslip is dropped and generated again when the salary is paid. A salary whose payout is in progress or succeeded cannot be reopened
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Param salary body entity.SalaryTransitionRequest false "New pay date"
+// @Success 200 {object} Response{data=entity.Salary} "Salary reopened successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format or request body"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is already pending or paid out"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) reopenSalary(c *gin.Context) {
+	var req entity.SalaryTransitionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	r.transition(c, entity.SalaryStatusPending, &req, "Salary reopened successfully")
+}
+
+// @Router /salaries/report [get]
+// @Summary Get a salary payout report
+// @Description Totals the salaries due in the range in one reporting currency, overall and per status. Each salary is converted at the latest exchange rate known on its pay date (Admins only)
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param currency query string false "Reporting currency" default(USD)
+// @Param from query string false "First pay date (YYYY-MM-DD), defaults to the start of the current month"
+// @Param to query string false "Last pay date (YYYY-MM-DD), defaults to today"
+// @Param user_id query int false "Filter by User ID"
+// @Param status query string false "Filter by Status (pending, paid, overdue)"
+// @Success 200 {object} Response{data=entity.PayoutReport} "Salary payout report"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters or missing exchange rate"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) getSalaryReport(c *gin.Context) {
+	filter := make(map[string]string)
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err != nil {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+		filter["user_id"] = userIDStr
+	}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	from, to, msg := parseDateRange(c)
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	currency := entity.Currency(strings.ToUpper(c.DefaultQuery("currency", string(entity.CurrencyUSD))))
+	msg, err := checkCurrency(c, r.currencyUC, currency)
+	if err != nil {
+		r.log.Error("Error while checking currency", map[string]any{"error": err.Error(), "currency": currency})
+		r.handleResponse(c, InternalServerError, "Error while checking currency", err.Error())
+		return
+	}
+	if msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	report, err := r.salaryUC.Report(c, filter, from, to, currency)
+	if err != nil {
+		if errors.Is(err, entity.ErrorNoExchangeRate) {
+			r.handleResponse(c, BadRequest, "Missing exchange rate: "+err.Error(), nil)
+			return
+		}
+		r.log.Error("Error while building salary report", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error building salary report", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, report)
+}
+
+// issuePayslip renders the payslip of a paid salary, uploads it and links it
+// to the salary. It is stored under the same key every time, so generating it
+// again replaces the previous document.
+func issuePayslip(c *gin.Context, salaryUC service.SalaryRepoInterface, minIO *minio.MinIOClient, salary *entity.Salary) error {
+	slip, err := salaryUC.Payslip(c, salary.ID)
+	if err != nil {
+		return err
+	}
+
+	key := payslip.Key(salary.UserID, salary.ID)
+	if err := minIO.UploadBytes(c, key, payslip.Render(slip), payslip.ContentType); err != nil {
+		return fmt.Errorf("%w: %v", minio.ErrFileUploadFailed, err)
+	}
+
+	if err := salaryUC.SetPayslip(c, salary.ID, key); err != nil {
+		return err
+	}
+	salary.PayslipKey = &key
+
+	return nil
+}
+
+// servePayslip streams the payslip of salary as a download.
+func servePayslip(c *gin.Context, minIO *minio.MinIOClient, salary *entity.Salary) error {
+	object, info, err := minIO.GetFile(c, *salary.PayslipKey)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	c.DataFromReader(OK.Code, info.Size, payslip.ContentType, object, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, payslip.FileName(salary)),
+	})
+
+	return nil
+}
+
+// @Router /salaries/{id}/payslip [post]
+// @Summary Generate a payslip
+// @Description Generates the payslip of a paid salary again, replacing the stored document. Payslips are generated when a salary is paid, so this is only needed when that failed or the bonuses of the period changed (Admins only)
+// @Tags SALARIES
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {object} Response{data=entity.Salary} "Payslip generated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format or missing exchange rate"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Salary is not paid"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) generatePayslip(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	salary, err := r.salaryUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting salary by ID", map[string]any{"error": err.Error(), "salary_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving salary", err.Error())
+		return
+	}
+
+	if err := issuePayslip(c, r.salaryUC, r.minIO, salary); err != nil {
+		switch {
+		case errors.Is(err, entity.ErrorSalaryNotPaid):
+			r.handleResponse(c, Conflict, fmt.Sprintf("Salary with ID %d is not paid", id), nil)
+		case errors.Is(err, entity.ErrorNoExchangeRate):
+			r.handleResponse(c, BadRequest, "Missing exchange rate: "+err.Error(), nil)
+		default:
+			r.log.Error("Error while generating payslip", map[string]any{"error": err.Error(), "salary_id": id})
+			r.handleResponse(c, InternalServerError, "Error while generating payslip", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, "Payslip generated successfully", salary)
+}
+
+// @Router /salaries/{id}/payslip [get]
+// @Summary Download a payslip
+// @Description Downloads the payslip PDF of a paid salary (Admins only)
+// @Tags SALARIES
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Salary ID"
+// @Success 200 {file} file "Payslip PDF"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Salary or payslip not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *salaryRoutes) downloadPayslip(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid salary ID format", nil)
+		return
+	}
+
+	salary, err := r.salaryUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting salary by ID", map[string]any{"error": err.Error(), "salary_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving salary", err.Error())
+		return
+	}
+	if salary.PayslipKey == nil {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Salary with ID %d has no payslip", id), nil)
+		return
+	}
+
+	if err := servePayslip(c, r.minIO, salary); err != nil {
+		r.log.Error("Error while downloading payslip", map[string]any{"error": err.Error(), "salary_id": id})
+		r.handleResponse(c, InternalServerError, "Error while downloading payslip", err.Error())
+	}
+}
@@ -0,0 +1,347 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type shiftRoutes struct {
+	handlers.BaseHandler
+	shiftUC  service.ShiftRepoInterface
+	log      *logger.Logger
+	cfg      *config.Config
+	enforcer *casbin.CachedEnforcer
+}
+
+// NewShiftRoutes sets up the routes for managing users' weekly work schedules.
+func NewShiftRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &shiftRoutes{
+		shiftUC:  option.Shift,
+		log:      option.Logger,
+		cfg:      option.Config,
+		enforcer: option.Enforcer,
+	}
+
+	// Define authorization policies for shift endpoints
+	policies := [][]string{
+		{"admin", "/v1/shifts", "GET"},
+		{"admin", "/v1/shifts/:id", "GET"},
+		{"admin", "/v1/shifts", "POST"},
+		{"admin", "/v1/shifts/:id", "PUT"},
+		{"admin", "/v1/shifts/:id", "DELETE"},
+		// Users see their own schedule through /v1/me/shifts
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during shifts enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	shiftsGroup := apiV1Group.Group("/shifts")
+	{
+		shiftsGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		shiftsGroup.POST("", r.createShift)
+		shiftsGroup.GET("/:id", r.getShiftByID)
+		shiftsGroup.GET("", r.getAllShifts)
+		shiftsGroup.PUT("/:id", r.updateShift)
+		shiftsGroup.DELETE("/:id", r.deleteShift)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *shiftRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// validateShift checks the optional shift fields and returns a message for the first invalid one.
+func validateShift(weekday *int, startTime, endTime *string, graceMinutes *int, timezone *string) string {
+	if weekday != nil && (*weekday < 0 || *weekday > 6) {
+		return "Invalid weekday, expected 0 (Sunday) to 6 (Saturday)"
+	}
+	if startTime != nil {
+		if _, err := helper.ParseClock(*startTime); err != nil {
+			return "Invalid start_time, expected HH:MM"
+		}
+	}
+	if endTime != nil {
+		if _, err := helper.ParseClock(*endTime); err != nil {
+			return "Invalid end_time, expected HH:MM"
+		}
+	}
+	if graceMinutes != nil && *graceMinutes < 0 {
+		return "Invalid grace_minutes, must not be negative"
+	}
+	if timezone != nil {
+		if _, err := time.LoadLocation(*timezone); err != nil || *timezone == "" {
+			return "Invalid timezone, expected an IANA name such as Asia/Tashkent"
+		}
+	}
+
+	return ""
+}
+
+// @Router /shifts [post]
+// @Summary Create a shift
+// @Description Adds one weekday to a user's weekly work schedule. Start and end are HH:MM in the shift's time zone; an end before the start means the shift crosses midnight
+// @Tags SHIFTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param shift body entity.CreateShiftRequest true "Shift details"
+// @Success 201 {object} Response{data=entity.Shift} "Shift created successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or missing data"
+// @Failure 409 {object} Response{data=string} "Conflict - The user already has a shift on that weekday"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *shiftRoutes) createShift(c *gin.Context) {
+	var req entity.CreateShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if req.UserID == 0 || req.Weekday == nil || req.StartTime == "" || req.EndTime == "" {
+		r.handleResponse(c, BadRequest, "Missing required fields: user_id, weekday, start_time, end_time", nil)
+		return
+	}
+	if req.Timezone == "" {
+		req.Timezone = r.cfg.Attendance.Timezone
+	}
+	if msg := validateShift(req.Weekday, &req.StartTime, &req.EndTime, &req.GraceMinutes, &req.Timezone); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	createdShift, err := r.shiftUC.Create(c, &req)
+	if err != nil {
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, Conflict, fmt.Sprintf("User %d already has a shift on weekday %d", req.UserID, *req.Weekday), nil)
+			return
+		}
+		r.log.Error("Error while creating shift", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error while creating shift", err.Error())
+		return
+	}
+
+	r.handleResponse(c, Created, "Shift created successfully", createdShift)
+}
+
+// @Router /shifts/{id} [get]
+// @Summary Get a shift by ID
+// @Description Retrieves a single shift by its unique ID
+// @Tags SHIFTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Shift ID"
+// @Success 200 {object} Response{data=entity.Shift} "Shift details"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Shift not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *shiftRoutes) getShiftByID(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid shift ID format", nil)
+		return
+	}
+
+	shift, err := r.shiftUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		r.log.Error("Error while getting shift by ID", map[string]any{"error": err.Error(), "shift_id": id})
+
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Shift with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error retrieving shift", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, shift)
+}
+
+// @Router /shifts [get]
+// @Summary Get all shifts
+// @Description Retrieves shifts with optional filtering and pagination, ordered by user and weekday
+// @Tags SHIFTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Filter by User ID"
+// @Param weekday query int false "Filter by weekday, 0 (Sunday) to 6 (Saturday)"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllShiftsResponse} "Successfully retrieved shifts"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid query parameters"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *shiftRoutes) getAllShifts(c *gin.Context) {
+	page, limit := helper.GetPaginationParams(c)
+	filter := make(map[string]string)
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		if _, err := strconv.ParseInt(userIDStr, 10, 64); err == nil {
+			filter["user_id"] = userIDStr
+		} else {
+			r.handleResponse(c, BadRequest, "Invalid user_id format", nil)
+			return
+		}
+	}
+	if weekdayStr := c.Query("weekday"); weekdayStr != "" {
+		if weekday, err := strconv.Atoi(weekdayStr); err == nil && weekday >= 0 && weekday <= 6 {
+			filter["weekday"] = weekdayStr
+		} else {
+			r.handleResponse(c, BadRequest, "Invalid weekday, expected 0 (Sunday) to 6 (Saturday)", nil)
+			return
+		}
+	}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	shifts, err := r.shiftUC.List(c, uint64(limit), uint64(offset), filter)
+	if err != nil {
+		r.log.Error("Error while getting all shifts", map[string]any{"error": err.Error()})
+		r.handleResponse(c, InternalServerError, "Error retrieving shifts", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, shifts)
+}
+
+// @Router /shifts/{id} [put]
+// @Summary Update a shift
+// @Description Updates an existing shift by its ID
+// @Tags SHIFTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Shift ID to update"
+// @Param shift body entity.UpdateShiftRequest true "Updated shift details"
+// @Success 200 {object} Response{data=string} "Shift updated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
+// @Failure 404 {object} Response{data=string} "Not Found - Shift not found"
+// @Failure 409 {object} Response{data=string} "Conflict - The user already has a shift on that weekday"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *shiftRoutes) updateShift(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid shift ID format", nil)
+		return
+	}
+
+	var req entity.UpdateShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	if msg := validateShift(req.Weekday, req.StartTime, req.EndTime, req.GraceMinutes, req.Timezone); msg != "" {
+		r.handleResponse(c, BadRequest, msg, nil)
+		return
+	}
+
+	req.ID = id // Set the ID from the URL path
+
+	err = r.shiftUC.Update(c, &req)
+	if err != nil {
+		r.log.Error("Error while updating shift", map[string]any{"error": err.Error(), "shift_id": id})
+
+		if strings.Contains(err.Error(), "no shift found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Shift with ID %d not found", id), nil)
+			return
+		}
+		if strings.Contains(err.Error(), "no fields to update") {
+			r.handleResponse(c, BadRequest, "No fields provided to update", err.Error())
+			return
+		}
+		if errors.Is(err, entity.ErrorConflict) {
+			r.handleResponse(c, Conflict, "The user already has a shift on that weekday", nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while updating shift", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Shift updated successfully", nil)
+}
+
+// @Router /shifts/{id} [delete]
+// @Summary Delete a shift
+// @Description Deletes a shift by its unique ID, making that weekday a day off
+// @Tags SHIFTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Shift ID to delete"
+// @Success 200 {object} Response{data=string} "Shift deleted successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Shift not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *shiftRoutes) deleteShift(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid shift ID format", nil)
+		return
+	}
+
+	err = r.shiftUC.Delete(c, id)
+	if err != nil {
+		r.log.Error("Error while deleting shift", map[string]any{"error": err.Error(), "shift_id": id})
+
+		if strings.Contains(err.Error(), "no shift found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Shift with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while deleting shift", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Shift deleted successfully", nil)
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/ruziba3vich/argus/api/middleware"
 	"github.com/ruziba3vich/argus/internal/entity"
 	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/infrastructure/minio"
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 	"github.com/ruziba3vich/argus/internal/pkg/helper"
 	"github.com/ruziba3vich/argus/internal/service"
@@ -34,6 +36,8 @@ type HandlerOption struct {
 type taskRoutes struct {
 	handlers.BaseHandler // Inherit common methods like handleResponse
 	taskUC               service.TaskRepoInterface
+	fileUC               service.FileRepoInterface
+	minIO                *minio.MinIOClient
 	log                  *logger.Logger
 	cfg                  *config.Config
 	enforcer             *casbin.CachedEnforcer
@@ -43,6 +47,8 @@ type taskRoutes struct {
 func NewTaskRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
 	r := &taskRoutes{
 		taskUC:   option.Task, // Assuming option.Task exists
+		fileUC:   option.File,
+		minIO:    option.MinIO,
 		log:      option.Logger,
 		cfg:      option.Config,
 		enforcer: option.Enforcer,
@@ -50,12 +56,20 @@ func NewTaskRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	// Define authorization policies for task endpoints
 	policies := [][]string{
-		{"admin", "/v1/tasks/", "GET"},
-		{"admin", "/v1/tasks/", "POST"},
-		{"admin", "/v1/tasks/", "PUT"},
-		{"admin", "/v1/tasks/", "DELETE"},
-		{"user", "/v1/tasks/", "GET"}, // Users can view tasks assigned to them
-		{"user", "/v1/tasks/", "PUT"}, // Users can update the status of their own tasks
+		{"admin", "/v1/tasks", "GET"},
+		{"admin", "/v1/tasks/:id", "GET"},
+		{"admin", "/v1/tasks", "POST"},
+		{"admin", "/v1/tasks/:id", "PUT"},
+		{"admin", "/v1/tasks/:id", "DELETE"},
+		{"admin", "/v1/tasks/:id/restore", "PUT"},
+		{"admin", "/v1/tasks/:id/purge", "DELETE"},
+		{"admin", "/v1/tasks/:id/cancel", "PUT"},
+		{"admin", "/v1/tasks/:id/reopen", "PUT"},
+		{"user", "/v1/tasks", "GET"},              // Users can view tasks assigned to them
+		{"user", "/v1/tasks/:id", "GET"},          // Users can view tasks assigned to them
+		{"user", "/v1/tasks/:id/history", "GET"},  // Users can view the history of their own tasks
+		{"user", "/v1/tasks/:id/start", "PUT"},    // Users can work through their own tasks
+		{"user", "/v1/tasks/:id/complete", "PUT"}, // Users can work through their own tasks
 	}
 
 	for _, policy := range policies {
@@ -67,13 +81,20 @@ func NewTaskRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	taskGroup := apiV1Group.Group("/tasks")
 	{
-		// taskGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+		taskGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		taskGroup.POST("", r.createTask)
 		taskGroup.GET("/:id", r.getTaskByID)
 		taskGroup.GET("", r.getAllTasks)
 		taskGroup.PUT("/:id", r.updateTask)
 		taskGroup.DELETE("/:id", r.deleteTask)
+		taskGroup.PUT("/:id/restore", r.restoreTask)
+		taskGroup.DELETE("/:id/purge", r.purgeTask)
+		taskGroup.GET("/:id/history", r.getTaskHistory)
+		taskGroup.PUT("/:id/start", r.startTask)
+		taskGroup.PUT("/:id/complete", r.completeTask)
+		taskGroup.PUT("/:id/cancel", r.cancelTask)
+		taskGroup.PUT("/:id/reopen", r.reopenTask)
 	}
 }
 
@@ -109,9 +130,20 @@ func (r *taskRoutes) handleResponse(c *gin.Context, status Status, customMessage
 	})
 }
 
+// canAccessTask reports whether the caller may see the task. Admins see every
+// task, plain users only the ones assigned to them. The task, comment and file
+// routes all go through it.
+func canAccessTask(c *gin.Context, h *handlers.BaseHandler, task *entity.Task) bool {
+	if h.GetUserRole(c) != entity.UserRoleUser {
+		return true
+	}
+
+	return task.AssignedTo != nil && strconv.FormatInt(*task.AssignedTo, 10) == h.GetUserID(c)
+}
+
 // @Router /tasks [post]
 // @Summary Create a new task
-// @Description Creates a new task and can assign it to a user
+// @Description Creates a new pending task and can assign it to a user. The status is changed through /start, /complete, /cancel and /reopen
 // @Tags TASKS
 // @Accept json
 // @Produce json
@@ -127,8 +159,8 @@ func (r *taskRoutes) createTask(c *gin.Context) {
 		return
 	}
 
-	if req.AdminID == 0 || req.Title == "" || req.Status == "" || req.Priority == "" {
-		r.handleResponse(c, BadRequest, "Missing required fields: admin_id, title, status, priority", nil)
+	if req.AdminID == 0 || req.Title == "" || req.Priority == "" {
+		r.handleResponse(c, BadRequest, "Missing required fields: admin_id, title, priority", nil)
 		return
 	}
 
@@ -174,6 +206,11 @@ func (r *taskRoutes) getTaskByID(c *gin.Context) {
 		return
 	}
 
+	if !canAccessTask(c, &r.BaseHandler, task) {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+		return
+	}
+
 	r.handleResponse(c, OK, nil, task)
 }
 
@@ -189,6 +226,7 @@ func (r *taskRoutes) getTaskByID(c *gin.Context) {
 // @Param status query string false "Filter by Status (e.g., 'todo', 'in_progress')"
 // @Param priority query string false "Filter by Priority (e.g., 'high', 'low')"
 // @Param search query string false "Search by title, description, status, or priority"
+// @Param deleted query bool false "List the tasks in the trash instead"
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of records per page" default(10)
 // @Success 200 {object} Response{data=entity.GetAllTasksResponse} "Successfully retrieved tasks"
@@ -222,6 +260,21 @@ func (r *taskRoutes) getAllTasks(c *gin.Context) {
 		filter["priority"] = priority
 	}
 
+	// Only admins can look into the trash
+	if deleted := c.Query("deleted"); deleted != "" && r.GetUserRole(c) != entity.UserRoleUser {
+		showDeleted, err := strconv.ParseBool(deleted)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid deleted format", nil)
+			return
+		}
+		filter["deleted"] = strconv.FormatBool(showDeleted)
+	}
+
+	// Plain users may only list tasks assigned to them
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		filter["assignedto"] = r.GetUserID(c)
+	}
+
 	offset := (page - 1) * limit
 	if offset < 0 {
 		offset = 0
@@ -239,7 +292,7 @@ func (r *taskRoutes) getAllTasks(c *gin.Context) {
 
 // @Router /tasks/{id} [put]
 // @Summary Update a task
-// @Description Updates an existing task by its ID.
+// @Description Updates an existing task by its ID. The status is changed through the start, complete, cancel and reopen actions (Admins only)
 // @Tags TASKS
 // @Accept json
 // @Produce json
@@ -287,7 +340,7 @@ func (r *taskRoutes) updateTask(c *gin.Context) {
 
 // @Router /tasks/{id} [delete]
 // @Summary Delete a task
-// @Description Deletes a task by its unique ID (Admins only)
+// @Description Moves a task to the trash, from where it can be restored or purged (Admins only)
 // @Tags TASKS
 // @Accept json
 // @Produce json
@@ -319,3 +372,243 @@ func (r *taskRoutes) deleteTask(c *gin.Context) {
 
 	r.handleResponse(c, OK, "Task deleted successfully", nil)
 }
+
+// @Router /tasks/{id}/restore [put]
+// @Summary Restore a deleted task
+// @Description Takes a task out of the trash
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID to restore"
+// @Success 200 {object} Response{data=string} "Task restored successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not in the trash"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) restoreTask(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return
+	}
+
+	err = r.taskUC.Restore(c, id)
+	if err != nil {
+		r.log.Error("Error while restoring task", map[string]any{"error": err.Error(), "task_id": id})
+
+		if strings.Contains(err.Error(), "no deleted task found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted task with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while restoring task", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Task restored successfully", nil)
+}
+
+// @Router /tasks/{id}/purge [delete]
+// @Summary Purge a deleted task
+// @Description Removes a task in the trash for good, along with its files
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID to purge"
+// @Success 200 {object} Response{data=string} "Task purged successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not in the trash"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) purgeTask(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return
+	}
+
+	// The file rows go with the task, so their objects are looked up first
+	keys, err := r.fileUC.ObjectKeys(c, id)
+	if err != nil {
+		r.log.Error("Error while getting task files", map[string]any{"error": err.Error(), "task_id": id})
+		r.handleResponse(c, InternalServerError, "Error while purging task", err.Error())
+		return
+	}
+
+	err = r.taskUC.Purge(c, id)
+	if err != nil {
+		r.log.Error("Error while purging task", map[string]any{"error": err.Error(), "task_id": id})
+
+		if strings.Contains(err.Error(), "no deleted task found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted task with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while purging task", err.Error())
+		return
+	}
+
+	for _, key := range keys {
+		if err := r.minIO.DeleteFile(c, key); err != nil {
+			r.log.Error("Error while deleting file from storage", map[string]any{"error": err.Error(), "object_key": key})
+		}
+	}
+
+	r.handleResponse(c, OK, "Task purged successfully", nil)
+}
+
+// transition moves the task of the path to status on behalf of the
+// authenticated user and writes the response. Plain users may only move
+// tasks assigned to them.
+func (r *taskRoutes) transition(c *gin.Context, status entity.TaskStatus, message string) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return
+	}
+
+	actorID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || actorID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return
+	}
+
+	if r.GetUserRole(c) == entity.UserRoleUser {
+		task, err := r.taskUC.Get(c, map[string]string{"id": idStr})
+		if err != nil || !canAccessTask(c, &r.BaseHandler, task) {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+			return
+		}
+	}
+
+	task, err := r.taskUC.Transition(c, &entity.TaskTransitionRequest{ID: id, ActorID: actorID, Status: status})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "no task found with ID"):
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+		case errors.Is(err, entity.ErrorTaskTransition):
+			r.handleResponse(c, Conflict, fmt.Sprintf("Task with ID %d cannot be moved to %s", id, status), err.Error())
+		default:
+			r.log.Error("Error while changing task status", map[string]any{"error": err.Error(), "task_id": id, "status": status})
+			r.handleResponse(c, InternalServerError, "Error while changing task status", err.Error())
+		}
+		return
+	}
+
+	r.handleResponse(c, OK, message, task)
+}
+
+// @Router /tasks/{id}/start [put]
+// @Summary Start a task
+// @Description Moves a pending task to in_progress. Plain users may only start tasks assigned to them
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} Response{data=entity.Task} "Task started successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Task is not pending"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) startTask(c *gin.Context) {
+	r.transition(c, entity.TaskStatusInProgress, "Task started successfully")
+}
+
+// @Router /tasks/{id}/complete [put]
+// @Summary Complete a task
+// @Description Moves a pending or in_progress task to completed and records when. Plain users may only complete tasks assigned to them
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} Response{data=entity.Task} "Task completed successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Task is already completed or cancelled"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) completeTask(c *gin.Context) {
+	r.transition(c, entity.TaskStatusCompleted, "Task completed successfully")
+}
+
+// @Router /tasks/{id}/cancel [put]
+// @Summary Cancel a task
+// @Description Moves a pending or in_progress task to cancelled (Admins only)
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} Response{data=entity.Task} "Task cancelled successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Task is already completed or cancelled"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) cancelTask(c *gin.Context) {
+	r.transition(c, entity.TaskStatusCancelled, "Task cancelled successfully")
+}
+
+// @Router /tasks/{id}/reopen [put]
+// @Summary Reopen a task
+// @Description Moves a completed or cancelled task back to pending, clearing its completion time (Admins only)
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} Response{data=entity.Task} "Task reopened successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 409 {object} Response{data=string} "Conflict - Task is still open"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) reopenTask(c *gin.Context) {
+	r.transition(c, entity.TaskStatusPending, "Task reopened successfully")
+}
+
+// @Router /tasks/{id}/history [get]
+// @Summary Get the status history of a task
+// @Description Lists every status the task moved through, oldest first, with who moved it and when. Plain users may only see the history of tasks assigned to them
+// @Tags TASKS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Success 200 {object} Response{data=[]entity.TaskStatusChange} "Task status history"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskRoutes) getTaskHistory(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return
+	}
+
+	task, err := r.taskUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+			return
+		}
+		r.log.Error("Error while getting task by ID", map[string]any{"error": err.Error(), "task_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving task", err.Error())
+		return
+	}
+
+	if !canAccessTask(c, &r.BaseHandler, task) {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+		return
+	}
+
+	history, err := r.taskUC.History(c, id)
+	if err != nil {
+		r.log.Error("Error while getting task history", map[string]any{"error": err.Error(), "task_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving task history", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, history)
+}
@@ -0,0 +1,427 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	handlers "github.com/ruziba3vich/argus/internal/https"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/service"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+type taskCommentRoutes struct {
+	handlers.BaseHandler
+	taskCommentUC  service.TaskCommentRepoInterface
+	taskUC         service.TaskRepoInterface
+	notificationUC service.NotificationRepoInterface
+	log            *logger.Logger
+	cfg            *config.Config
+	enforcer       *casbin.CachedEnforcer
+}
+
+// NewTaskCommentRoutes sets up the routes for the discussion and activity
+// feed of a task.
+func NewTaskCommentRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption) {
+	r := &taskCommentRoutes{
+		taskCommentUC:  option.TaskComment,
+		taskUC:         option.Task,
+		notificationUC: option.Notification,
+		log:            option.Logger,
+		cfg:            option.Config,
+		enforcer:       option.Enforcer,
+	}
+
+	// Define authorization policies for task comment endpoints. Plain users
+	// only reach the tasks assigned to them, see task below
+	policies := [][]string{
+		{"user", "/v1/tasks/:id/comments", "GET"},
+		{"user", "/v1/tasks/:id/comments", "POST"},
+		{"user", "/v1/tasks/:id/comments/:comment_id", "PUT"},
+		{"user", "/v1/tasks/:id/comments/:comment_id", "DELETE"},
+		{"user", "/v1/tasks/:id/activity", "GET"},
+	}
+
+	for _, policy := range policies {
+		_, err := option.Enforcer.AddPolicy(policy)
+		if err != nil {
+			option.Logger.Error("error during task comment enforcer add policies", map[string]any{"error": err.Error()})
+		}
+	}
+
+	taskGroup := apiV1Group.Group("/tasks/:id")
+	{
+		taskGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+
+		taskGroup.GET("/comments", r.getAllTaskComments)
+		taskGroup.POST("/comments", r.createTaskComment)
+		taskGroup.PUT("/comments/:comment_id", r.updateTaskComment)
+		taskGroup.DELETE("/comments/:comment_id", r.deleteTaskComment)
+		taskGroup.GET("/activity", r.getTaskActivity)
+	}
+}
+
+// handleResponse is a generic response handler.
+// In a real project, this would be a method of a common BaseHandler.
+func (r *taskCommentRoutes) handleResponse(c *gin.Context, status Status, customMessage any, data any) {
+	if status.Code >= 400 {
+		if data == nil {
+			data = customMessage
+		}
+
+		reqID, exists := c.Get(middleware.RequestIDHeader)
+		if exists {
+			data = fmt.Errorf("requestID : %s, %v", reqID, data)
+		}
+
+		r.log.Error(fmt.Sprintf("API Error: %s", data), map[string]any{"request_id": reqID, "status_code": status.Code})
+
+		c.JSON(status.Code, Response{
+			Status:        status.Status,
+			Description:   status.Description,
+			Data:          nil,
+			CustomMessage: customMessage,
+		})
+		return
+	}
+
+	c.JSON(status.Code, Response{
+		Status:        status.Status,
+		Description:   status.Description,
+		Data:          data,
+		CustomMessage: status.CustomMessage,
+	})
+}
+
+// callerID resolves the authenticated user from the sub claim and writes a 401
+// response when it is missing.
+func (r *taskCommentRoutes) callerID(c *gin.Context) (int64, bool) {
+	userID, err := strconv.ParseInt(r.GetUserID(c), 10, 64)
+	if err != nil || userID <= 0 {
+		r.handleResponse(c, Unauthorized, "Missing or invalid access token", nil)
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// task loads the task of the path and writes a 404 response when it does not
+// exist or the caller may not see it. Admins see every task, plain users only
+// the ones assigned to them.
+func (r *taskCommentRoutes) task(c *gin.Context) (*entity.Task, bool) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid task ID format", nil)
+		return nil, false
+	}
+
+	task, err := r.taskUC.Get(c, map[string]string{"id": idStr})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+			return nil, false
+		}
+		r.log.Error("Error while getting task by ID", map[string]any{"error": err.Error(), "task_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving task", err.Error())
+		return nil, false
+	}
+
+	if !canAccessTask(c, &r.BaseHandler, task) {
+		r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", id), nil)
+		return nil, false
+	}
+
+	return task, true
+}
+
+// comment loads the comment of the path on task and writes a 404 response
+// when there is none. Unless anyAuthor is set, only the author may go on.
+func (r *taskCommentRoutes) comment(c *gin.Context, task *entity.Task, callerID int64, anyAuthor bool) (*entity.TaskComment, bool) {
+	idStr := strings.TrimSpace(c.Param("comment_id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid comment ID format", nil)
+		return nil, false
+	}
+
+	comment, err := r.taskCommentUC.Get(c, map[string]string{"id": idStr, "task_id": strconv.FormatInt(task.ID, 10)})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Comment with ID %d not found", id), nil)
+			return nil, false
+		}
+		r.log.Error("Error while getting task comment by ID", map[string]any{"error": err.Error(), "comment_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving comment", err.Error())
+		return nil, false
+	}
+
+	if !anyAuthor && (comment.UserID == nil || *comment.UserID != callerID) {
+		r.handleResponse(c, Forbidden, "Only the author can change this comment", nil)
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// notifyMentions tells the users mentioned in a comment on task about it. A
+// failure is only logged, the comment is saved either way.
+func (r *taskCommentRoutes) notifyMentions(c *gin.Context, task *entity.Task, comment *entity.TaskComment) {
+	if len(comment.Mentions) == 0 {
+		return
+	}
+
+	_, err := r.notificationUC.Broadcast(c, &entity.BroadcastNotificationRequest{
+		UserIDs: comment.Mentions,
+		Message: fmt.Sprintf("You were mentioned in a comment on task %q.", task.Title),
+		Type:    entity.NotificationTypeApp,
+	})
+	if err != nil {
+		r.log.Error("Error while notifying mentioned users", map[string]any{"error": err.Error(), "comment_id": comment.ID})
+	}
+}
+
+// @Router /tasks/{id}/comments [post]
+// @Summary Comment on a task
+// @Description Adds a comment to a task. Users mentioned by email, as in @jane.doe@example.com, get an in-app notification if they can see the task, that is admins and the assignee. Plain users may only comment on tasks assigned to them
+// @Tags TASK COMMENTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param comment body entity.CreateTaskCommentRequest true "Comment"
+// @Success 201 {object} Response{data=entity.TaskComment} "Comment added successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or empty body"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskCommentRoutes) createTaskComment(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	var req entity.CreateTaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		r.handleResponse(c, BadRequest, "Missing required field: body", nil)
+		return
+	}
+
+	req.TaskID = task.ID
+	req.UserID = userID
+
+	createdComment, err := r.taskCommentUC.Create(c, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no task found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Task with ID %d not found", task.ID), nil)
+			return
+		}
+		r.log.Error("Error while creating task comment", map[string]any{"error": err.Error(), "task_id": task.ID})
+		r.handleResponse(c, InternalServerError, "Error while adding comment", err.Error())
+		return
+	}
+
+	r.notifyMentions(c, task, createdComment)
+
+	r.handleResponse(c, Created, "Comment added successfully", createdComment)
+}
+
+// @Router /tasks/{id}/comments [get]
+// @Summary Get the comments of a task
+// @Description Retrieves the comments of a task, oldest first, with pagination. Plain users may only read the comments of tasks assigned to them
+// @Tags TASK COMMENTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param search query string false "Search in the comment text"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetAllTaskCommentsResponse} "Successfully retrieved comments"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskCommentRoutes) getAllTaskComments(c *gin.Context) {
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	search := c.Query("search")
+	filter := map[string]string{"task_id": strconv.FormatInt(task.ID, 10)}
+
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	comments, err := r.taskCommentUC.List(c, uint64(limit), uint64(offset), filter, search)
+	if err != nil {
+		r.log.Error("Error while getting task comments", map[string]any{"error": err.Error(), "task_id": task.ID})
+		r.handleResponse(c, InternalServerError, "Error retrieving comments", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, comments)
+}
+
+// @Router /tasks/{id}/comments/{comment_id} [put]
+// @Summary Edit a comment
+// @Description Changes the text of a comment. Only the author can edit it, and only users newly mentioned by the edit are notified
+// @Tags TASK COMMENTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param comment_id path int true "Comment ID"
+// @Param comment body entity.UpdateTaskCommentRequest true "New comment text"
+// @Success 200 {object} Response{data=entity.TaskComment} "Comment updated successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or empty body"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden - Not the author"
+// @Failure 404 {object} Response{data=string} "Not Found - Task or comment not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskCommentRoutes) updateTaskComment(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := r.comment(c, task, userID, false)
+	if !ok {
+		return
+	}
+
+	var req entity.UpdateTaskCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		r.handleResponse(c, BadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		r.handleResponse(c, BadRequest, "Missing required field: body", nil)
+		return
+	}
+
+	req.ID = comment.ID
+
+	updatedComment, err := r.taskCommentUC.Update(c, &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "no task comment found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Comment with ID %d not found", comment.ID), nil)
+			return
+		}
+		r.log.Error("Error while updating task comment", map[string]any{"error": err.Error(), "comment_id": comment.ID})
+		r.handleResponse(c, InternalServerError, "Error while updating comment", err.Error())
+		return
+	}
+
+	r.notifyMentions(c, task, updatedComment)
+
+	r.handleResponse(c, OK, "Comment updated successfully", updatedComment)
+}
+
+// @Router /tasks/{id}/comments/{comment_id} [delete]
+// @Summary Delete a comment
+// @Description Deletes a comment. Plain users may only delete their own comments, admins any
+// @Tags TASK COMMENTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param comment_id path int true "Comment ID"
+// @Success 200 {object} Response{data=string} "Comment deleted successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 401 {object} Response{data=string} "Unauthorized"
+// @Failure 403 {object} Response{data=string} "Forbidden - Not the author"
+// @Failure 404 {object} Response{data=string} "Not Found - Task or comment not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskCommentRoutes) deleteTaskComment(c *gin.Context) {
+	userID, ok := r.callerID(c)
+	if !ok {
+		return
+	}
+
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := r.comment(c, task, userID, r.GetUserRole(c) != entity.UserRoleUser)
+	if !ok {
+		return
+	}
+
+	if err := r.taskCommentUC.Delete(c, comment.ID); err != nil {
+		r.log.Error("Error while deleting task comment", map[string]any{"error": err.Error(), "comment_id": comment.ID})
+
+		if strings.Contains(err.Error(), "no task comment found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Comment with ID %d not found", comment.ID), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while deleting comment", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "Comment deleted successfully", nil)
+}
+
+// @Router /tasks/{id}/activity [get]
+// @Summary Get the activity feed of a task
+// @Description Lists everything that happened to a task, newest first: comments, status changes, reassignments and attached files. Plain users may only see the activity of tasks assigned to them
+// @Tags TASK COMMENTS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Task ID"
+// @Param page query int false "Page number for pagination" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Success 200 {object} Response{data=entity.GetTaskActivityResponse} "Task activity feed"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - Task not found"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *taskCommentRoutes) getTaskActivity(c *gin.Context) {
+	task, ok := r.task(c)
+	if !ok {
+		return
+	}
+
+	page, limit := helper.GetPaginationParams(c)
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+
+	activity, err := r.taskUC.Activity(c, task.ID, uint64(limit), uint64(offset))
+	if err != nil {
+		r.log.Error("Error while getting task activity", map[string]any{"error": err.Error(), "task_id": task.ID})
+		r.handleResponse(c, InternalServerError, "Error retrieving task activity", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, nil, activity)
+}
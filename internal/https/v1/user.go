@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -75,10 +76,13 @@ func NewUserRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	// Define authorization policies for user endpoints
 	policies := [][]string{
-		{"admin", "/v1/users/", "GET"},
-		{"admin", "/v1/users/", "POST"},
-		{"admin", "/v1/users/", "PUT"},
-		{"admin", "/v1/users/", "DELETE"},
+		{"admin", "/v1/users", "GET"},
+		{"admin", "/v1/users/:id", "GET"},
+		{"admin", "/v1/users", "POST"},
+		{"admin", "/v1/users/:id", "PUT"},
+		{"admin", "/v1/users/:id", "DELETE"},
+		{"admin", "/v1/users/:id/restore", "PUT"},
+		{"admin", "/v1/users/:id/purge", "DELETE"},
 		// Users can get/update their own profiles via a different route like /users/me
 	}
 
@@ -91,13 +95,15 @@ func NewUserRoutes(apiV1Group *gin.RouterGroup, option *handlers.HandlerOption)
 
 	userGroup := apiV1Group.Group("/users")
 	{
-		// userGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
+		userGroup.Use(middleware.Authorizer(option.Enforcer, option.Logger))
 
 		userGroup.POST("", r.createUser)
 		userGroup.GET("/:id", r.getUserByID)
 		userGroup.GET("", r.getAllUsers)
 		userGroup.PUT("/:id", r.updateUser)
 		userGroup.DELETE("/:id", r.deleteUser)
+		userGroup.PUT("/:id/restore", r.restoreUser)
+		userGroup.DELETE("/:id/purge", r.purgeUser)
 	}
 }
 
@@ -138,6 +144,7 @@ func (r *userRoutes) handleResponse(c *gin.Context, status Status, customMessage
 // @Param user body entity.CreateUserRequest true "User creation details"
 // @Success 201 {object} Response{data=v1.UserResponse} "User created successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid input or validation error"
+// @Failure 403 {object} Response{data=string} "Forbidden - Only a super admin can create admins"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *userRoutes) createUser(c *gin.Context) {
 	var req entity.CreateUserRequest
@@ -156,6 +163,14 @@ func (r *userRoutes) createUser(c *gin.Context) {
 		r.handleResponse(c, BadRequest, "Invalid email format", nil)
 		return
 	}
+	if entity.GetUserRole(string(req.Role)) == "" {
+		r.handleResponse(c, BadRequest, "Invalid role, expected user, admin or super_admin", nil)
+		return
+	}
+	if privilegedRole(req.Role) && r.GetUserRole(c) != entity.UserRoleSuperAdmin {
+		r.handleResponse(c, Forbidden, "Only a super admin can create admins", nil)
+		return
+	}
 
 	createdUser, err := r.userUC.Create(c, &req)
 	if err != nil {
@@ -185,6 +200,41 @@ func (r *userRoutes) createUser(c *gin.Context) {
 	r.handleResponse(c, Created, "User created successfully", resp)
 }
 
+// privilegedRole reports whether role grants admin rights.
+func privilegedRole(role entity.UserRole) bool {
+	return role == entity.UserRoleAdmin || role == entity.UserRoleSuperAdmin
+}
+
+// canAssignRole reports whether the caller may give the user with the given
+// ID role, writing an error response when not. Only super admins grant admin
+// rights or change the role of an admin, so admins cannot promote themselves.
+func (r *userRoutes) canAssignRole(c *gin.Context, id int64, role entity.UserRole) bool {
+	if r.GetUserRole(c) == entity.UserRoleSuperAdmin {
+		return true
+	}
+	if privilegedRole(role) {
+		r.handleResponse(c, Forbidden, "Only a super admin can grant admin rights", nil)
+		return false
+	}
+
+	user, err := r.userUC.Get(c, map[string]string{"id": strconv.FormatInt(id, 10)})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows in result set") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("User with ID %d not found", id), nil)
+			return false
+		}
+		r.log.Error("Error while getting user by ID", map[string]any{"error": err.Error(), "user_id": id})
+		r.handleResponse(c, InternalServerError, "Error retrieving user", err.Error())
+		return false
+	}
+	if privilegedRole(user.Role) && user.Role != role {
+		r.handleResponse(c, Forbidden, "Only a super admin can change the role of an admin", nil)
+		return false
+	}
+
+	return true
+}
+
 // @Router /users/{id} [get]
 // @Summary Get a user by ID
 // @Description Retrieves a single user by their unique ID, without sensitive data
@@ -242,6 +292,7 @@ func (r *userRoutes) getUserByID(c *gin.Context) {
 // @Security BearerAuth
 // @Param role query string false "Filter by Role (e.g., 'admin', 'user')"
 // @Param search query string false "Search by name, email, or phone"
+// @Param deleted query bool false "List the users in the trash instead"
 // @Param page query int false "Page number for pagination" default(1)
 // @Param limit query int false "Number of records per page" default(10)
 // @Success 200 {object} Response{data=entity.GetAllUsersResponse} "Successfully retrieved users"
@@ -256,6 +307,15 @@ func (r *userRoutes) getAllUsers(c *gin.Context) {
 		filter["role"] = role
 	}
 
+	if deleted := c.Query("deleted"); deleted != "" {
+		showDeleted, err := strconv.ParseBool(deleted)
+		if err != nil {
+			r.handleResponse(c, BadRequest, "Invalid deleted format", nil)
+			return
+		}
+		filter["deleted"] = strconv.FormatBool(showDeleted)
+	}
+
 	offset := (page - 1) * limit
 	if offset < 0 {
 		offset = 0
@@ -283,6 +343,7 @@ func (r *userRoutes) getAllUsers(c *gin.Context) {
 // @Param user body v1.UpdateUserPayload true "Updated user details"
 // @Success 200 {object} Response{data=string} "User updated successfully"
 // @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID or request body"
+// @Failure 403 {object} Response{data=string} "Forbidden - Only a super admin can grant or change admin roles"
 // @Failure 404 {object} Response{data=string} "Not Found - User not found"
 // @Failure 500 {object} Response{data=string} "Internal Server Error"
 func (r *userRoutes) updateUser(c *gin.Context) {
@@ -299,19 +360,28 @@ func (r *userRoutes) updateUser(c *gin.Context) {
 		return
 	}
 
-	role := entity.GetUserRole(*payload.Role)
-
 	updateReq := &entity.UpdateUserRequest{
 		ID:        id,
 		FirstName: payload.FirstName,
 		LastName:  payload.LastName,
-		Role:      &role,
 		Email:     payload.Email,
 		Phone:     payload.Phone,
 		PhotoURL:  payload.PhotoURL,
 		Bio:       payload.Bio,
 	}
 
+	if payload.Role != nil {
+		role := entity.GetUserRole(*payload.Role)
+		if role == "" {
+			r.handleResponse(c, BadRequest, "Invalid role, expected user, admin or super_admin", nil)
+			return
+		}
+		if !r.canAssignRole(c, id, role) {
+			return
+		}
+		updateReq.Role = &role
+	}
+
 	// If a new password is provided, hash it before updating
 	if payload.Password != nil && *payload.Password != "" {
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*payload.Password), bcrypt.DefaultCost)
@@ -344,7 +414,7 @@ func (r *userRoutes) updateUser(c *gin.Context) {
 
 // @Router /users/{id} [delete]
 // @Summary Delete a user
-// @Description Deletes a user by their unique ID
+// @Description Moves a user to the trash, from where it can be restored or purged
 // @Tags USERS
 // @Accept json
 // @Produce json
@@ -375,3 +445,78 @@ func (r *userRoutes) deleteUser(c *gin.Context) {
 
 	r.handleResponse(c, OK, "User deleted successfully", nil)
 }
+
+// @Router /users/{id}/restore [put]
+// @Summary Restore a deleted user
+// @Description Takes a user out of the trash
+// @Tags USERS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID to restore"
+// @Success 200 {object} Response{data=string} "User restored successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - User not in the trash"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *userRoutes) restoreUser(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid user ID format", nil)
+		return
+	}
+
+	err = r.userUC.Restore(c, id)
+	if err != nil {
+		r.log.Error("Error while restoring user", map[string]any{"error": err.Error(), "user_id": id})
+
+		if strings.Contains(err.Error(), "no deleted user found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted user with ID %d not found", id), nil)
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while restoring user", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "User restored successfully", nil)
+}
+
+// @Router /users/{id}/purge [delete]
+// @Summary Purge a deleted user
+// @Description Removes a user in the trash for good. Users that salaries, bonuses, attendance or other records still point at cannot be purged
+// @Tags USERS
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID to purge"
+// @Success 200 {object} Response{data=string} "User purged successfully"
+// @Failure 400 {object} Response{data=string} "Bad Request - Invalid ID format"
+// @Failure 404 {object} Response{data=string} "Not Found - User not in the trash"
+// @Failure 409 {object} Response{data=string} "Conflict - User still has payroll history"
+// @Failure 500 {object} Response{data=string} "Internal Server Error"
+func (r *userRoutes) purgeUser(c *gin.Context) {
+	idStr := strings.TrimSpace(c.Param("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		r.handleResponse(c, BadRequest, "Invalid user ID format", nil)
+		return
+	}
+
+	err = r.userUC.Purge(c, id)
+	if err != nil {
+		r.log.Error("Error while purging user", map[string]any{"error": err.Error(), "user_id": id})
+
+		if strings.Contains(err.Error(), "no deleted user found with ID") {
+			r.handleResponse(c, NotFound, fmt.Sprintf("Deleted user with ID %d not found", id), nil)
+			return
+		}
+		if errors.Is(err, entity.ErrorUserHistory) {
+			r.handleResponse(c, Conflict, "The user still has payroll history and cannot be purged", err.Error())
+			return
+		}
+		r.handleResponse(c, InternalServerError, "Error while purging user", err.Error())
+		return
+	}
+
+	r.handleResponse(c, OK, "User purged successfully", nil)
+}
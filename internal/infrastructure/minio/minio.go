@@ -1,9 +1,12 @@
 package minio
 
 import (
+	"bytes"
 	"context"
 	"fmt"
-	"mime/multipart"
+	"io"
+	"mime"
+	"net/url"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -12,6 +15,10 @@ import (
 
 const MaxFileSize = 10 * 1024 * 1024
 
+// streamPartSize is the part size of uploads of unknown size. It is the
+// smallest MinIO accepts, as each part is buffered in memory.
+const streamPartSize = 5 * 1024 * 1024
+
 var (
 	ErrFileTooLarge     = fmt.Errorf("file size exceeds the maximum limit of %d bytes", MaxFileSize)
 	ErrFileNotFound     = fmt.Errorf("file not found")
@@ -55,31 +62,80 @@ func NewMinIOClient(cfg *config.Config) (*MinIOClient, error) {
 	return &MinIOClient{Client: client, config: cfg}, nil
 }
 
-func (m *MinIOClient) UploadFile(file multipart.File, fileName string, fileSize int64, contentType string) (string, error) {
-	ctx := context.Background()
+// UploadFile stores file under fileName. A fileSize of -1 streams a file of
+// unknown size. The bucket is private, objects are handed out with
+// PresignedURL or streamed with GetFile.
+func (m *MinIOClient) UploadFile(ctx context.Context, file io.Reader, fileName string, fileSize int64, contentType string) error {
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if fileSize < 0 {
+		opts.PartSize = streamPartSize
+	}
+
+	_, err := m.Client.PutObject(ctx, m.config.MinIO.BucketName, fileName, file, fileSize, opts)
+	if err != nil {
+		return err
+	}
 
-	_, err := m.Client.PutObject(ctx, m.config.MinIO.BucketName, fileName, file, fileSize, minio.PutObjectOptions{
+	return nil
+}
+
+// UploadBytes stores data generated by the service itself, such as payslips,
+// under fileName.
+func (m *MinIOClient) UploadBytes(ctx context.Context, fileName string, data []byte, contentType string) error {
+	_, err := m.Client.PutObject(ctx, m.config.MinIO.BucketName, fileName, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
 		ContentType: contentType,
 	})
 	if err != nil {
-		fmt.Println("err put: ", err)
-		return "", err
+		return err
 	}
 
-	fileURL := fmt.Sprintf("http://%s/%s/%s", m.config.MinIO.Endpoint, m.config.MinIO.BucketName, fileName)
-	return fileURL, nil
+	return nil
 }
 
-func (m *MinIOClient) DeleteFile(fileName string) error {
-	ctx := context.Background()
+// PresignedURL returns a URL that downloads the object stored under fileName
+// as downloadName without credentials. It stays valid for the configured
+// MINIO_PRESIGN_EXPIRY.
+func (m *MinIOClient) PresignedURL(ctx context.Context, fileName, downloadName string) (*url.URL, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", Attachment(downloadName))
+
+	return m.Client.PresignedGetObject(ctx, m.config.MinIO.BucketName, fileName, m.config.MinIO.PresignExpiry, params)
+}
+
+// Attachment returns the Content-Disposition value that downloads a file as
+// name, encoding names that are not plain ASCII.
+func Attachment(name string) string {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	if disposition == "" {
+		return "attachment"
+	}
+
+	return disposition
+}
+
+// GetFile opens the object stored under fileName along with its size and
+// content type. The caller closes it.
+func (m *MinIOClient) GetFile(ctx context.Context, fileName string) (*minio.Object, minio.ObjectInfo, error) {
+	object, err := m.Client.GetObject(ctx, m.config.MinIO.BucketName, fileName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		return nil, minio.ObjectInfo{}, err
+	}
 
+	return object, info, nil
+}
+
+// DeleteFile removes the object stored under fileName.
+func (m *MinIOClient) DeleteFile(ctx context.Context, fileName string) error {
 	err := m.Client.RemoveObject(ctx, m.config.MinIO.BucketName, fileName, minio.RemoveObjectOptions{})
 	if err != nil {
-		fmt.Println("err delete: ", err)
 		return err
 	}
 
-	fmt.Println("File deleted successfully:", fileName, "----", err)
-
 	return nil
 }
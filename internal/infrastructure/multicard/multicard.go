@@ -0,0 +1,253 @@
+// Package multicard pays employees to their cards through the Multicard
+// payment gateway.
+package multicard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+)
+
+const (
+	authPath   = "auth"
+	payoutPath = "payment/credit"
+
+	// IdempotencyHeader carries the payout key on every attempt of a payout.
+	IdempotencyHeader = "X-Idempotency-Key"
+
+	defaultAttempts = 3
+	defaultBackoff  = 500 * time.Millisecond
+	requestTimeout  = 30 * time.Second
+)
+
+var (
+	// ErrRejected means Multicard refused the payout, so retrying it with
+	// the same details will not help.
+	ErrRejected = errors.New("payout rejected by multicard")
+	// ErrUnavailable means Multicard could not be reached or kept failing
+	// after every retry. The payout may or may not have been made.
+	ErrUnavailable = errors.New("multicard is unavailable")
+)
+
+// PayoutRequest pays Amount tiyin (hundredths of a sum) to Card.
+type PayoutRequest struct {
+	Key     string // Idempotency key, the same on every attempt of one payout
+	Card    string // Card number or Multicard card token
+	Amount  int64
+	Details string
+}
+
+// PayoutResult is what Multicard answered for a payout.
+type PayoutResult struct {
+	TransactionID string `json:"uuid"`
+	Status        string `json:"status"`
+}
+
+// Client talks to the Multicard API with the application of the Aggr section
+// of the config. It is safe for concurrent use.
+type Client struct {
+	http          *http.Client
+	baseURL       string
+	applicationID string
+	secret        string
+	storeID       string
+	attempts      int
+	backoff       time.Duration
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewClient returns a client for the production API when the environment is
+// production and for the test API otherwise. Pointing
+// MULTICARD_AGGR_API_URL_TEST at a local stand-in such as NewStandIn makes
+// the whole payout flow run without touching Multicard.
+func NewClient(cfg *config.Config) *Client {
+	baseURL := cfg.Multicard.Aggr.APIURLTest
+	if cfg.Environment == "production" {
+		baseURL = cfg.Multicard.Aggr.APIURLProd
+	}
+
+	return &Client{
+		http:          &http.Client{Timeout: requestTimeout},
+		baseURL:       strings.TrimSuffix(baseURL, "/") + "/",
+		applicationID: cfg.Multicard.Aggr.ApplicationID,
+		secret:        cfg.Multicard.Aggr.Secret,
+		storeID:       cfg.Multicard.Aggr.StoreID,
+		attempts:      defaultAttempts,
+		backoff:       defaultBackoff,
+	}
+}
+
+// envelope is the shape of every Multicard response.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Details string `json:"details"`
+	} `json:"error"`
+}
+
+// Payout pays req. Transport errors, rate limiting and server errors are
+// retried with a growing delay and the same idempotency key, and an expired
+// token is renewed once. It returns an error wrapping ErrRejected when
+// Multicard refused the payout and ErrUnavailable when it gave up.
+func (c *Client) Payout(ctx context.Context, req *PayoutRequest) (*PayoutResult, error) {
+	body := map[string]any{
+		"store_id":   c.storeID,
+		"card":       req.Card,
+		"amount":     req.Amount,
+		"invoice_id": req.Key,
+		"details":    req.Details,
+	}
+
+	var (
+		result  PayoutResult
+		lastErr error
+		renewed bool
+	)
+	for attempt := 0; attempt < c.attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(c.backoff << (attempt - 1)):
+			}
+		}
+
+		token, err := c.authToken(ctx)
+		if err != nil {
+			lastErr = err
+			if errors.Is(err, ErrRejected) {
+				return nil, err
+			}
+			continue
+		}
+
+		status, err := c.post(ctx, payoutPath, token, req.Key, body, &result)
+		switch {
+		case err == nil:
+			return &result, nil
+		case status == http.StatusUnauthorized && !renewed:
+			// The token expired early, so get a new one without using up an attempt
+			c.dropToken(token)
+			renewed = true
+			attempt--
+		case status == http.StatusTooManyRequests || status >= http.StatusInternalServerError || status == 0:
+			lastErr = err
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("%w after %d attempts: %v", ErrUnavailable, c.attempts, lastErr)
+}
+
+// authToken returns the cached access token, getting a new one when it is
+// missing or about to expire.
+func (c *Client) authToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Now().Add(time.Minute).Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+
+	var auth struct {
+		Token  string `json:"token"`
+		Expiry string `json:"expiry"`
+	}
+	body := map[string]string{"application_id": c.applicationID, "secret": c.secret}
+	status, err := c.post(ctx, authPath, "", "", body, &auth)
+	if err != nil {
+		if status == http.StatusUnauthorized {
+			return "", fmt.Errorf("%w: authentication failed: %v", ErrRejected, err)
+		}
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	c.token = auth.Token
+	// Tokens last a day; keep one an hour less when the expiry is unreadable
+	c.tokenExpiry = time.Now().Add(23 * time.Hour)
+	if expiry, err := time.ParseInLocation(time.DateTime, auth.Expiry, time.Local); err == nil {
+		c.tokenExpiry = expiry
+	}
+
+	return c.token, nil
+}
+
+// dropToken forgets token unless another request already replaced it.
+func (c *Client) dropToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token == token {
+		c.token = ""
+	}
+}
+
+// post sends body as JSON and decodes the data of a successful response into
+// out. It returns the HTTP status, or 0 when no response was received.
+func (c *Client) post(ctx context.Context, path, token, key string, body, out any) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyHeader, key)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return resp.StatusCode, fmt.Errorf("%s %s returned %d: %s", http.MethodPost, path, resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest || !env.Success {
+		msg := fmt.Sprintf("%s returned %d", path, resp.StatusCode)
+		if env.Error != nil {
+			msg = fmt.Sprintf("%s: %s %s", msg, env.Error.Code, env.Error.Details)
+		}
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests &&
+			resp.StatusCode != http.StatusUnauthorized {
+			return resp.StatusCode, fmt.Errorf("%w: %s", ErrRejected, msg)
+		}
+		return resp.StatusCode, errors.New(msg)
+	}
+
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return resp.StatusCode, fmt.Errorf("decode %s response: %w", path, err)
+	}
+
+	return resp.StatusCode, nil
+}
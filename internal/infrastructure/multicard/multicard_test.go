@@ -0,0 +1,213 @@
+package multicard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+)
+
+const testBackoff = 20 * time.Millisecond
+
+// recorder notes every request reaching the stand-in, before the answer
+// leaves for the client.
+type recorder struct {
+	standIn *StandIn
+
+	mu       sync.Mutex
+	auths    int
+	payouts  []time.Time
+	keys     []string
+	statuses []int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	switch strings.TrimPrefix(req.URL.Path, "/") {
+	case authPath:
+		r.auths++
+	case payoutPath:
+		r.payouts = append(r.payouts, time.Now())
+		r.keys = append(r.keys, req.Header.Get(IdempotencyHeader))
+		w = &statusWriter{ResponseWriter: w, rec: r}
+	}
+	r.mu.Unlock()
+
+	r.standIn.ServeHTTP(w, req)
+}
+
+// statusWriter records the status of a payout answer.
+type statusWriter struct {
+	http.ResponseWriter
+	rec *recorder
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.rec.mu.Lock()
+	w.rec.statuses = append(w.rec.statuses, status)
+	w.rec.mu.Unlock()
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// newTestClient serves a stand-in accepting app/secret and returns a client
+// logging in with secret, with a short backoff.
+func newTestClient(t *testing.T, secret string) (*Client, *StandIn, *recorder) {
+	t.Helper()
+
+	standIn := NewStandIn("app", "secret")
+	rec := &recorder{standIn: standIn}
+	server := httptest.NewServer(rec)
+	t.Cleanup(server.Close)
+
+	var cfg config.Config
+	cfg.Multicard.Aggr.ApplicationID = "app"
+	cfg.Multicard.Aggr.Secret = secret
+	cfg.Multicard.Aggr.StoreID = "1"
+	cfg.Multicard.Aggr.APIURLTest = server.URL
+
+	client := NewClient(&cfg)
+	client.backoff = testBackoff
+
+	return client, standIn, rec
+}
+
+func TestPayoutRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		wantErr  error
+		attempts int
+		payouts  int
+	}{
+		{name: "no failures", failures: 0, attempts: 1, payouts: 1},
+		{name: "recovers", failures: 2, attempts: 3, payouts: 1},
+		{name: "gives up", failures: 3, wantErr: ErrUnavailable, attempts: 3, payouts: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, standIn, rec := newTestClient(t, "secret")
+			standIn.FailNext(tt.failures)
+
+			result, err := client.Payout(context.Background(), &PayoutRequest{Key: "payout-1", Card: "8600123412341234", Amount: 100})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Payout error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && result.TransactionID == "" {
+				t.Error("Payout returned no transaction ID")
+			}
+
+			if len(rec.payouts) != tt.attempts {
+				t.Fatalf("payout requests = %d, want %d", len(rec.payouts), tt.attempts)
+			}
+			if standIn.Payouts() != tt.payouts {
+				t.Errorf("payouts made = %d, want %d", standIn.Payouts(), tt.payouts)
+			}
+			for i, key := range rec.keys {
+				if key != "payout-1" {
+					t.Errorf("attempt %d sent %s %q, want %q", i+1, IdempotencyHeader, key, "payout-1")
+				}
+			}
+			// The delay doubles after each failure
+			for i := 1; i < len(rec.payouts); i++ {
+				want := testBackoff << (i - 1)
+				if gap := rec.payouts[i].Sub(rec.payouts[i-1]); gap < want {
+					t.Errorf("attempt %d came after %s, want at least %s", i+1, gap, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPayoutIdempotent(t *testing.T) {
+	client, standIn, _ := newTestClient(t, "secret")
+	req := &PayoutRequest{Key: "payout-1", Card: "8600123412341234", Amount: 100}
+
+	first, err := client.Payout(context.Background(), req)
+	if err != nil {
+		t.Fatalf("first Payout: %v", err)
+	}
+	second, err := client.Payout(context.Background(), req)
+	if err != nil {
+		t.Fatalf("second Payout: %v", err)
+	}
+
+	if first.TransactionID != second.TransactionID {
+		t.Errorf("transaction IDs = %q and %q, want the same", first.TransactionID, second.TransactionID)
+	}
+	if standIn.Payouts() != 1 {
+		t.Errorf("payouts made = %d, want 1", standIn.Payouts())
+	}
+}
+
+func TestPayoutRenewsToken(t *testing.T) {
+	client, standIn, rec := newTestClient(t, "secret")
+	ctx := context.Background()
+
+	if _, err := client.Payout(ctx, &PayoutRequest{Key: "payout-1", Card: "8600123412341234", Amount: 100}); err != nil {
+		t.Fatalf("first Payout: %v", err)
+	}
+
+	// The gateway forgets the token before the expiry it announced
+	standIn.mu.Lock()
+	standIn.tokens = make(map[string]bool)
+	standIn.mu.Unlock()
+
+	if _, err := client.Payout(ctx, &PayoutRequest{Key: "payout-2", Card: "8600123412341234", Amount: 100}); err != nil {
+		t.Fatalf("second Payout: %v", err)
+	}
+
+	if rec.auths != 2 {
+		t.Errorf("auth requests = %d, want 2", rec.auths)
+	}
+	want := []int{http.StatusOK, http.StatusUnauthorized, http.StatusOK}
+	if len(rec.statuses) != len(want) {
+		t.Fatalf("payout statuses = %v, want %v", rec.statuses, want)
+	}
+	for i := range want {
+		if rec.statuses[i] != want[i] {
+			t.Fatalf("payout statuses = %v, want %v", rec.statuses, want)
+		}
+	}
+}
+
+func TestPayoutRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		req      *PayoutRequest
+		attempts int
+	}{
+		{name: "unknown card", secret: "secret", req: &PayoutRequest{Key: "payout-1", Card: "0000123412341234", Amount: 100}, attempts: 1},
+		{name: "invalid amount", secret: "secret", req: &PayoutRequest{Key: "payout-1", Card: "8600123412341234", Amount: 0}, attempts: 1},
+		{name: "wrong secret", secret: "wrong", req: &PayoutRequest{Key: "payout-1", Card: "8600123412341234", Amount: 100}, attempts: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, standIn, rec := newTestClient(t, tt.secret)
+
+			_, err := client.Payout(context.Background(), tt.req)
+			if !errors.Is(err, ErrRejected) {
+				t.Fatalf("Payout error = %v, want %v", err, ErrRejected)
+			}
+			// A refusal is final, so it is not retried
+			if len(rec.payouts) != tt.attempts {
+				t.Errorf("payout requests = %d, want %d", len(rec.payouts), tt.attempts)
+			}
+			if rec.auths != 1 {
+				t.Errorf("auth requests = %d, want 1", rec.auths)
+			}
+			if standIn.Payouts() != 0 {
+				t.Errorf("payouts made = %d, want 0", standIn.Payouts())
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package multicard
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StandIn is a local stand-in for the Multicard endpoints the client uses. It
+// accepts the configured application, pays any card that does not start with
+// "0000", and answers a repeated invoice with the first answer, like the real
+// gateway.
+type StandIn struct {
+	ApplicationID string
+	Secret        string
+
+	mu       sync.Mutex
+	failNext int
+	tokens   map[string]bool
+	payouts  map[string]*PayoutResult
+}
+
+// NewStandIn returns a stand-in accepting the given application. Serve it with
+// http.ListenAndServe and point MULTICARD_AGGR_API_URL_TEST at it.
+func NewStandIn(applicationID, secret string) *StandIn {
+	return &StandIn{
+		ApplicationID: applicationID,
+		Secret:        secret,
+		tokens:        make(map[string]bool),
+		payouts:       make(map[string]*PayoutResult),
+	}
+}
+
+// FailNext makes the next n payout requests fail with 503, to exercise the
+// client's retries.
+func (s *StandIn) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failNext = n
+}
+
+// Payouts returns the number of distinct payouts made.
+func (s *StandIn) Payouts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.payouts)
+}
+
+func (s *StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStandIn(w, http.StatusMethodNotAllowed, nil, "METHOD_NOT_ALLOWED", r.Method)
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case authPath:
+		s.auth(w, r)
+	case payoutPath:
+		s.payout(w, r)
+	default:
+		writeStandIn(w, http.StatusNotFound, nil, "NOT_FOUND", r.URL.Path)
+	}
+}
+
+func (s *StandIn) auth(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ApplicationID string `json:"application_id"`
+		Secret        string `json:"secret"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStandIn(w, http.StatusBadRequest, nil, "BAD_REQUEST", err.Error())
+		return
+	}
+	if req.ApplicationID != s.ApplicationID || req.Secret != s.Secret {
+		writeStandIn(w, http.StatusUnauthorized, nil, "UNAUTHORIZED", "unknown application")
+		return
+	}
+
+	token := randomID()
+	s.mu.Lock()
+	s.tokens[token] = true
+	s.mu.Unlock()
+
+	writeStandIn(w, http.StatusOK, map[string]string{
+		"token":  token,
+		"expiry": time.Now().Add(24 * time.Hour).Format(time.DateTime),
+	}, "", "")
+}
+
+func (s *StandIn) payout(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")] {
+		writeStandIn(w, http.StatusUnauthorized, nil, "UNAUTHORIZED", "invalid token")
+		return
+	}
+	if s.failNext > 0 {
+		s.failNext--
+		writeStandIn(w, http.StatusServiceUnavailable, nil, "UNAVAILABLE", "try again later")
+		return
+	}
+
+	var req struct {
+		Card      string `json:"card"`
+		Amount    int64  `json:"amount"`
+		InvoiceID string `json:"invoice_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeStandIn(w, http.StatusBadRequest, nil, "BAD_REQUEST", err.Error())
+		return
+	}
+	if req.InvoiceID == "" || req.Amount <= 0 || req.Card == "" {
+		writeStandIn(w, http.StatusBadRequest, nil, "BAD_REQUEST", "invoice_id, amount and card are required")
+		return
+	}
+
+	if result, ok := s.payouts[req.InvoiceID]; ok {
+		writeStandIn(w, http.StatusOK, result, "", "")
+		return
+	}
+	if strings.HasPrefix(req.Card, "0000") {
+		writeStandIn(w, http.StatusBadRequest, nil, "CARD_NOT_FOUND", "card is not found")
+		return
+	}
+
+	result := &PayoutResult{TransactionID: randomID(), Status: "success"}
+	s.payouts[req.InvoiceID] = result
+	writeStandIn(w, http.StatusOK, result, "", "")
+}
+
+func writeStandIn(w http.ResponseWriter, status int, data any, code, details string) {
+	body := map[string]any{"success": code == "", "data": data}
+	if code != "" {
+		body["error"] = map[string]string{"code": code, "details": details}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
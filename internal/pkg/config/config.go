@@ -21,6 +21,9 @@ type (
 		Log           Log
 		PG            PG
 		DB            DB
+		Attendance    Attendance
+		Jobs          Jobs
+		Leave         Leave
 		RMQ           RMQ
 		Redis         Redis
 		Email         EmailConfig
@@ -79,6 +82,28 @@ type (
 		User     string
 		Password string
 		SSLMode  string
+		// MigrateOnStart applies pending schema migrations when the app starts.
+		MigrateOnStart bool
+	}
+
+	// Attendance -.
+	Attendance struct {
+		ShiftStart string // Clock time, e.g. 09:00
+		Timezone   string // IANA name, e.g. Asia/Tashkent
+	}
+
+	// Leave -.
+	Leave struct {
+		VacationDaysPerMonth float64
+		SickDaysPerMonth     float64
+	}
+
+	// Jobs -.
+	Jobs struct {
+		Enabled        bool
+		AbsenceCheckAt string // Clock time in Attendance.Timezone the previous day is closed at
+		OverdueCheckAt string // Clock time in Attendance.Timezone unpaid salaries turn overdue at
+		BonusRulesAt   string // Clock time in Attendance.Timezone the bonus rules are evaluated at
 	}
 
 	// RMQ -.
@@ -119,6 +144,8 @@ type (
 		AccessKey  string `env:"MINIO_ACCESS_KEY,required"`
 		SecretKey  string `env:"MINIO_SECRET_KEY,required"`
 		BucketName string `env:"MINIO_BUCKET_NAME,required"`
+		// PresignExpiry is how long a presigned download URL stays valid
+		PresignExpiry time.Duration `env:"MINIO_PRESIGN_EXPIRY"`
 	}
 
 	// Multicard
@@ -199,9 +226,24 @@ func NewConfig() (*Config, error) {
 	config.DB.User = getEnv("POSTGRES_USER", "argus")
 	config.DB.Password = getEnv("POSTGRES_PASSWORD", "v8Qe96csIhZZ")
 	config.DB.SSLMode = getEnv("POSTGRES_SSLMODE", "disable")
+	config.DB.MigrateOnStart = cast.ToBool(getEnv("POSTGRES_MIGRATE_ON_START", "true"))
 
 	config.PG.PoolMax = cast.ToInt(getEnv("POSTGRES_POOL_MAX", "1"))
 
+	// attendance configuration
+	config.Attendance.ShiftStart = getEnv("ATTENDANCE_SHIFT_START", "09:00")
+	config.Attendance.Timezone = getEnv("ATTENDANCE_TIMEZONE", "UTC")
+
+	// leave configuration
+	config.Leave.VacationDaysPerMonth = cast.ToFloat64(getEnv("LEAVE_VACATION_DAYS_PER_MONTH", "1.75"))
+	config.Leave.SickDaysPerMonth = cast.ToFloat64(getEnv("LEAVE_SICK_DAYS_PER_MONTH", "1"))
+
+	// background jobs configuration
+	config.Jobs.Enabled = cast.ToBool(getEnv("JOBS_ENABLED", "true"))
+	config.Jobs.AbsenceCheckAt = getEnv("JOBS_ABSENCE_CHECK_AT", "00:30")
+	config.Jobs.OverdueCheckAt = getEnv("JOBS_OVERDUE_CHECK_AT", "01:00")
+	config.Jobs.BonusRulesAt = getEnv("JOBS_BONUS_RULES_AT", "02:00")
+
 	// redis configuration
 	// config.Redis.Host = getEnv("REDIS_HOST", "108.181.201.147")
 	// config.Redis.Port = getEnv("REDIS_PORT", "6379")
@@ -227,6 +269,15 @@ func NewConfig() (*Config, error) {
 	config.MinIO.SecretKey = getEnv("MINIO_SECRET_KEY", "minioadmin123")
 	config.MinIO.BucketName = getEnv("MINIO_BUCKET_NAME", "argus")
 
+	// MinIO refuses to presign URLs for less than a second or more than a week
+	config.MinIO.PresignExpiry, err = time.ParseDuration(getEnv("MINIO_PRESIGN_EXPIRY", "15m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MINIO_PRESIGN_EXPIRY: %w", err)
+	}
+	if config.MinIO.PresignExpiry < time.Second || config.MinIO.PresignExpiry > 7*24*time.Hour {
+		return nil, fmt.Errorf("invalid MINIO_PRESIGN_EXPIRY %s, expected between 1s and 168h", config.MinIO.PresignExpiry)
+	}
+
 	// multicard configuration
 	config.Multicard.Aggr.ApplicationID = getEnv("MULTICARD_AGGR_APPLiCATION_ID", "application_id")
 	config.Multicard.Aggr.Secret = getEnv("MULTICARD_AGGR_SECRET", "secret_key")
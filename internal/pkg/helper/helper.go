@@ -2,6 +2,8 @@ package helper
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
@@ -59,6 +61,46 @@ func ValidatePhoneNumber(phoneNumber string) bool {
 	return true
 }
 
+// ParseClock parses a clock time such as "09:00" into an offset from midnight.
+func ParseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid clock time %q, expected HH:MM: %w", clock, err)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ParseShiftRule builds a shift rule from clock times such as "09:00" and an
+// IANA time zone name; an empty end means the shift length is unknown and an
+// empty zone means UTC.
+func ParseShiftRule(start, end string, graceMinutes int, timezone string) (*entity.ShiftRule, error) {
+	rule := &entity.ShiftRule{Grace: time.Duration(graceMinutes) * time.Minute}
+
+	var err error
+	if rule.Start, err = ParseClock(start); err != nil {
+		return nil, err
+	}
+	if end != "" {
+		endsAt, err := ParseClock(end)
+		if err != nil {
+			return nil, err
+		}
+
+		// An end before the start means the shift crosses midnight
+		rule.Length = endsAt - rule.Start
+		if rule.Length <= 0 {
+			rule.Length += 24 * time.Hour
+		}
+	}
+
+	if rule.Location, err = time.LoadLocation(timezone); err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+	}
+
+	return rule, nil
+}
+
 // HashPassword hashes a password using bcrypt
 func HashPassword(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -73,6 +115,13 @@ func CheckPassword(plainPassword, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
 }
 
+// HashToken returns the hex encoded SHA-256 digest of a token.
+// Refresh tokens are longer than bcrypt's 72 byte limit, so they are stored this way instead.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // GenerateRandomCode generates a random numeric code of specified length
 func GenerateRandomCode(length int) (string, error) {
 	const digits = "0123456789"
@@ -176,3 +225,30 @@ func IsValidUUID(uuid string) bool {
 	uuidRegex := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
 	return uuidRegex.MatchString(uuid)
 }
+
+// ValidateCardNumber reports whether card is a 16-digit card number with a
+// valid Luhn check digit, such as the Uzcard and Humo cards employees are paid
+// to.
+func ValidateCardNumber(card string) bool {
+	if len(card) != 16 {
+		return false
+	}
+
+	var sum int
+	for i := len(card) - 1; i >= 0; i-- {
+		digit := int(card[i] - '0')
+		if digit > 9 {
+			return false
+		}
+		// Double every second digit from the right
+		if (len(card)-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,162 @@
+// Package payslip renders payslips as PDF documents.
+package payslip
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/pkg/pdf"
+)
+
+const (
+	marginLeft  = 56.0
+	marginRight = pdf.PageWidth - 56
+	marginTop   = pdf.PageHeight - 64
+	marginEnd   = 64.0
+	lineHeight  = 16.0
+)
+
+// ContentType is the MIME type of rendered payslips.
+const ContentType = "application/pdf"
+
+// Key returns the object name a salary's payslip is stored under.
+func Key(userID, salaryID int64) string {
+	return fmt.Sprintf("payslips/%d/%d.pdf", userID, salaryID)
+}
+
+// FileName returns the name a salary's payslip is downloaded as.
+func FileName(salary *entity.Salary) string {
+	return fmt.Sprintf("payslip-%s-%d.pdf", salary.PayDate.Format("2006-01"), salary.ID)
+}
+
+// Render lays out p on A4 pages.
+func Render(p *entity.Payslip) []byte {
+	w := &writer{doc: pdf.New()}
+	w.newPage()
+
+	w.page.Text(marginLeft, w.y, 20, true, "Payslip")
+	w.page.TextRight(marginRight, w.y, 10, false, fmt.Sprintf("Salary #%d", p.SalaryID))
+	w.y -= 2 * lineHeight
+
+	w.field("Employee", strings.TrimSpace(p.FirstName+" "+p.LastName))
+	w.field("Employee ID", fmt.Sprintf("%d", p.UserID))
+	w.field("Pay period", fmt.Sprintf("%s to %s", p.PeriodStart.Format(time.DateOnly), p.PeriodEnd.Format(time.DateOnly)))
+	w.field("Pay date", p.PayDate.Format(time.DateOnly))
+	if p.PaidAt != nil {
+		w.field("Paid on", p.PaidAt.UTC().Format(time.DateOnly))
+	}
+	w.field("Currency", string(p.Currency))
+	w.y -= lineHeight
+
+	w.heading("Earnings", p.Currency)
+	w.row("", "Base salary", p.BaseSalary, false)
+	for _, bonus := range p.Bonuses {
+		w.row(bonus.Date.Format(time.DateOnly), bonus.Description, bonus.Amount, false)
+	}
+	w.rule()
+	w.row("", "Total earnings", p.BaseSalary+p.TotalBonuses, true)
+	w.y -= lineHeight
+
+	w.heading("Deductions", p.Currency)
+	if len(p.Deductions) == 0 {
+		w.row("", "None", 0, false)
+	}
+	for _, deduction := range p.Deductions {
+		w.row(deduction.Date.Format(time.DateOnly), deduction.Description, -deduction.Amount, false)
+	}
+	w.rule()
+	w.row("", "Total deductions", -p.TotalDeductions, true)
+	w.y -= lineHeight
+
+	w.rule()
+	w.page.Text(marginLeft, w.y, 13, true, "Net pay")
+	w.page.TextRight(marginRight, w.y, 13, true, formatAmount(p.Net)+" "+string(p.Currency))
+	w.y -= 3 * lineHeight
+
+	w.page.Text(marginLeft, w.y, 8, false, fmt.Sprintf("Generated on %s", time.Now().UTC().Format(time.DateOnly)))
+
+	return w.doc.Bytes()
+}
+
+// writer keeps track of the position on the current page and starts a new
+// page when it runs out of room.
+type writer struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func (w *writer) newPage() {
+	w.page = w.doc.AddPage()
+	w.y = marginTop
+}
+
+func (w *writer) ensureRoom() {
+	if w.y < marginEnd {
+		w.newPage()
+	}
+}
+
+func (w *writer) field(label, value string) {
+	w.ensureRoom()
+	w.page.Text(marginLeft, w.y, 10, true, label)
+	w.page.Text(marginLeft+120, w.y, 10, false, value)
+	w.y -= lineHeight
+}
+
+func (w *writer) heading(title string, currency entity.Currency) {
+	w.ensureRoom()
+	w.page.Text(marginLeft, w.y, 12, true, title)
+	w.page.TextRight(marginRight, w.y, 10, true, string(currency))
+	w.y -= 4
+	w.page.Line(marginLeft, w.y, marginRight, w.y)
+	w.y -= lineHeight
+}
+
+func (w *writer) row(date, description string, amount float64, bold bool) {
+	w.ensureRoom()
+	w.page.Text(marginLeft, w.y, 10, bold, date)
+	w.page.Text(marginLeft+80, w.y, 10, bold, truncate(description, 330, 10))
+	w.page.TextRight(marginRight, w.y, 10, bold, formatAmount(amount))
+	w.y -= lineHeight
+}
+
+func (w *writer) rule() {
+	w.ensureRoom()
+	w.page.Line(marginLeft, w.y+lineHeight-4, marginRight, w.y+lineHeight-4)
+}
+
+// truncate shortens s with an ellipsis until it fits width points at size.
+func truncate(s string, width, size float64) string {
+	if pdf.TextWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// formatAmount formats amount with two decimals and thousands separators.
+func formatAmount(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	whole := fmt.Sprintf("%d", cents/100)
+
+	var b strings.Builder
+	if cents != 0 && amount < 0 {
+		b.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	fmt.Fprintf(&b, ".%02d", cents%100)
+
+	return b.String()
+}
@@ -0,0 +1,263 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	// _migrationsTable is the same single-row table the migrate CLI keeps, so
+	// migrations created with `make migrate-create` can be applied by either.
+	_migrationsTable = "schema_migrations"
+	// _migrationsLockID keys the advisory lock that keeps two instances from
+	// migrating the same database at once.
+	_migrationsLockID = 7346812095
+)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// LoadMigrations reads <version>_<title>.up.sql and .down.sql files from fsys
+// and returns them ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("postgres - LoadMigrations - fs.ReadDir: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		name := entry.Name()
+		versionStr, rest, found := strings.Cut(name, "_")
+		if !found {
+			return nil, fmt.Errorf("postgres - LoadMigrations - invalid migration file name %q", name)
+		}
+
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("postgres - LoadMigrations - invalid migration version in %q: %w", name, err)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("postgres - LoadMigrations - fs.ReadFile %q: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version}
+			byVersion[version] = m
+		}
+
+		switch {
+		case strings.HasSuffix(rest, ".up.sql"):
+			m.Name = strings.TrimSuffix(rest, ".up.sql")
+			m.Up = string(content)
+		case strings.HasSuffix(rest, ".down.sql"):
+			m.Down = string(content)
+		default:
+			return nil, fmt.Errorf("postgres - LoadMigrations - migration %q is neither .up.sql nor .down.sql", name)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator applies migrations to the database. Every migration runs in its
+// own transaction together with the version bump, so a failed migration
+// leaves the schema at the previous version instead of a dirty one.
+type Migrator struct {
+	db         *Postgres
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations from fsys.
+func NewMigrator(db *Postgres, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Version returns the currently applied version, 0 when nothing is applied.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, err
+	}
+
+	return m.version(ctx, m.db.Pool)
+}
+
+// Up applies every migration newer than the current version and returns how
+// many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	var applied int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("postgres - Migrator.Up - version %d: %w", migration.Version, err)
+			}
+			log.Printf("Migration %d_%s applied", migration.Version, migration.Name)
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the given number of applied migrations, newest first, and
+// returns how many were rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	var reverted int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		current, err := m.version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Version > current {
+				continue
+			}
+
+			var previous int64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err := m.apply(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("postgres - Migrator.Down - version %d: %w", migration.Version, err)
+			}
+			log.Printf("Migration %d_%s reverted", migration.Version, migration.Name)
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	_, err := m.db.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+_migrationsTable+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("postgres - Migrator - create %s: %w", _migrationsTable, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) version(ctx context.Context, q querier) (int64, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+
+	err := q.QueryRow(ctx, "SELECT version, dirty FROM "+_migrationsTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("postgres - Migrator - read version: %w", err)
+	}
+	if dirty {
+		return 0, fmt.Errorf("postgres - Migrator - database is dirty at version %d, fix it manually and reset the dirty flag", version)
+	}
+
+	return version, nil
+}
+
+// withLock runs fn while holding the migrations advisory lock. The locked
+// connection is handed to fn so migrating works with a pool of one.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	if err := m.ensureTable(ctx); err != nil {
+		return err
+	}
+
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("postgres - Migrator - acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", _migrationsLockID); err != nil {
+		return fmt.Errorf("postgres - Migrator - acquire lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", _migrationsLockID); err != nil {
+			log.Printf("Migration lock release failed: %v", err)
+		}
+	}()
+
+	return fn(conn)
+}
+
+// apply runs one migration script and records version as the new current one.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, script string, version int64) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// Without arguments pgx sends the script over the simple protocol, which
+	// allows several statements in one call.
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.Exec(ctx, script); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM "+_migrationsTable); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.Exec(ctx, "INSERT INTO "+_migrationsTable+" (version, dirty) VALUES ($1, FALSE)", version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name:     "empty",
+			files:    fstest.MapFS{},
+			versions: []int64{},
+		},
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"20261016100000_shifts.up.sql":       {Data: []byte("CREATE TABLE shifts ();")},
+				"20261016100000_shifts.down.sql":     {Data: []byte("DROP TABLE shifts;")},
+				"20250703092109_.up.sql":             {Data: []byte("CREATE TABLE users ();")},
+				"20250703092109_.down.sql":           {Data: []byte("DROP TABLE users;")},
+				"20261016090000_attendance.up.sql":   {Data: []byte("ALTER TABLE attendances;")},
+				"20261016090000_attendance.down.sql": {Data: []byte("ALTER TABLE attendances;")},
+			},
+			versions: []int64{20250703092109, 20261016090000, 20261016100000},
+		},
+		{
+			name: "other files skipped",
+			files: fstest.MapFS{
+				"20250703092109_init.up.sql":  {Data: []byte("SELECT 1;")},
+				"migrations.go":               {Data: []byte("package migrations")},
+				"README.md":                   {Data: []byte("# migrations")},
+				"old/20240101000000_x.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			versions: []int64{20250703092109},
+		},
+		{
+			name:    "missing version",
+			files:   fstest.MapFS{"init.up.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			files:   fstest.MapFS{"v1_init.up.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: true,
+		},
+		{
+			name:    "no direction",
+			files:   fstest.MapFS{"20250703092109_init.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMigrations error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(migrations) != len(tt.versions) {
+				t.Fatalf("LoadMigrations returned %d migrations, want %d", len(migrations), len(tt.versions))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.versions[i] {
+					t.Errorf("migration %d has version %d, want %d", i, m.Version, tt.versions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadMigrationsPairs(t *testing.T) {
+	migrations, err := LoadMigrations(fstest.MapFS{
+		"20261016100000_shifts.down.sql": {Data: []byte("DROP TABLE shifts;")},
+		"20261016100000_shifts.up.sql":   {Data: []byte("CREATE TABLE shifts ();")},
+	})
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) != 1 {
+		t.Fatalf("LoadMigrations returned %d migrations, want 1", len(migrations))
+	}
+
+	m := migrations[0]
+	if m.Name != "shifts" || m.Up != "CREATE TABLE shifts ();" || m.Down != "DROP TABLE shifts;" {
+		t.Errorf("migration = %+v, want shifts with both directions", m)
+	}
+}
@@ -14,8 +14,8 @@ import (
 	"github.com/ruziba3vich/argus/internal/pkg/config"
 
 	"github.com/Masterminds/squirrel"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"database/sql" // For sql.NullTime
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -19,6 +21,14 @@ const (
 	attendanceIDKey     = "attendanceID: "
 )
 
+// CloseDay leaves an open record alone until autoCloseGrace after its shift
+// end, so shifts running past midnight are not cut short. Records without a
+// known shift end wait maxOpenShift after the check-in instead.
+const (
+	autoCloseGrace = time.Hour
+	maxOpenShift   = 16 * time.Hour
+)
+
 // AttendanceRepoInterface defines the interface for Attendance CRUD operations.
 type AttendanceRepoInterface interface {
 	Create(ctx context.Context, req *entity.CreateAttendanceRequest) (*entity.Attendance, error)
@@ -26,6 +36,11 @@ type AttendanceRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllAttendancesResponse, error)
 	Update(ctx context.Context, req *entity.UpdateAttendanceRequest) error
 	Delete(ctx context.Context, id int64) error
+	CheckIn(ctx context.Context, userID int64, fallback *entity.ShiftRule) (*entity.Attendance, error)
+	CheckOut(ctx context.Context, userID int64) (*entity.Attendance, error)
+	Days(ctx context.Context, userID int64, from, to time.Time) (*entity.AttendanceDaysResponse, error)
+	Report(ctx context.Context, limit, offset uint64, filter map[string]string, from, to time.Time) (*entity.AttendanceReportResponse, error)
+	CloseDay(ctx context.Context, date time.Time) (*entity.CloseDayResponse, error)
 }
 
 type attendanceRepo struct {
@@ -69,7 +84,7 @@ func (p *attendanceRepo) Create(ctx context.Context, req *entity.CreateAttendanc
 		Insert(p.tableName).
 		Columns(columns...).
 		Values(values...).
-		Suffix(`RETURNING id, user_id, date, intime, outtime, status, created_at, updated_at`).
+		Suffix(`RETURNING id, user_id, date, intime, outtime, status, auto_closed, created_at, updated_at`).
 		ToSql()
 	if err != nil {
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
@@ -92,6 +107,7 @@ func (p *attendanceRepo) Create(ctx context.Context, req *entity.CreateAttendanc
 		&createdAttendance.InTime,
 		&nullOutTime, // Scan into NullTime
 		&createdAttendance.Status,
+		&createdAttendance.AutoClosed,
 		&createdAttendance.CreatedAt,
 		&createdAttendance.UpdatedAt,
 	)
@@ -103,6 +119,10 @@ func (p *attendanceRepo) Create(ctx context.Context, req *entity.CreateAttendanc
 		createdAttendance.OutTime = &nullOutTime.Time
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdAttendance.ID, nil); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
@@ -122,7 +142,7 @@ func (p *attendanceRepo) Get(ctx context.Context, params map[string]string) (*en
 	}
 
 	builder := p.db.Sq.Builder.Select(
-		"id", "user_id", "date", "intime", "outtime", "status",
+		"id", "user_id", "date", "intime", "outtime", "status", "auto_closed",
 		"created_at", "updated_at",
 	).From(p.tableName)
 
@@ -145,6 +165,7 @@ func (p *attendanceRepo) Get(ctx context.Context, params map[string]string) (*en
 		&attendance.InTime,
 		&nullOutTime,
 		&attendance.Status,
+		&attendance.AutoClosed,
 		&attendance.CreatedAt,
 		&attendance.UpdatedAt,
 	)
@@ -168,7 +189,7 @@ func (p *attendanceRepo) List(ctx context.Context, limit, offset uint64, filter
 
 	var attendances entity.GetAllAttendancesResponse
 	baseBuilder := p.db.Sq.Builder.Select(
-		"id", "user_id", "date", "intime", "outtime", "status",
+		"id", "user_id", "date", "intime", "outtime", "status", "auto_closed",
 		"created_at", "updated_at",
 	).From(p.tableName)
 
@@ -210,7 +231,7 @@ func (p *attendanceRepo) List(ctx context.Context, limit, offset uint64, filter
 		var nullOutTime sql.NullTime
 		if err := rows.Scan(
 			&attendance.ID, &attendance.UserID, &attendance.Date, &attendance.InTime,
-			&nullOutTime, &attendance.Status, &attendance.CreatedAt, &attendance.UpdatedAt,
+			&nullOutTime, &attendance.Status, &attendance.AutoClosed, &attendance.CreatedAt, &attendance.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
@@ -296,6 +317,11 @@ func (p *attendanceRepo) Update(ctx context.Context, req *entity.UpdateAttendanc
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -305,6 +331,10 @@ func (p *attendanceRepo) Update(ctx context.Context, req *entity.UpdateAttendanc
 		return fmt.Errorf("no attendance record found with ID %d", req.ID)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -331,7 +361,18 @@ func (p *attendanceRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("delete query build error: %w", err)
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
 	}
@@ -340,5 +381,696 @@ func (p *attendanceRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("no attendance record found with ID %d", id)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
 	return nil
 }
+
+// CheckIn opens an attendance record for the user stamped with the server
+// time. The first check-in of a scheduled day after the shift start plus the
+// grace period is marked late. Users without a schedule are measured against
+// the fallback rule.
+func (p *attendanceRepo) CheckIn(ctx context.Context, userID int64, fallback *entity.ShiftRule) (*entity.Attendance, error) {
+	var reqID = attendanceIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("attendanceRepo.CheckIn - %s", reqID))
+	}
+
+	if userID == 0 {
+		return nil, fmt.Errorf("user ID is required for check-in")
+	}
+
+	rules, err := loadShiftRules(ctx, p.db, p.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := fallback
+	if len(rules) > 0 {
+		// Days missing from a schedule are days off, nobody is late on them
+		rule = nil
+		for weekday, r := range rules {
+			if time.Now().In(r.Location).Weekday() == weekday {
+				rule = r
+				break
+			}
+		}
+	}
+
+	location := time.UTC
+	switch {
+	case rule != nil && rule.Location != nil:
+		location = rule.Location
+	case fallback != nil && fallback.Location != nil:
+		location = fallback.Location
+	}
+
+	now := time.Now().In(location)
+	year, month, day := now.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// A record left open on an earlier day blocks the check-in as well, it
+	// has to be checked out (or closed by the nightly job) first
+	countSqlStr, countArgs, err := p.db.Sq.Builder.
+		Select("COUNT(*) FILTER (WHERE outtime IS NULL)").
+		Column(squirrel.Expr("COUNT(*) FILTER (WHERE date = ? AND status <> 'absent')", date)).
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" check-in count")
+	}
+
+	var openCount, dayCount uint64
+	if err := tx.QueryRow(ctx, countSqlStr, countArgs...).Scan(&openCount, &dayCount); err != nil {
+		return nil, p.db.Error(err)
+	}
+	if openCount > 0 {
+		return nil, entity.ErrorAlreadyCheckedIn
+	}
+
+	status := entity.AttendanceStatusPresent
+	if rule != nil && dayCount == 0 {
+		shiftStart := time.Date(year, month, day, 0, 0, 0, 0, location).Add(rule.Start)
+		if now.After(shiftStart.Add(rule.Grace)) {
+			status = entity.AttendanceStatusLate
+		}
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("user_id", "date", "intime", "status", "created_at", "updated_at").
+		Values(userID, date, now.UTC(), status, now.UTC(), now.UTC()).
+		Suffix(`RETURNING id, user_id, date, intime, outtime, status, auto_closed, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" check-in")
+	}
+
+	var attendance entity.Attendance
+	var nullOutTime sql.NullTime
+
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(
+		&attendance.ID,
+		&attendance.UserID,
+		&attendance.Date,
+		&attendance.InTime,
+		&nullOutTime,
+		&attendance.Status,
+		&attendance.AutoClosed,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		// A concurrent check-in won the race for the open record index
+		if errors.Is(p.db.Error(err), entity.ErrorConflict) {
+			return nil, entity.ErrorAlreadyCheckedIn
+		}
+		return nil, p.db.Error(err)
+	}
+
+	if nullOutTime.Valid {
+		attendance.OutTime = &nullOutTime.Time
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, attendance.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &attendance, nil
+}
+
+// CheckOut closes the user's latest open attendance record with the server time.
+func (p *attendanceRepo) CheckOut(ctx context.Context, userID int64) (*entity.Attendance, error) {
+	var reqID = attendanceIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("attendanceRepo.CheckOut - %s", reqID))
+	}
+
+	if userID == 0 {
+		return nil, fmt.Errorf("user ID is required for check-out")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// The latest open record is closed even if it started the previous day,
+	// so shifts that cross midnight can still be checked out.
+	var id int64
+	err = tx.QueryRow(ctx,
+		"SELECT id FROM "+p.tableName+" WHERE user_id = $1 AND outtime IS NULL ORDER BY intime DESC LIMIT 1 FOR UPDATE",
+		userID,
+	).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrorNotCheckedIn
+		}
+		return nil, p.db.Error(err)
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("outtime", now).
+		Set("updated_at", now).
+		Where(squirrel.Eq{"id": id}).
+		Suffix(`RETURNING id, user_id, date, intime, outtime, status, auto_closed, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" check-out")
+	}
+
+	var attendance entity.Attendance
+	var nullOutTime sql.NullTime
+
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(
+		&attendance.ID,
+		&attendance.UserID,
+		&attendance.Date,
+		&attendance.InTime,
+		&nullOutTime,
+		&attendance.Status,
+		&attendance.AutoClosed,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if nullOutTime.Valid {
+		attendance.OutTime = &nullOutTime.Time
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, id, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &attendance, nil
+}
+
+// Days classifies every day from from to to (inclusive) as present, late or
+// absent against the user's schedule and compares worked with expected hours.
+// A scheduled day without a check-in only counts as absent once its shift is
+// over, and days of approved leave are neither expected nor absent.
+func (p *attendanceRepo) Days(ctx context.Context, userID int64, from, to time.Time) (*entity.AttendanceDaysResponse, error) {
+	var reqID = attendanceIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("attendanceRepo.Days - %s", reqID))
+	}
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		return nil, fmt.Errorf("date range end is before its start")
+	}
+
+	rules, err := loadShiftRules(ctx, p.db, p.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := loadApprovedLeave(ctx, p.db, p.db, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("date", "intime", "outtime", "status").
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.GtOrEq{"date": from}).
+		Where(squirrel.LtOrEq{"date": to}).
+		OrderBy("date", "intime").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" days")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	records := make(map[string][]*entity.Attendance)
+	for rows.Next() {
+		var attendance entity.Attendance
+		var nullOutTime sql.NullTime
+		if err := rows.Scan(&attendance.Date, &attendance.InTime, &nullOutTime, &attendance.Status); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if nullOutTime.Valid {
+			attendance.OutTime = &nullOutTime.Time
+		}
+
+		key := attendance.Date.Format(time.DateOnly)
+		records[key] = append(records[key], &attendance)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	response := entity.AttendanceDaysResponse{UserID: userID, From: from, To: to}
+	now := time.Now()
+
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := entity.AttendanceDay{Date: date}
+		rule, scheduled := rules[date.Weekday()]
+		day.Scheduled = scheduled
+
+		var worked time.Duration
+		dayRecords := records[date.Format(time.DateOnly)]
+		for _, record := range dayRecords {
+			if record.OutTime != nil {
+				worked += record.OutTime.Sub(record.InTime)
+			}
+		}
+		day.WorkedHours = hours(worked)
+
+		for _, record := range dayRecords {
+			if record.Status == entity.AttendanceStatusAbsent {
+				continue
+			}
+			firstIn := record.InTime.UTC()
+			if scheduled {
+				firstIn = firstIn.In(rule.Location)
+			}
+			day.FirstIn = &firstIn
+			break
+		}
+
+		// Nobody is expected to work on approved leave
+		if leaveType, onLeave := leaves[date.Format(time.DateOnly)]; onLeave {
+			day.Leave = &leaveType
+		} else if scheduled {
+			day.ExpectedHours = hours(rule.Length)
+		}
+
+		switch {
+		case day.Leave != nil && !workedOn(dayRecords):
+			// Leave days without work have no attendance status
+		case len(dayRecords) > 0:
+			// An absence marker only stands when the user never checked in that day
+			status := dayRecords[0].Status
+			for _, record := range dayRecords {
+				if record.Status != entity.AttendanceStatusAbsent {
+					status = record.Status
+					break
+				}
+			}
+			day.Status = &status
+		case scheduled:
+			shiftEnd := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, rule.Location).Add(rule.Start + rule.Length)
+			if now.After(shiftEnd) {
+				status := entity.AttendanceStatusAbsent
+				day.Status = &status
+			}
+		}
+
+		response.ExpectedHours += day.ExpectedHours
+		response.WorkedHours += day.WorkedHours
+		response.Items = append(response.Items, &day)
+	}
+
+	response.ExpectedHours = math.Round(response.ExpectedHours*100) / 100
+	response.WorkedHours = math.Round(response.WorkedHours*100) / 100
+
+	return &response, nil
+}
+
+// Report summarises the days from from to to (inclusive) of every user
+// matching filter, one page of users at a time.
+func (p *attendanceRepo) Report(ctx context.Context, limit, offset uint64, filter map[string]string, from, to time.Time) (*entity.AttendanceReportResponse, error) {
+	var reqID = attendanceIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("attendanceRepo.Report - %s", reqID))
+	}
+
+	baseBuilder := p.db.Sq.Builder.Select("id", "first_name", "COALESCE(last_name, '')").From(userTableName).Where(notDeleted)
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(userTableName).Where(notDeleted)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := baseBuilder.OrderBy("id").Limit(limit).Offset(offset).ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, userTableName+" report")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var summaries []*entity.AttendanceSummary
+	for rows.Next() {
+		var summary entity.AttendanceSummary
+		if err := rows.Scan(&summary.UserID, &summary.FirstName, &summary.LastName); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		summaries = append(summaries, &summary)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	report := entity.AttendanceReportResponse{From: dateOnly(from), To: dateOnly(to), Items: summaries}
+
+	for _, summary := range summaries {
+		days, err := p.Days(ctx, summary.UserID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		summarizeDays(summary, days)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, userTableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&report.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &report, nil
+}
+
+// summarizeDays folds classified days into summary. Overtime is work beyond
+// the expected hours of each day, so all work on a day off is overtime.
+func summarizeDays(summary *entity.AttendanceSummary, days *entity.AttendanceDaysResponse) {
+	var (
+		arrivalMinutes int
+		arrivals       int
+		overtime       float64
+	)
+
+	for _, day := range days.Items {
+		if day.Scheduled {
+			summary.ScheduledDays++
+		}
+		if day.Leave != nil {
+			summary.LeaveDays++
+		}
+		if day.Status != nil {
+			switch *day.Status {
+			case entity.AttendanceStatusPresent:
+				summary.PresentDays++
+			case entity.AttendanceStatusLate:
+				summary.PresentDays++
+				summary.LateDays++
+			case entity.AttendanceStatusAbsent:
+				summary.AbsentDays++
+			}
+		}
+		if day.FirstIn != nil {
+			arrivalMinutes += day.FirstIn.Hour()*60 + day.FirstIn.Minute()
+			arrivals++
+		}
+		if day.WorkedHours > day.ExpectedHours {
+			overtime += day.WorkedHours - day.ExpectedHours
+		}
+	}
+
+	summary.WorkedHours = days.WorkedHours
+	summary.ExpectedHours = days.ExpectedHours
+	summary.OvertimeHours = math.Round(overtime*100) / 100
+
+	if arrivals > 0 {
+		average := arrivalMinutes / arrivals
+		arrival := fmt.Sprintf("%02d:%02d", average/60, average%60)
+		summary.AverageArrival = &arrival
+	}
+}
+
+// workedOn tells whether any record of a day is more than an absence marker.
+func workedOn(records []*entity.Attendance) bool {
+	for _, record := range records {
+		if record.Status != entity.AttendanceStatusAbsent {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hours converts a duration to hours rounded to two decimals.
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
+
+// CloseDay finishes a working day: open records dated on or before it whose
+// shift is over are closed at their shift end (or their check-in time when
+// the shift is unknown) and flagged auto_closed, and every user scheduled
+// that weekday without a record or approved leave gets an absent one. Records
+// of shifts still running are left for a later run. Running it twice for a
+// day is a no-op.
+func (p *attendanceRepo) CloseDay(ctx context.Context, date time.Time) (*entity.CloseDayResponse, error) {
+	var reqID = attendanceIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("attendanceRepo.CloseDay - %s", reqID))
+	}
+
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	response := entity.CloseDayResponse{Date: date}
+	now := time.Now().UTC()
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	openSqlStr, openArgs, err := p.db.Sq.Builder.
+		Select("id", "user_id", "date", "intime").
+		From(p.tableName).
+		Where(squirrel.Eq{"outtime": nil}).
+		Where(squirrel.LtOrEq{"date": date}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" open records")
+	}
+
+	rows, err := tx.Query(ctx, openSqlStr, openArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var open []*entity.Attendance
+	for rows.Next() {
+		var attendance entity.Attendance
+		if err := rows.Scan(&attendance.ID, &attendance.UserID, &attendance.Date, &attendance.InTime); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		open = append(open, &attendance)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	schedules := make(map[int64]map[time.Weekday]*entity.ShiftRule)
+	scheduleOf := func(userID int64) (map[time.Weekday]*entity.ShiftRule, error) {
+		if rules, ok := schedules[userID]; ok {
+			return rules, nil
+		}
+		rules, err := loadShiftRules(ctx, p.db, tx, userID)
+		if err != nil {
+			return nil, err
+		}
+		schedules[userID] = rules
+		return rules, nil
+	}
+
+	for _, record := range open {
+		rules, err := scheduleOf(record.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Never invent working time: without a known shift end the record is
+		// closed at its own check-in time.
+		closeAt := record.InTime
+		deadline := record.InTime.Add(maxOpenShift)
+		if rule, ok := rules[record.Date.Weekday()]; ok && rule.Length > 0 {
+			shiftEnd := time.Date(record.Date.Year(), record.Date.Month(), record.Date.Day(), 0, 0, 0, 0, rule.Location).
+				Add(rule.Start + rule.Length)
+			if shiftEnd.After(closeAt) {
+				closeAt = shiftEnd
+			}
+			deadline = closeAt.Add(autoCloseGrace)
+		}
+		if deadline.After(now) {
+			continue
+		}
+
+		before, err := snapshot(ctx, p.db, tx, p.tableName, record.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Update(p.tableName).
+			Set("outtime", closeAt.UTC()).
+			Set("auto_closed", true).
+			Set("updated_at", now).
+			Where(squirrel.Eq{"id": record.ID}).
+			Suffix(`RETURNING id, user_id, date, intime, outtime, status, auto_closed, created_at, updated_at`).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableName+" auto close")
+		}
+
+		closed, err := scanAttendance(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, closed.ID, before); err != nil {
+			return nil, err
+		}
+		response.AutoClosed = append(response.AutoClosed, closed)
+	}
+
+	missingSqlStr, missingArgs, err := p.db.Sq.Builder.
+		Select("s.user_id").
+		From(shiftsTableName + " s").
+		Where(squirrel.Eq{"s.weekday": int(date.Weekday())}).
+		Where("s.user_id IN (SELECT id FROM " + userTableName + " WHERE deleted_at IS NULL)").
+		Where(squirrel.Expr("NOT EXISTS (SELECT 1 FROM "+p.tableName+" a WHERE a.user_id = s.user_id AND a.date = ?)", date)).
+		Where(squirrel.Expr(
+			"NOT EXISTS (SELECT 1 FROM "+leavesTableName+" l WHERE l.user_id = s.user_id AND l.status = ? AND ? BETWEEN l.start_date AND l.end_date)",
+			entity.LeaveStatusApproved, date,
+		)).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" missing check-ins")
+	}
+
+	rows, err = tx.Query(ctx, missingSqlStr, missingArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var missing []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		missing = append(missing, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, userID := range missing {
+		rules, err := scheduleOf(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Absence markers start and end at the shift start, so they count no
+		// working time and never look like an open record.
+		markAt := date
+		if rule, ok := rules[date.Weekday()]; ok {
+			markAt = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, rule.Location).Add(rule.Start)
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Insert(p.tableName).
+			Columns("user_id", "date", "intime", "outtime", "status", "created_at", "updated_at").
+			Values(userID, date, markAt.UTC(), markAt.UTC(), entity.AttendanceStatusAbsent, now, now).
+			Suffix(`RETURNING id, user_id, date, intime, outtime, status, auto_closed, created_at, updated_at`).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableName+" absent")
+		}
+
+		absent, err := scanAttendance(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, absent.ID, nil); err != nil {
+			return nil, err
+		}
+		response.Absent = append(response.Absent, absent)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &response, nil
+}
+
+// scanAttendance reads a row selected or returned with the full attendance column list.
+func scanAttendance(row pgx.Row) (*entity.Attendance, error) {
+	var attendance entity.Attendance
+	var nullOutTime sql.NullTime
+
+	err := row.Scan(
+		&attendance.ID,
+		&attendance.UserID,
+		&attendance.Date,
+		&attendance.InTime,
+		&nullOutTime,
+		&attendance.Status,
+		&attendance.AutoClosed,
+		&attendance.CreatedAt,
+		&attendance.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullOutTime.Valid {
+		attendance.OutTime = &nullOutTime.Time
+	}
+
+	return &attendance, nil
+}
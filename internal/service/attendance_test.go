@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+)
+
+// createTestShift schedules userID on weekday from start to end, both
+// HH:MM in UTC.
+func createTestShift(t *testing.T, db *postgres.Postgres, userID int64, weekday time.Weekday, start, end string, graceMinutes int) {
+	t.Helper()
+
+	testExec(t, db, "INSERT INTO "+shiftsTableName+" (user_id, weekday, start_time, end_time, grace_minutes) VALUES ($1, $2, $3, $4, $5)",
+		userID, int(weekday), start, end, graceMinutes)
+}
+
+func TestCheckInLate(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	sinceMidnight := now.Sub(today)
+	todayWeekday, tomorrowWeekday := now.Weekday(), (now.Weekday()+1)%7
+
+	fallback := func(start, grace time.Duration) *entity.ShiftRule {
+		return &entity.ShiftRule{Start: start, Length: 8 * time.Hour, Grace: grace, Location: time.UTC}
+	}
+
+	tests := []struct {
+		name     string
+		fallback *entity.ShiftRule
+		shiftDay *time.Weekday // Schedules the user on that day from midnight
+		earlier  string        // "closed" or "open" record of today before the check-in
+		want     entity.AttendanceStatus
+		wantErr  error
+	}{
+		{name: "no rule", want: entity.AttendanceStatusPresent},
+		{name: "after the grace period", fallback: fallback(sinceMidnight-2*time.Hour, 30*time.Minute), want: entity.AttendanceStatusLate},
+		{name: "within the grace period", fallback: fallback(sinceMidnight-10*time.Minute, 30*time.Minute), want: entity.AttendanceStatusPresent},
+		{name: "before the shift", fallback: fallback(sinceMidnight+time.Hour, 0), want: entity.AttendanceStatusPresent},
+		{
+			name:     "second check-in of the day",
+			fallback: fallback(sinceMidnight-2*time.Hour, 0),
+			earlier:  "closed",
+			want:     entity.AttendanceStatusPresent,
+		},
+		{
+			name:     "scheduled day",
+			fallback: fallback(sinceMidnight+time.Hour, 0),
+			shiftDay: &todayWeekday,
+			want:     entity.AttendanceStatusLate,
+		},
+		{
+			name:     "day off in the schedule",
+			fallback: fallback(sinceMidnight-2*time.Hour, 0),
+			shiftDay: &tomorrowWeekday,
+			want:     entity.AttendanceStatusPresent,
+		},
+		{
+			name:    "still checked in",
+			earlier: "open",
+			wantErr: entity.ErrorAlreadyCheckedIn,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			repo := NewAttendanceRepo(db, testLogger(t))
+
+			userID := createTestUser(t, db, "employee", entity.UserRoleUser)
+			if tt.shiftDay != nil {
+				createTestShift(t, db, userID, *tt.shiftDay, "00:00", "23:59", 0)
+			}
+			switch tt.earlier {
+			case "closed":
+				testExec(t, db, "INSERT INTO "+attendanceTableName+" (user_id, date, intime, outtime, status) VALUES ($1, $2, $3, $3, 'late')",
+					userID, today, now.Add(-time.Minute))
+			case "open":
+				testExec(t, db, "INSERT INTO "+attendanceTableName+" (user_id, date, intime, status) VALUES ($1, $2, $3, 'present')",
+					userID, today, now.Add(-time.Minute))
+			}
+
+			attendance, err := repo.CheckIn(context.Background(), userID, tt.fallback)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CheckIn error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if attendance.Status != tt.want {
+				t.Errorf("status = %s, want %s", attendance.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestCloseDay(t *testing.T) {
+	db := testDB(t)
+	repo := NewAttendanceRepo(db, testLogger(t))
+	ctx := context.Background()
+
+	// A Monday well in the past, so every shift of it is over
+	monday := september(7)
+	at := func(day time.Time, hour, minute int) *time.Time {
+		stamp := day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+		return &stamp
+	}
+
+	tests := []struct {
+		name    string
+		shift   bool       // Scheduled on Mondays from 09:00 to 17:00
+		inTime  *time.Time // Check-in of the day, nil when there is none
+		outTime *time.Time
+		leave   bool
+		want    string // "closed", "absent" or "" for untouched
+		closeAt time.Time
+	}{
+		{name: "open with a shift", shift: true, inTime: at(monday, 9, 5), want: "closed", closeAt: *at(monday, 17, 0)},
+		{name: "open after the shift end", shift: true, inTime: at(monday, 18, 30), want: "closed", closeAt: *at(monday, 18, 30)},
+		{name: "open without a shift", inTime: at(monday, 10, 0), want: "closed", closeAt: *at(monday, 10, 0)},
+		{name: "open from an earlier day", inTime: at(monday.AddDate(0, 0, -3), 10, 0), want: "closed", closeAt: *at(monday.AddDate(0, 0, -3), 10, 0)},
+		{name: "open on a later day", shift: true, inTime: at(monday.AddDate(0, 0, 1), 9, 0), want: "absent"},
+		{name: "checked out", shift: true, inTime: at(monday, 9, 0), outTime: at(monday, 17, 0)},
+		{name: "no check-in", shift: true, want: "absent"},
+		{name: "no check-in on leave", shift: true, leave: true},
+		{name: "no check-in without a shift"},
+	}
+
+	users := make(map[int64]int)
+	for i, tt := range tests {
+		userID := createTestUser(t, db, "employee"+string(rune('a'+i)), entity.UserRoleUser)
+		users[userID] = i
+
+		if tt.shift {
+			createTestShift(t, db, userID, time.Monday, "09:00", "17:00", 0)
+		}
+		if tt.inTime != nil {
+			day := time.Date(tt.inTime.Year(), tt.inTime.Month(), tt.inTime.Day(), 0, 0, 0, 0, time.UTC)
+			testExec(t, db, "INSERT INTO "+attendanceTableName+" (user_id, date, intime, outtime, status) VALUES ($1, $2, $3, $4, 'present')",
+				userID, day, *tt.inTime, tt.outTime)
+		}
+		if tt.leave {
+			testExec(t, db, "INSERT INTO "+leavesTableName+" (user_id, type, start_date, end_date, days, status) VALUES ($1, 'vacation', $2, $2, 1, 'approved')",
+				userID, monday)
+		}
+	}
+
+	response, err := repo.CloseDay(ctx, monday)
+	if err != nil {
+		t.Fatalf("CloseDay: %v", err)
+	}
+
+	got := make(map[int]string)
+	for _, attendance := range response.AutoClosed {
+		i := users[attendance.UserID]
+		got[i] = "closed"
+		if !attendance.AutoClosed || attendance.OutTime == nil || !attendance.OutTime.Equal(tests[i].closeAt) {
+			t.Errorf("%s: closed at %v (auto %v), want %v", tests[i].name, attendance.OutTime, attendance.AutoClosed, tests[i].closeAt)
+		}
+	}
+	for _, attendance := range response.Absent {
+		i := users[attendance.UserID]
+		got[i] = "absent"
+		if attendance.Status != entity.AttendanceStatusAbsent || !attendance.Date.Equal(monday) {
+			t.Errorf("%s: absent record %+v, want absent on %s", tests[i].name, attendance, monday.Format(time.DateOnly))
+		}
+	}
+	for i, tt := range tests {
+		if got[i] != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got[i], tt.want)
+		}
+	}
+
+	again, err := repo.CloseDay(ctx, monday)
+	if err != nil {
+		t.Fatalf("second CloseDay: %v", err)
+	}
+	if len(again.AutoClosed) != 0 || len(again.Absent) != 0 {
+		t.Errorf("second CloseDay closed %d and marked %d absent, want nothing", len(again.AutoClosed), len(again.Absent))
+	}
+}
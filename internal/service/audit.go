@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	authmiddleware "github.com/ruziba3vich/argus/api/middleware"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	auditLogTableName = "audit_logs"
+	auditLogIDKey     = "auditLogID: "
+)
+
+var auditLogColumns = []string{
+	"id", "actor_id", "action", "entity", "entity_id", "before", "after", "created_at",
+}
+
+// auditKeys holds the key column of the audited tables that are not keyed by
+// id.
+var auditKeys = map[string]string{
+	currencyTableName:   "code",
+	payoutCardTableName: "user_id",
+}
+
+// auditRedacted holds the columns of the audited tables that must never make
+// it into the audit trail.
+var auditRedacted = map[string][]string{
+	userTableName:       {"hashed_password", "hashed_refresh_token"},
+	payoutCardTableName: {"card"},
+}
+
+// AuditRepoInterface reads the audit trail written by the other repositories.
+type AuditRepoInterface interface {
+	Get(ctx context.Context, id int64) (*entity.AuditLog, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string, from, to *time.Time) (*entity.GetAllAuditLogsResponse, error)
+}
+
+type auditRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewAuditRepo(db *postgres.Postgres, log *logger.Logger) AuditRepoInterface {
+	return &auditRepo{
+		tableName: auditLogTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+func (p *auditRepo) Get(ctx context.Context, id int64) (*entity.AuditLog, error) {
+	var reqID = auditLogIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("auditRepo.Get - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(auditLogColumns...).
+		From(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	auditLog, err := scanAuditLog(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no audit log found with ID %d", id)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	return auditLog, nil
+}
+
+// List returns the audit trail, newest first. from and to bound the day the
+// changes were made, both inclusive.
+func (p *auditRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, from, to *time.Time) (*entity.GetAllAuditLogsResponse, error) {
+	var reqID = auditLogIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("auditRepo.List - %s", reqID))
+	}
+
+	var auditLogs entity.GetAllAuditLogsResponse
+	baseBuilder := p.db.Sq.Builder.Select(auditLogColumns...).From(p.tableName)
+
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+	if from != nil {
+		baseBuilder = baseBuilder.Where(squirrel.GtOrEq{"created_at": dateOnly(*from)})
+		countBuilder = countBuilder.Where(squirrel.GtOrEq{"created_at": dateOnly(*from)})
+	}
+	if to != nil {
+		baseBuilder = baseBuilder.Where(squirrel.Lt{"created_at": dateOnly(*to).AddDate(0, 0, 1)})
+		countBuilder = countBuilder.Where(squirrel.Lt{"created_at": dateOnly(*to).AddDate(0, 0, 1)})
+	}
+
+	baseBuilder = baseBuilder.OrderBy("created_at DESC", "id DESC").Limit(limit).Offset(offset)
+
+	sqlStr, args, err := baseBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	for rows.Next() {
+		auditLog, err := scanAuditLog(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		auditLogs.Items = append(auditLogs.Items, auditLog)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&auditLogs.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &auditLogs, nil
+}
+
+// auditQuerier is satisfied by both the pool and a transaction. Repositories
+// pass the transaction of the change so it is recorded atomically with it.
+type auditQuerier interface {
+	rowQuerier
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// snapshot returns the row of table with the given key as JSON, or nil when
+// there is none. The row is locked so it cannot change between the snapshot
+// and the change being audited. Redacted columns are left out.
+func snapshot(ctx context.Context, db *postgres.Postgres, q rowQuerier, table string, key any) ([]byte, error) {
+	keyColumn, ok := auditKeys[table]
+	if !ok {
+		keyColumn = "id"
+	}
+
+	row := "to_jsonb(t)"
+	for _, column := range auditRedacted[table] {
+		row += " - '" + column + "'"
+	}
+
+	sqlStr, args, err := db.Sq.Builder.
+		Select(row).
+		From(table + " t").
+		Where(squirrel.Eq{"t." + keyColumn: key}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, table+" snapshot")
+	}
+
+	var data []byte
+	if err := q.QueryRow(ctx, sqlStr, args...).Scan(&data); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, db.Error(err)
+	}
+
+	return data, nil
+}
+
+// recordAudit writes action on the row of table with the given key to the
+// audit trail, along with the row as it was before and as it is now. It runs
+// on q, which should be the transaction that made the change.
+func recordAudit(ctx context.Context, db *postgres.Postgres, q auditQuerier, action entity.AuditAction, table string, key any, before []byte) error {
+	after, err := snapshot(ctx, db, q, table, key)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{"action", "entity", "entity_id", "created_at"}
+	values := []interface{}{action, table, fmt.Sprint(key), time.Now().UTC()}
+	if actorID := auditActor(ctx); actorID != 0 {
+		columns = append(columns, "actor_id")
+		values = append(values, actorID)
+	}
+	if before != nil {
+		columns = append(columns, "before")
+		values = append(values, before)
+	}
+	if after != nil {
+		columns = append(columns, "after")
+		values = append(values, after)
+	}
+
+	sqlStr, args, err := db.Sq.Builder.
+		Insert(auditLogTableName).
+		Columns(columns...).
+		Values(values...).
+		ToSql()
+	if err != nil {
+		return db.ErrSQLBuild(err, auditLogTableName+" create")
+	}
+
+	if _, err := q.Exec(ctx, sqlStr, args...); err != nil {
+		return fmt.Errorf("audit log: %w", db.Error(err))
+	}
+
+	return nil
+}
+
+// auditActor returns the user ID from the sub claim of the access token the
+// request was made with, or 0 outside of a request.
+func auditActor(ctx context.Context) int64 {
+	claims, ok := ctx.Value(authmiddleware.RequestAuthKey).(map[string]string)
+	if !ok {
+		if claims, ok = ctx.Value(authmiddleware.RequestAuthCtx).(map[string]string); !ok {
+			return 0
+		}
+	}
+
+	actorID, err := strconv.ParseInt(claims["sub"], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return actorID
+}
+
+func scanAuditLog(row pgx.Row) (*entity.AuditLog, error) {
+	var auditLog entity.AuditLog
+	var nullActorID sql.NullInt64
+
+	err := row.Scan(
+		&auditLog.ID,
+		&nullActorID,
+		&auditLog.Action,
+		&auditLog.Entity,
+		&auditLog.EntityID,
+		&auditLog.Before,
+		&auditLog.After,
+		&auditLog.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullActorID.Valid {
+		auditLog.ActorID = &nullActorID.Int64
+	}
+
+	return &auditLog, nil
+}
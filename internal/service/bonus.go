@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -19,6 +21,19 @@ const (
 	bonusesIDKey     = "bonusID: "
 )
 
+var bonusColumns = []string{
+	"id", "superadminid", "user_id", "amount", "currency", "reason",
+	"status", "proposed_by", "rule_id", "reviewed_at", "review_comment",
+	"payout_status", "payout_transaction_id", "payroll_run_id", "deleted_at", "created_at", "updated_at",
+}
+
+// bonusPaidOut matches the bonuses that were paid out on their own or by a
+// payroll run, which are kept as the record of the money sent.
+var bonusPaidOut = squirrel.Or{
+	squirrel.NotEq{"payroll_run_id": nil},
+	squirrel.Eq{"payout_status": []entity.PayoutStatus{entity.PayoutStatusProcessing, entity.PayoutStatusSucceeded}},
+}
+
 // BonusesRepoInterface defines the interface for Bonus CRUD operations.
 type BonusesRepoInterface interface {
 	Create(ctx context.Context, req *entity.CreateBonusRequest) (*entity.Bonus, error)
@@ -26,6 +41,10 @@ type BonusesRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllBonusesResponse, error)
 	Update(ctx context.Context, req *entity.UpdateBonusRequest) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Review(ctx context.Context, req *entity.ReviewBonusRequest) (*entity.Bonus, error)
+	Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error)
 }
 
 type bonusesRepo struct {
@@ -42,6 +61,7 @@ func NewBonusesRepo(db *postgres.Postgres, log *logger.Logger) BonusesRepoInterf
 	}
 }
 
+// Create proposes a bonus. It stays pending until a super admin reviews it.
 func (p *bonusesRepo) Create(ctx context.Context, req *entity.CreateBonusRequest) (*entity.Bonus, error) {
 	var reqID = bonusesIDKey
 	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
@@ -52,18 +72,18 @@ func (p *bonusesRepo) Create(ctx context.Context, req *entity.CreateBonusRequest
 	// Define columns and values for insertion.
 	// Always include non-nullable fields.
 	columns := []string{
-		"user_id", "amount", "currency",
+		"user_id", "amount", "currency", "status",
 		"created_at", "updated_at",
 	}
 	values := []interface{}{
-		req.UserID, req.Amount, req.Currency,
+		req.UserID, req.Amount, req.Currency, entity.BonusStatusPending,
 		time.Now().UTC(), time.Now().UTC(),
 	}
 
 	// Conditionally add nullable fields
-	if req.SuperAdminID != nil {
-		columns = append(columns, "superadminid") // Database column name
-		values = append(values, *req.SuperAdminID)
+	if req.ProposedBy != 0 {
+		columns = append(columns, "proposed_by")
+		values = append(values, req.ProposedBy)
 	}
 	if req.Reason != nil {
 		columns = append(columns, "reason")
@@ -74,53 +94,32 @@ func (p *bonusesRepo) Create(ctx context.Context, req *entity.CreateBonusRequest
 		Insert(p.tableName).
 		Columns(columns...).
 		Values(values...).
-		Suffix(`RETURNING id, superadminid, user_id, amount, currency, reason, created_at, updated_at`).
+		Suffix("RETURNING " + strings.Join(bonusColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
 	}
 
-	var createdBonus entity.Bonus
-	// Use sql.Null* types for scanning nullable columns
-	var (
-		nullSuperAdminID sql.NullInt64
-		nullReason       sql.NullString
-	)
-
 	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("transaction start failed: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx, sqlStr, args...)
-	err = row.Scan(
-		&createdBonus.ID,
-		&nullSuperAdminID,
-		&createdBonus.UserID,
-		&createdBonus.Amount,
-		&createdBonus.Currency,
-		&nullReason,
-		&createdBonus.CreatedAt,
-		&createdBonus.UpdatedAt,
-	)
+	createdBonus, err := scanBonus(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	// Assign scanned null values to pointers in the entity struct
-	if nullSuperAdminID.Valid {
-		createdBonus.SuperAdminID = &nullSuperAdminID.Int64
-	}
-	if nullReason.Valid {
-		createdBonus.Reason = &nullReason.String
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdBonus.ID, nil); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 
-	return &createdBonus, nil
+	return createdBonus, nil
 }
 
 func (p *bonusesRepo) Get(ctx context.Context, params map[string]string) (*entity.Bonus, error) {
@@ -134,10 +133,7 @@ func (p *bonusesRepo) Get(ctx context.Context, params map[string]string) (*entit
 		return nil, fmt.Errorf("at least one filter parameter is required")
 	}
 
-	builder := p.db.Sq.Builder.Select(
-		"id", "superadminid", "user_id", "amount", "currency", "reason",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	builder := p.db.Sq.Builder.Select(bonusColumns...).From(p.tableName).Where(notDeleted)
 
 	for key, value := range params {
 		builder = builder.Where(squirrel.Eq{key: value})
@@ -148,34 +144,12 @@ func (p *bonusesRepo) Get(ctx context.Context, params map[string]string) (*entit
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
 	}
 
-	var bonus entity.Bonus
-	var (
-		nullSuperAdminID sql.NullInt64
-		nullReason       sql.NullString
-	)
-
-	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
-		&bonus.ID,
-		&nullSuperAdminID,
-		&bonus.UserID,
-		&bonus.Amount,
-		&bonus.Currency,
-		&nullReason,
-		&bonus.CreatedAt,
-		&bonus.UpdatedAt,
-	)
+	bonus, err := scanBonus(p.db.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullSuperAdminID.Valid {
-		bonus.SuperAdminID = &nullSuperAdminID.Int64
-	}
-	if nullReason.Valid {
-		bonus.Reason = &nullReason.String
-	}
-
-	return &bonus, nil
+	return bonus, nil
 }
 
 func (p *bonusesRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllBonusesResponse, error) {
@@ -186,13 +160,14 @@ func (p *bonusesRepo) List(ctx context.Context, limit, offset uint64, filter map
 	}
 
 	var bonuses entity.GetAllBonusesResponse
-	baseBuilder := p.db.Sq.Builder.Select(
-		"id", "superadminid", "user_id", "amount", "currency", "reason",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	baseBuilder := p.db.Sq.Builder.Select(bonusColumns...).From(p.tableName)
 
 	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
 
+	trash := deletedFilter(filter)
+	baseBuilder = baseBuilder.Where(trash)
+	countBuilder = countBuilder.Where(trash)
+
 	// Apply filters
 	for key, value := range filter {
 		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
@@ -226,25 +201,11 @@ func (p *bonusesRepo) List(ctx context.Context, limit, offset uint64, filter map
 	defer rows.Close()
 
 	for rows.Next() {
-		var bonus entity.Bonus
-		var (
-			nullSuperAdminID sql.NullInt64
-			nullReason       sql.NullString
-		)
-
-		if err := rows.Scan(
-			&bonus.ID, &nullSuperAdminID, &bonus.UserID, &bonus.Amount,
-			&bonus.Currency, &nullReason, &bonus.CreatedAt, &bonus.UpdatedAt,
-		); err != nil {
+		bonus, err := scanBonus(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
-		if nullSuperAdminID.Valid {
-			bonus.SuperAdminID = &nullSuperAdminID.Int64
-		}
-		if nullReason.Valid {
-			bonus.Reason = &nullReason.String
-		}
-		bonuses.Items = append(bonuses.Items, &bonus)
+		bonuses.Items = append(bonuses.Items, bonus)
 	}
 
 	// Get total count
@@ -263,6 +224,8 @@ func (p *bonusesRepo) List(ctx context.Context, limit, offset uint64, filter map
 	return &bonuses, nil
 }
 
+// Update changes a pending bonus. Reviewed bonuses are final and fail with
+// entity.ErrorBonusNotPending.
 func (p *bonusesRepo) Update(ctx context.Context, req *entity.UpdateBonusRequest) error {
 	var reqID = bonusesIDKey
 	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
@@ -279,9 +242,6 @@ func (p *bonusesRepo) Update(ctx context.Context, req *entity.UpdateBonusRequest
 
 	clauses := map[string]interface{}{"updated_at": time.Now().UTC()}
 
-	if req.SuperAdminID != nil {
-		clauses["superadminid"] = *req.SuperAdminID
-	}
 	if req.UserID != nil {
 		clauses["user_id"] = *req.UserID
 	}
@@ -303,6 +263,7 @@ func (p *bonusesRepo) Update(ctx context.Context, req *entity.UpdateBonusRequest
 		Update(p.tableName).
 		SetMap(clauses).
 		Where(p.db.Sq.Equal("id", req.ID)).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return p.db.ErrSQLBuild(err, p.tableName+" update")
@@ -314,13 +275,25 @@ func (p *bonusesRepo) Update(ctx context.Context, req *entity.UpdateBonusRequest
 	}
 	defer tx.Rollback(ctx)
 
-	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	bonus, err := p.lockBonus(ctx, tx, req.ID)
+	if err != nil {
+		return err
+	}
+	if bonus.Status != entity.BonusStatusPending {
+		return entity.ErrorBonusNotPending
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
 	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sqlStr, args...); err != nil {
 		return p.db.Error(err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("no bonus found with ID %d", req.ID)
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -341,22 +314,272 @@ func (p *bonusesRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("bonus ID is required for deletion")
 	}
 
+	deleted, err := softDelete(ctx, p.db, p.tableName, id, bonusPaidOut)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no bonus found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Restore takes a deleted bonus out of the trash.
+func (p *bonusesRepo) Restore(ctx context.Context, id int64) error {
+	var reqID = bonusesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusesRepo.Restore - %s", reqID))
+	}
+
+	restored, err := restoreDeleted(ctx, p.db, p.tableName, id)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return fmt.Errorf("no deleted bonus found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Purge removes a deleted bonus for good.
+func (p *bonusesRepo) Purge(ctx context.Context, id int64) error {
+	var reqID = bonusesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusesRepo.Purge - %s", reqID))
+	}
+
+	purged, err := purgeDeleted(ctx, p.db, p.tableName, id, bonusPaidOut)
+	if err != nil {
+		return err
+	}
+	if !purged {
+		return fmt.Errorf("no deleted bonus found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Review approves or rejects a pending bonus on behalf of a super admin.
+func (p *bonusesRepo) Review(ctx context.Context, req *entity.ReviewBonusRequest) (*entity.Bonus, error) {
+	var reqID = bonusesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusesRepo.Review - %s", reqID))
+	}
+
+	if req.Status != entity.BonusStatusApproved && req.Status != entity.BonusStatusRejected {
+		return nil, fmt.Errorf("invalid review status %q", req.Status)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	bonus, err := p.lockBonus(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if bonus.Status != entity.BonusStatusPending {
+		return nil, entity.ErrorBonusNotPending
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, bonus.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	clauses := map[string]interface{}{
+		"status":       req.Status,
+		"superadminid": req.SuperAdminID,
+		"reviewed_at":  now,
+		"updated_at":   now,
+	}
+	if req.Comment != nil {
+		clauses["review_comment"] = *req.Comment
+	}
+
 	sqlStr, args, err := p.db.Sq.Builder.
-		Delete(p.tableName).
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(squirrel.Eq{"id": bonus.ID}).
+		Suffix("RETURNING " + strings.Join(bonusColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" review")
+	}
+
+	reviewed, err := scanBonus(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, bonus.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return reviewed, nil
+}
+
+// Report totals the approved bonuses granted from from to to in currency, converting
+// each one at the rates known on the day it was granted.
+func (p *bonusesRepo) Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error) {
+	var reqID = bonusesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusesRepo.Report - %s", reqID))
+	}
+
+	builder := p.db.Sq.Builder.
+		Select("amount", "currency", "created_at").
+		From(p.tableName).
+		Where(squirrel.Eq{"status": entity.BonusStatusApproved}).
+		Where(notDeleted).
+		Where(squirrel.GtOrEq{"created_at": dateOnly(from)}).
+		Where(squirrel.Lt{"created_at": dateOnly(to).AddDate(0, 0, 1)})
+
+	// Apply filters
+	for key, value := range filter {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" report")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var bonuses []*entity.Bonus
+	for rows.Next() {
+		var bonus entity.Bonus
+		if err := rows.Scan(&bonus.Amount, &bonus.Currency, &bonus.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		bonuses = append(bonuses, &bonus)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	totals := newPayoutTotals(p.db, currency, from, to)
+	for _, bonus := range bonuses {
+		if err := totals.add(ctx, bonus.Amount, bonus.Currency, bonus.CreatedAt, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return totals.finish(), nil
+}
+
+// lockBonus returns the bonus with the given ID, locked for the rest of tx.
+func (p *bonusesRepo) lockBonus(ctx context.Context, tx pgx.Tx, id int64) (*entity.Bonus, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(bonusColumns...).
+		From(p.tableName).
 		Where(squirrel.Eq{"id": id}).
+		Where(notDeleted).
+		Suffix("FOR UPDATE").
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("delete query build error: %w", err)
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" lock")
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	bonus, err := scanBonus(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
-		return p.db.Error(err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no bonus found with ID %d", id)
+		}
+		return nil, p.db.Error(err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("no bonus found with ID %d", id)
+	return bonus, nil
+}
+
+func scanBonus(row pgx.Row) (*entity.Bonus, error) {
+	var bonus entity.Bonus
+	var (
+		nullSuperAdminID        sql.NullInt64
+		nullReason              sql.NullString
+		nullProposedBy          sql.NullInt64
+		nullRuleID              sql.NullInt64
+		nullReviewedAt          sql.NullTime
+		nullReviewComment       sql.NullString
+		nullPayoutStatus        sql.NullString
+		nullPayoutTransactionID sql.NullString
+		nullPayrollRunID        sql.NullInt64
+		nullDeletedAt           sql.NullTime
+	)
+
+	err := row.Scan(
+		&bonus.ID,
+		&nullSuperAdminID,
+		&bonus.UserID,
+		&bonus.Amount,
+		&bonus.Currency,
+		&nullReason,
+		&bonus.Status,
+		&nullProposedBy,
+		&nullRuleID,
+		&nullReviewedAt,
+		&nullReviewComment,
+		&nullPayoutStatus,
+		&nullPayoutTransactionID,
+		&nullPayrollRunID,
+		&nullDeletedAt,
+		&bonus.CreatedAt,
+		&bonus.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	if nullSuperAdminID.Valid {
+		bonus.SuperAdminID = &nullSuperAdminID.Int64
+	}
+	if nullReason.Valid {
+		bonus.Reason = &nullReason.String
+	}
+	if nullProposedBy.Valid {
+		bonus.ProposedBy = &nullProposedBy.Int64
+	}
+	if nullRuleID.Valid {
+		bonus.RuleID = &nullRuleID.Int64
+	}
+	if nullReviewedAt.Valid {
+		bonus.ReviewedAt = &nullReviewedAt.Time
+	}
+	if nullReviewComment.Valid {
+		bonus.ReviewComment = &nullReviewComment.String
+	}
+	if nullPayoutStatus.Valid {
+		status := entity.PayoutStatus(nullPayoutStatus.String)
+		bonus.PayoutStatus = &status
+	}
+	if nullPayoutTransactionID.Valid {
+		bonus.PayoutTransactionID = &nullPayoutTransactionID.String
+	}
+	if nullPayrollRunID.Valid {
+		bonus.PayrollRunID = &nullPayrollRunID.Int64
+	}
+	if nullDeletedAt.Valid {
+		bonus.DeletedAt = &nullDeletedAt.Time
+	}
+
+	return &bonus, nil
 }
@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	bonusRuleTableName = "bonus_rules"
+	bonusRuleIDKey     = "bonusRuleID: "
+)
+
+var bonusRuleColumns = []string{
+	"id", "name", "kind", "threshold", "amount", "currency", "active", "admin_id",
+	"created_at", "updated_at",
+}
+
+// BonusRuleRepoInterface defines the interface for bonus rules and their
+// evaluation.
+type BonusRuleRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateBonusRuleRequest) (*entity.BonusRule, error)
+	Get(ctx context.Context, params map[string]string) (*entity.BonusRule, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllBonusRulesResponse, error)
+	Update(ctx context.Context, req *entity.UpdateBonusRuleRequest) error
+	Delete(ctx context.Context, id int64) error
+	Evaluate(ctx context.Context, month time.Time) (*entity.BonusRuleRun, error)
+}
+
+type bonusRuleRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewBonusRuleRepo(db *postgres.Postgres, log *logger.Logger) BonusRuleRepoInterface {
+	return &bonusRuleRepo{
+		tableName: bonusRuleTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+func (p *bonusRuleRepo) Create(ctx context.Context, req *entity.CreateBonusRuleRequest) (*entity.BonusRule, error) {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.Create - %s", reqID))
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns(
+			"name", "kind", "threshold", "amount", "currency", "active", "admin_id",
+			"created_at", "updated_at",
+		).
+		Values(
+			req.Name, req.Kind, req.Threshold, req.Amount, req.Currency, active, req.AdminID,
+			time.Now().UTC(), time.Now().UTC(),
+		).
+		Suffix("RETURNING " + strings.Join(bonusRuleColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rule, err := scanBonusRule(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, rule.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (p *bonusRuleRepo) Get(ctx context.Context, params map[string]string) (*entity.BonusRule, error) {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(bonusRuleColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	rule, err := scanBonusRule(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return rule, nil
+}
+
+func (p *bonusRuleRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllBonusRulesResponse, error) {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.List - %s", reqID))
+	}
+
+	var rules entity.GetAllBonusRulesResponse
+	baseBuilder := p.db.Sq.Builder.Select(bonusRuleColumns...).From(p.tableName)
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := baseBuilder.OrderBy("id").Limit(limit).Offset(offset).ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rule, err := scanBonusRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		rules.Items = append(rules.Items, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&rules.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &rules, nil
+}
+
+func (p *bonusRuleRepo) Update(ctx context.Context, req *entity.UpdateBonusRuleRequest) error {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.Update - %s", reqID))
+	}
+
+	if req == nil {
+		return fmt.Errorf("bonus rule update request cannot be nil")
+	}
+	if req.ID == 0 {
+		return fmt.Errorf("bonus rule ID is required for update")
+	}
+
+	clauses := map[string]interface{}{"updated_at": time.Now().UTC()}
+
+	if req.Name != nil {
+		clauses["name"] = *req.Name
+	}
+	if req.Threshold != nil {
+		clauses["threshold"] = *req.Threshold
+	}
+	if req.Amount != nil {
+		clauses["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		clauses["currency"] = *req.Currency
+	}
+	if req.Active != nil {
+		clauses["active"] = *req.Active
+	}
+
+	if len(clauses) <= 1 { // Only updated_at means no actual fields were passed
+		return fmt.Errorf("no fields to update")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", req.ID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no bonus rule found with ID %d", req.ID)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a rule. Bonuses it already proposed are kept.
+func (p *bonusRuleRepo) Delete(ctx context.Context, id int64) error {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.Delete - %s", reqID))
+	}
+
+	if id == 0 {
+		return fmt.Errorf("bonus rule ID is required for deletion")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no bonus rule found with ID %d", id)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// ruleMatch is one employee meeting a rule over the evaluated month.
+type ruleMatch struct {
+	rule   *entity.BonusRule
+	userID int64
+	count  int // Days attended or tasks completed
+}
+
+// Evaluate checks every active rule against the attendance and tasks of the
+// calendar month containing month and proposes a pending bonus to each
+// employee meeting it. A rule proposes at most one bonus per employee and
+// month, so evaluating the same month again only adds new matches.
+func (p *bonusRuleRepo) Evaluate(ctx context.Context, month time.Time) (*entity.BonusRuleRun, error) {
+	var reqID = bonusRuleIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("bonusRuleRepo.Evaluate - %s", reqID))
+	}
+
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	run := &entity.BonusRuleRun{PeriodStart: from, PeriodEnd: to, Proposed: []*entity.Bonus{}}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(bonusRuleColumns...).
+		From(p.tableName).
+		Where(squirrel.Eq{"active": true}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" active")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var rules []*entity.BonusRule
+	for rows.Next() {
+		rule, err := scanBonusRule(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	run.Rules = len(rules)
+
+	var matches []ruleMatch
+	for _, rule := range rules {
+		ruleMatches, err := p.match(ctx, rule, from, to)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, ruleMatches...)
+	}
+	if len(matches) == 0 {
+		return run, nil
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, match := range matches {
+		sqlStr, args, err := p.db.Sq.Builder.
+			Insert(bonusesTableName).
+			Columns(
+				"user_id", "amount", "currency", "reason", "status", "rule_id", "rule_period",
+				"created_at", "updated_at",
+			).
+			Values(
+				match.userID, match.rule.Amount, match.rule.Currency, ruleReason(match, from), entity.BonusStatusPending,
+				match.rule.ID, from, time.Now().UTC(), time.Now().UTC(),
+			).
+			Suffix("ON CONFLICT (rule_id, user_id, rule_period) DO NOTHING RETURNING " + strings.Join(bonusColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, bonusesTableName+" propose")
+		}
+
+		bonus, err := scanBonus(tx.QueryRow(ctx, sqlStr, args...))
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Already proposed for this month
+			continue
+		}
+		if err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, bonusesTableName, bonus.ID, nil); err != nil {
+			return nil, err
+		}
+		run.Proposed = append(run.Proposed, bonus)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return run, nil
+}
+
+// match returns the employees meeting rule from from to to (inclusive).
+// Attendance rules need at least Threshold days attended, late days
+// included; task rules need Threshold high-priority tasks completed before
+// their due date.
+func (p *bonusRuleRepo) match(ctx context.Context, rule *entity.BonusRule, from, to time.Time) ([]ruleMatch, error) {
+	var builder squirrel.SelectBuilder
+	switch rule.Kind {
+	case entity.BonusRuleKindPerfectAttendance, entity.BonusRuleKindZeroLateDays:
+		// Absences are recorded by the nightly job, so a month without any
+		// is a month without a missed shift
+		missed := entity.AttendanceStatusAbsent
+		if rule.Kind == entity.BonusRuleKindZeroLateDays {
+			missed = entity.AttendanceStatusLate
+		}
+		builder = p.db.Sq.Builder.
+			Select("user_id", "COUNT(*) FILTER (WHERE status <> 'absent')").
+			From(attendanceTableName).
+			Where("user_id IN (SELECT id FROM "+userTableName+" WHERE deleted_at IS NULL)").
+			Where(squirrel.GtOrEq{"date": from}).
+			Where(squirrel.LtOrEq{"date": to}).
+			GroupBy("user_id").
+			Having("COUNT(*) FILTER (WHERE status <> 'absent') >= ?", rule.Threshold).
+			Having("COUNT(*) FILTER (WHERE status = ?) = 0", missed)
+	case entity.BonusRuleKindHighPriorityTasks:
+		builder = p.db.Sq.Builder.
+			Select("assignedto", "COUNT(*)").
+			From(taskTableName).
+			Where(squirrel.NotEq{"assignedto": nil}).
+			Where(notDeleted).
+			Where("assignedto IN (SELECT id FROM "+userTableName+" WHERE deleted_at IS NULL)").
+			Where(squirrel.Eq{"status": entity.TaskStatusCompleted, "priority": entity.TaskPriorityHigh}).
+			Where("duedate IS NOT NULL AND completed_at <= duedate").
+			Where(squirrel.GtOrEq{"completed_at": from}).
+			Where(squirrel.Lt{"completed_at": to.AddDate(0, 0, 1)}).
+			GroupBy("assignedto").
+			Having("COUNT(*) >= ?", rule.Threshold)
+	default:
+		return nil, fmt.Errorf("unknown bonus rule kind %q", rule.Kind)
+	}
+
+	sqlStr, args, err := builder.OrderBy("1").ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" match")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []ruleMatch
+	for rows.Next() {
+		match := ruleMatch{rule: rule}
+		if err := rows.Scan(&match.userID, &match.count); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return matches, nil
+}
+
+// ruleReason explains why match earned its bonus.
+func ruleReason(match ruleMatch, from time.Time) string {
+	month := from.Format("January 2006")
+
+	switch match.rule.Kind {
+	case entity.BonusRuleKindPerfectAttendance:
+		return fmt.Sprintf("%s: no absences in %s over %d days attended", match.rule.Name, month, match.count)
+	case entity.BonusRuleKindZeroLateDays:
+		return fmt.Sprintf("%s: no late arrivals in %s over %d days attended", match.rule.Name, month, match.count)
+	case entity.BonusRuleKindHighPriorityTasks:
+		return fmt.Sprintf("%s: %d high-priority tasks completed before their due date in %s", match.rule.Name, match.count, month)
+	}
+
+	return fmt.Sprintf("%s: %s", match.rule.Name, month)
+}
+
+func scanBonusRule(row pgx.Row) (*entity.BonusRule, error) {
+	var rule entity.BonusRule
+	var nullAdminID sql.NullInt64
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.Name,
+		&rule.Kind,
+		&rule.Threshold,
+		&rule.Amount,
+		&rule.Currency,
+		&rule.Active,
+		&nullAdminID,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullAdminID.Valid {
+		rule.AdminID = &nullAdminID.Int64
+	}
+
+	return &rule, nil
+}
@@ -0,0 +1,384 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	compensationTableName = "compensations"
+	compensationIDKey     = "compensationID: "
+)
+
+var compensationColumns = []string{
+	"id", "user_id", "rate_type", "amount", "currency", "effective_from", "admin_id", "note",
+	"created_at", "updated_at",
+}
+
+// CompensationRepoInterface defines the interface for employees' compensation profiles.
+type CompensationRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateCompensationRequest) (*entity.Compensation, error)
+	Get(ctx context.Context, params map[string]string) (*entity.Compensation, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllCompensationsResponse, error)
+	Update(ctx context.Context, req *entity.UpdateCompensationRequest) error
+	Delete(ctx context.Context, id int64) error
+	Current(ctx context.Context, userID int64, date time.Time) (*entity.Compensation, error)
+}
+
+type compensationRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewCompensationRepo(db *postgres.Postgres, log *logger.Logger) CompensationRepoInterface {
+	return &compensationRepo{
+		tableName: compensationTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+func (p *compensationRepo) Create(ctx context.Context, req *entity.CreateCompensationRequest) (*entity.Compensation, error) {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.Create - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns(
+			"user_id", "rate_type", "amount", "currency", "effective_from", "admin_id", "note",
+			"created_at", "updated_at",
+		).
+		Values(
+			req.UserID, req.RateType, req.Amount, req.Currency, dateOnly(req.EffectiveFrom), req.AdminID, req.Note,
+			time.Now().UTC(), time.Now().UTC(),
+		).
+		Suffix("RETURNING " + strings.Join(compensationColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	compensation, err := scanCompensation(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, compensation.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return compensation, nil
+}
+
+func (p *compensationRepo) Get(ctx context.Context, params map[string]string) (*entity.Compensation, error) {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(compensationColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	compensation, err := scanCompensation(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return compensation, nil
+}
+
+// List returns compensation profiles ordered by user, newest first, so the
+// profiles of one user read as their history.
+func (p *compensationRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllCompensationsResponse, error) {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.List - %s", reqID))
+	}
+
+	var compensations entity.GetAllCompensationsResponse
+	baseBuilder := p.db.Sq.Builder.Select(compensationColumns...).From(p.tableName)
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := baseBuilder.OrderBy("user_id", "effective_from DESC").Limit(limit).Offset(offset).ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		compensation, err := scanCompensation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		compensations.Items = append(compensations.Items, compensation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&compensations.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &compensations, nil
+}
+
+func (p *compensationRepo) Update(ctx context.Context, req *entity.UpdateCompensationRequest) error {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.Update - %s", reqID))
+	}
+
+	if req == nil {
+		return fmt.Errorf("compensation update request cannot be nil")
+	}
+	if req.ID == 0 {
+		return fmt.Errorf("compensation ID is required for update")
+	}
+
+	clauses := map[string]interface{}{"updated_at": time.Now().UTC()}
+
+	if req.RateType != nil {
+		clauses["rate_type"] = *req.RateType
+	}
+	if req.Amount != nil {
+		clauses["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		clauses["currency"] = *req.Currency
+	}
+	if req.EffectiveFrom != nil {
+		clauses["effective_from"] = dateOnly(*req.EffectiveFrom)
+	}
+	if req.Note != nil {
+		clauses["note"] = *req.Note
+	}
+
+	if len(clauses) <= 1 { // Only updated_at means no actual fields were passed
+		return fmt.Errorf("no fields to update")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", req.ID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no compensation found with ID %d", req.ID)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *compensationRepo) Delete(ctx context.Context, id int64) error {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.Delete - %s", reqID))
+	}
+
+	if id == 0 {
+		return fmt.Errorf("compensation ID is required for deletion")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no compensation found with ID %d", id)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// Current returns the compensation profile of the user in effect on date, or
+// pgx.ErrNoRows when none had started by then.
+func (p *compensationRepo) Current(ctx context.Context, userID int64, date time.Time) (*entity.Compensation, error) {
+	var reqID = compensationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("compensationRepo.Current - %s", reqID))
+	}
+
+	compensation, err := loadCompensation(ctx, p.db, p.db, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	if compensation == nil {
+		return nil, pgx.ErrNoRows
+	}
+
+	return compensation, nil
+}
+
+// loadCompensation returns the compensation profile of the user in effect on
+// date, or nil when there is none.
+func loadCompensation(ctx context.Context, db *postgres.Postgres, q rowQuerier, userID int64, date time.Time) (*entity.Compensation, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Select(compensationColumns...).
+		From(compensationTableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.LtOrEq{"effective_from": dateOnly(date)}).
+		OrderBy("effective_from DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, compensationTableName+" current")
+	}
+
+	compensation, err := scanCompensation(q.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, db.Error(err)
+	}
+
+	return compensation, nil
+}
+
+func scanCompensation(row pgx.Row) (*entity.Compensation, error) {
+	var compensation entity.Compensation
+	var (
+		nullAdminID sql.NullInt64
+		nullNote    sql.NullString
+	)
+
+	err := row.Scan(
+		&compensation.ID,
+		&compensation.UserID,
+		&compensation.RateType,
+		&compensation.Amount,
+		&compensation.Currency,
+		&compensation.EffectiveFrom,
+		&nullAdminID,
+		&nullNote,
+		&compensation.CreatedAt,
+		&compensation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullAdminID.Valid {
+		compensation.AdminID = &nullAdminID.Int64
+	}
+	if nullNote.Valid {
+		compensation.Note = &nullNote.String
+	}
+
+	return &compensation, nil
+}
@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	currencyTableName     = "currencies"
+	exchangeRateTableName = "exchange_rates"
+	currencyIDKey         = "currencyID: "
+)
+
+var exchangeRateColumns = []string{
+	"id", "base", "quote", "rate_date", "rate", "created_at", "updated_at",
+}
+
+// CurrencyRepoInterface defines the interface for currencies and their exchange rates.
+type CurrencyRepoInterface interface {
+	CreateCurrency(ctx context.Context, req *entity.CreateCurrencyRequest) (*entity.CurrencyDetails, error)
+	GetCurrency(ctx context.Context, code entity.Currency) (*entity.CurrencyDetails, error)
+	ListCurrencies(ctx context.Context) (*entity.GetAllCurrenciesResponse, error)
+	UploadRates(ctx context.Context, req *entity.UploadExchangeRatesRequest) ([]*entity.ExchangeRate, error)
+	ListRates(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllExchangeRatesResponse, error)
+	Convert(ctx context.Context, amount float64, from, to entity.Currency, date time.Time) (float64, error)
+}
+
+type currencyRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewCurrencyRepo(db *postgres.Postgres, log *logger.Logger) CurrencyRepoInterface {
+	return &currencyRepo{
+		tableName: currencyTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+func (p *currencyRepo) CreateCurrency(ctx context.Context, req *entity.CreateCurrencyRequest) (*entity.CurrencyDetails, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.CreateCurrency - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("code", "name", "created_at").
+		Values(req.Code, req.Name, time.Now().UTC()).
+		Suffix("RETURNING code, name, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currency entity.CurrencyDetails
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(&currency.Code, &currency.Name, &currency.CreatedAt)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, currency.Code, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &currency, nil
+}
+
+// GetCurrency returns the currency with code, or pgx.ErrNoRows when it is unknown.
+func (p *currencyRepo) GetCurrency(ctx context.Context, code entity.Currency) (*entity.CurrencyDetails, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.GetCurrency - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("code", "name", "created_at").
+		From(p.tableName).
+		Where(squirrel.Eq{"code": code}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var currency entity.CurrencyDetails
+	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(&currency.Code, &currency.Name, &currency.CreatedAt)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &currency, nil
+}
+
+func (p *currencyRepo) ListCurrencies(ctx context.Context) (*entity.GetAllCurrenciesResponse, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.ListCurrencies - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("code", "name", "created_at").
+		From(p.tableName).
+		OrderBy("code").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var currencies entity.GetAllCurrenciesResponse
+	for rows.Next() {
+		var currency entity.CurrencyDetails
+		if err := rows.Scan(&currency.Code, &currency.Name, &currency.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		currencies.Items = append(currencies.Items, &currency)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	currencies.Total = uint64(len(currencies.Items))
+	return &currencies, nil
+}
+
+// UploadRates stores the rates of req in one transaction, replacing rates
+// already stored for the same pair and date.
+func (p *currencyRepo) UploadRates(ctx context.Context, req *entity.UploadExchangeRatesRequest) ([]*entity.ExchangeRate, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.UploadRates - %s", reqID))
+	}
+
+	quotes := make([]entity.Currency, 0, len(req.Rates))
+	for quote := range req.Rates {
+		quotes = append(quotes, quote)
+	}
+	slices.Sort(quotes)
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rates := make([]*entity.ExchangeRate, 0, len(quotes))
+	for _, quote := range quotes {
+		// The rate being replaced, if any, is kept in the audit trail
+		beforeSqlStr, beforeArgs, err := p.db.Sq.Builder.
+			Select("to_jsonb(r)").
+			From(exchangeRateTableName + " r").
+			Where(squirrel.Eq{"base": req.Base, "quote": quote, "rate_date": dateOnly(req.Date)}).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, exchangeRateTableName+" snapshot")
+		}
+
+		var before []byte
+		if err := tx.QueryRow(ctx, beforeSqlStr, beforeArgs...).Scan(&before); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, p.db.Error(err)
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Insert(exchangeRateTableName).
+			Columns("base", "quote", "rate_date", "rate", "created_at", "updated_at").
+			Values(req.Base, quote, dateOnly(req.Date), req.Rates[quote], time.Now().UTC(), time.Now().UTC()).
+			Suffix(
+				"ON CONFLICT (base, quote, rate_date) DO UPDATE SET rate = EXCLUDED.rate, updated_at = EXCLUDED.updated_at " +
+					"RETURNING " + strings.Join(exchangeRateColumns, ", "),
+			).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, exchangeRateTableName+" upload")
+		}
+
+		rate, err := scanExchangeRate(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		action := entity.AuditActionCreate
+		if before != nil {
+			action = entity.AuditActionUpdate
+		}
+		if err := recordAudit(ctx, p.db, tx, action, exchangeRateTableName, rate.ID, before); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return rates, nil
+}
+
+func (p *currencyRepo) ListRates(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllExchangeRatesResponse, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.ListRates - %s", reqID))
+	}
+
+	var rates entity.GetAllExchangeRatesResponse
+	baseBuilder := p.db.Sq.Builder.Select(exchangeRateColumns...).From(exchangeRateTableName)
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(exchangeRateTableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := baseBuilder.OrderBy("rate_date DESC", "base", "quote").Limit(limit).Offset(offset).ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, exchangeRateTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		rates.Items = append(rates.Items, rate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, exchangeRateTableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&rates.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &rates, nil
+}
+
+// Convert converts amount from one currency to another at the latest rates
+// known on date.
+func (p *currencyRepo) Convert(ctx context.Context, amount float64, from, to entity.Currency, date time.Time) (float64, error) {
+	var reqID = currencyIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("currencyRepo.Convert - %s", reqID))
+	}
+
+	rates, err := loadRates(ctx, p.db, p.db, date)
+	if err != nil {
+		return 0, err
+	}
+
+	return rates.convert(amount, from, to)
+}
+
+// rateTable holds the latest rate of every currency pair as of one date.
+type rateTable map[[2]entity.Currency]float64
+
+// loadRates returns the latest rate of every pair stored on or before date.
+func loadRates(ctx context.Context, db *postgres.Postgres, q querier, date time.Time) (rateTable, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Select("base", "quote", "rate").
+		Options("DISTINCT ON (base, quote)").
+		From(exchangeRateTableName).
+		Where(squirrel.LtOrEq{"rate_date": dateOnly(date)}).
+		OrderBy("base", "quote", "rate_date DESC").
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, exchangeRateTableName+" latest")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	rates := make(rateTable)
+	for rows.Next() {
+		var (
+			base, quote entity.Currency
+			rate        float64
+		)
+		if err := rows.Scan(&base, &quote, &rate); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		rates[[2]entity.Currency{base, quote}] = rate
+	}
+
+	return rates, rows.Err()
+}
+
+// rate returns how many units of quote one unit of base is worth, using the
+// inverse of the opposite pair when only that one is known.
+func (t rateTable) rate(base, quote entity.Currency) (float64, bool) {
+	if base == quote {
+		return 1, true
+	}
+	if rate, ok := t[[2]entity.Currency{base, quote}]; ok {
+		return rate, true
+	}
+	if rate, ok := t[[2]entity.Currency{quote, base}]; ok {
+		return 1 / rate, true
+	}
+
+	return 0, false
+}
+
+// convert converts amount directly when the pair is known and otherwise
+// through the first currency, in code order, that has rates for both.
+func (t rateTable) convert(amount float64, from, to entity.Currency) (float64, error) {
+	if rate, ok := t.rate(from, to); ok {
+		return amount * rate, nil
+	}
+
+	var pivots []entity.Currency
+	for pair := range t {
+		pivots = append(pivots, pair[0], pair[1])
+	}
+	slices.Sort(pivots)
+
+	for _, pivot := range slices.Compact(pivots) {
+		fromRate, okFrom := t.rate(pivot, from)
+		toRate, okTo := t.rate(pivot, to)
+		if okFrom && okTo {
+			return amount / fromRate * toRate, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w from %s to %s", entity.ErrorNoExchangeRate, from, to)
+}
+
+// payoutTotals accumulates a PayoutReport, loading the rates of each date once.
+type payoutTotals struct {
+	db       *postgres.Postgres
+	report   *entity.PayoutReport
+	rates    map[time.Time]rateTable
+	original map[entity.Currency]float64
+}
+
+func newPayoutTotals(db *postgres.Postgres, currency entity.Currency, from, to time.Time) *payoutTotals {
+	return &payoutTotals{
+		db:       db,
+		report:   &entity.PayoutReport{Currency: currency, From: dateOnly(from), To: dateOnly(to)},
+		rates:    make(map[time.Time]rateTable),
+		original: make(map[entity.Currency]float64),
+	}
+}
+
+// add converts amount at the rates of date into the reporting currency and
+// adds it to the total, and to status unless it is empty.
+func (t *payoutTotals) add(ctx context.Context, amount float64, currency entity.Currency, date time.Time, status string) error {
+	date = dateOnly(date)
+	rates, ok := t.rates[date]
+	if !ok {
+		var err error
+		if rates, err = loadRates(ctx, t.db, t.db, date); err != nil {
+			return err
+		}
+		t.rates[date] = rates
+	}
+
+	converted, err := rates.convert(amount, currency, t.report.Currency)
+	if err != nil {
+		return fmt.Errorf("%w on %s", err, date.Format(time.DateOnly))
+	}
+
+	t.report.Count++
+	t.report.Total += converted
+	t.original[currency] += amount
+	if status != "" {
+		if t.report.ByStatus == nil {
+			t.report.ByStatus = make(map[string]float64)
+		}
+		t.report.ByStatus[status] += converted
+	}
+
+	return nil
+}
+
+// finish rounds the totals and returns the report.
+func (t *payoutTotals) finish() *entity.PayoutReport {
+	t.report.Total = roundMoney(t.report.Total)
+	for status, total := range t.report.ByStatus {
+		t.report.ByStatus[status] = roundMoney(total)
+	}
+
+	currencies := make([]entity.Currency, 0, len(t.original))
+	for currency := range t.original {
+		currencies = append(currencies, currency)
+	}
+	slices.Sort(currencies)
+
+	t.report.Original = make([]*entity.CurrencyAmount, 0, len(currencies))
+	for _, currency := range currencies {
+		t.report.Original = append(t.report.Original, &entity.CurrencyAmount{
+			Currency: currency,
+			Amount:   roundMoney(t.original[currency]),
+		})
+	}
+
+	return t.report
+}
+
+// roundMoney rounds amount to cents.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func scanExchangeRate(row pgx.Row) (*entity.ExchangeRate, error) {
+	var rate entity.ExchangeRate
+	err := row.Scan(
+		&rate.ID,
+		&rate.Base,
+		&rate.Quote,
+		&rate.Date,
+		&rate.Rate,
+		&rate.CreatedAt,
+		&rate.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rate, nil
+}
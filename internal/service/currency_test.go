@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+)
+
+func TestRateTableConvert(t *testing.T) {
+	rates := rateTable{
+		{entity.CurrencyUSD, entity.CurrencyUZS}: 12650,
+		{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1,
+	}
+
+	tests := []struct {
+		name     string
+		amount   float64
+		from, to entity.Currency
+		want     float64
+		wantErr  error
+	}{
+		{name: "same currency", amount: 100, from: entity.CurrencyGBP, to: entity.CurrencyGBP, want: 100},
+		{name: "direct", amount: 100, from: entity.CurrencyEUR, to: entity.CurrencyUSD, want: 110},
+		{name: "inverse", amount: 126500, from: entity.CurrencyUZS, to: entity.CurrencyUSD, want: 10},
+		{name: "through a pivot", amount: 10, from: entity.CurrencyEUR, to: entity.CurrencyUZS, want: 139150},
+		{name: "through a pivot inverse", amount: 139150, from: entity.CurrencyUZS, to: entity.CurrencyEUR, want: 10},
+		{name: "unknown", amount: 10, from: entity.CurrencyGBP, to: entity.CurrencyUSD, wantErr: entity.ErrorNoExchangeRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.convert(tt.amount, tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("convert error = %v, want %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("convert = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundMoney(t *testing.T) {
+	tests := []struct {
+		amount, want float64
+	}{
+		{0, 0},
+		{12.344, 12.34},
+		{12.345, 12.35},
+		{12.3449, 12.34},
+		{-12.345, -12.35},
+		{800.008, 800.01},
+		{1e9 / 3, 333333333.33},
+	}
+
+	for _, tt := range tests {
+		if got := roundMoney(tt.amount); got != tt.want {
+			t.Errorf("roundMoney(%v) = %v, want %v", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/pkg/config"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	"github.com/ruziba3vich/argus/migrations"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+// testDB connects to the database named by ARGUS_TEST_DATABASE, migrated to
+// the latest version and emptied, and skips the test when it is not set. The
+// server is found through the same POSTGRES_* variables the app reads. The
+// database is wiped, never point it at real data.
+func testDB(t *testing.T) *postgres.Postgres {
+	t.Helper()
+
+	name := os.Getenv("ARGUS_TEST_DATABASE")
+	if name == "" {
+		t.Skip("ARGUS_TEST_DATABASE is not set")
+	}
+
+	var cfg config.Config
+	cfg.DB.Host = testEnv("POSTGRES_HOST", "localhost")
+	cfg.DB.Port = testEnv("POSTGRES_PORT", "5432")
+	cfg.DB.User = testEnv("POSTGRES_USER", "postgres")
+	cfg.DB.Password = testEnv("POSTGRES_PASSWORD", "postgres")
+	cfg.DB.SSLMode = testEnv("POSTGRES_SSLMODE", "disable")
+	cfg.DB.Name = name
+
+	db, err := postgres.New(&cfg, postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	ctx := context.Background()
+	migrator, err := postgres.NewMigrator(db, migrations.FS)
+	if err != nil {
+		t.Fatalf("load migrations: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	// Everything else hangs off these, the seeded currencies are kept
+	_, err = db.Exec(ctx, "TRUNCATE "+userTableName+", "+payrollRunTableName+", "+exchangeRateTableName+", "+auditLogTableName+" RESTART IDENTITY CASCADE")
+	if err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+
+	return db
+}
+
+func testEnv(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+
+	return defaultValue
+}
+
+// testLogger returns a logger writing to a file of the test's temp dir.
+func testLogger(t *testing.T) *logger.Logger {
+	t.Helper()
+
+	log, err := logger.NewLogger(filepath.Join(t.TempDir(), "test.log"))
+	if err != nil {
+		t.Fatalf("logger: %v", err)
+	}
+
+	return log
+}
+
+// createTestUser inserts a user with the given role and returns its ID. The
+// email is <name>@example.com.
+func createTestUser(t *testing.T, db *postgres.Postgres, name string, role entity.UserRole) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(),
+		"INSERT INTO "+userTableName+" (first_name, role, email, hashed_password) VALUES ($1, $2, $3, 'x') RETURNING id",
+		name, role, fmt.Sprintf("%s@example.com", name),
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+
+	return id
+}
+
+// createTestTask inserts a task created by adminID and assigned to
+// assignedTo, which may be nil, and returns its ID.
+func createTestTask(t *testing.T, db *postgres.Postgres, adminID int64, assignedTo *int64) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(),
+		"INSERT INTO "+taskTableName+" (admin_id, assignedto, title) VALUES ($1, $2, 'Test task') RETURNING id",
+		adminID, assignedTo,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	return id
+}
+
+// createTestSalary inserts a salary of userID entered by adminID and returns
+// its ID.
+func createTestSalary(t *testing.T, db *postgres.Postgres, userID, adminID int64, amount float64, currency entity.Currency, payDate time.Time, status entity.SalaryStatus) int64 {
+	t.Helper()
+
+	var id int64
+	err := db.QueryRow(context.Background(),
+		"INSERT INTO "+salaryTableName+" (user_id, admin_id, amount, currency, pay_date, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		userID, adminID, amount, currency, payDate, status,
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create salary: %v", err)
+	}
+
+	return id
+}
+
+// createTestRate stores the rate of base in quote on date.
+func createTestRate(t *testing.T, db *postgres.Postgres, base, quote entity.Currency, date time.Time, rate float64) {
+	t.Helper()
+
+	_, err := db.Exec(context.Background(),
+		"INSERT INTO "+exchangeRateTableName+" (base, quote, rate_date, rate) VALUES ($1, $2, $3, $4)",
+		base, quote, date, rate,
+	)
+	if err != nil {
+		t.Fatalf("create rate: %v", err)
+	}
+}
+
+// testExec runs a fixture statement.
+func testExec(t *testing.T, db *postgres.Postgres, sqlStr string, args ...interface{}) {
+	t.Helper()
+
+	if _, err := db.Exec(context.Background(), sqlStr, args...); err != nil {
+		t.Fatalf("%s: %v", sqlStr, err)
+	}
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	deductionsTableName = "deductions"
+	deductionsIDKey     = "deductionID: "
+)
+
+var deductionColumns = []string{
+	"id", "admin_id", "user_id", "attendance_id", "kind", "amount", "currency",
+	"reason", "created_at", "updated_at",
+}
+
+// DeductionsRepoInterface defines the interface for Deduction CRUD operations.
+type DeductionsRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateDeductionRequest) (*entity.Deduction, error)
+	Get(ctx context.Context, params map[string]string) (*entity.Deduction, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllDeductionsResponse, error)
+	Update(ctx context.Context, req *entity.UpdateDeductionRequest) error
+	Delete(ctx context.Context, id int64) error
+	Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error)
+}
+
+type deductionsRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewDeductionsRepo(db *postgres.Postgres, log *logger.Logger) DeductionsRepoInterface {
+	return &deductionsRepo{
+		tableName: deductionsTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+// Create issues a deduction. A linked attendance record must belong to the
+// same employee, otherwise it fails with entity.ErrorDeductionAttendance.
+func (p *deductionsRepo) Create(ctx context.Context, req *entity.CreateDeductionRequest) (*entity.Deduction, error) {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.Create - %s", reqID))
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = entity.DeductionKindFine
+	}
+
+	// Define columns and values for insertion.
+	// Always include non-nullable fields.
+	columns := []string{
+		"user_id", "kind", "amount", "currency",
+		"created_at", "updated_at",
+	}
+	values := []interface{}{
+		req.UserID, kind, req.Amount, req.Currency,
+		time.Now().UTC(), time.Now().UTC(),
+	}
+
+	// Conditionally add nullable fields
+	if req.AdminID != 0 {
+		columns = append(columns, "admin_id")
+		values = append(values, req.AdminID)
+	}
+	if req.AttendanceID != nil {
+		columns = append(columns, "attendance_id")
+		values = append(values, *req.AttendanceID)
+	}
+	if req.Reason != nil {
+		columns = append(columns, "reason")
+		values = append(values, *req.Reason)
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns(columns...).
+		Values(values...).
+		Suffix("RETURNING " + strings.Join(deductionColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if req.AttendanceID != nil {
+		if err := p.checkAttendance(ctx, tx, req.UserID, *req.AttendanceID); err != nil {
+			return nil, err
+		}
+	}
+
+	createdDeduction, err := scanDeduction(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdDeduction.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return createdDeduction, nil
+}
+
+func (p *deductionsRepo) Get(ctx context.Context, params map[string]string) (*entity.Deduction, error) {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(deductionColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	deduction, err := scanDeduction(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return deduction, nil
+}
+
+func (p *deductionsRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllDeductionsResponse, error) {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.List - %s", reqID))
+	}
+
+	var deductions entity.GetAllDeductionsResponse
+	baseBuilder := p.db.Sq.Builder.Select(deductionColumns...).From(p.tableName)
+
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	// Apply search on the reason
+	if search != "" {
+		searchClause := squirrel.ILike{"reason": fmt.Sprintf("%%%s%%", search)}
+		baseBuilder = baseBuilder.Where(searchClause)
+		countBuilder = countBuilder.Where(searchClause)
+	}
+
+	// Order, Limit, Offset for data query
+	baseBuilder = baseBuilder.OrderBy("created_at DESC").Limit(limit).Offset(offset)
+
+	sqlStr, args, err := baseBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	for rows.Next() {
+		deduction, err := scanDeduction(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		deductions.Items = append(deductions.Items, deduction)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	// Get total count
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&deductions.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &deductions, nil
+}
+
+// Update changes a deduction. Moving it to another employee or attendance
+// record checks the link again like Create does.
+func (p *deductionsRepo) Update(ctx context.Context, req *entity.UpdateDeductionRequest) error {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.Update - %s", reqID))
+	}
+
+	if req == nil {
+		return fmt.Errorf("deduction update request cannot be nil")
+	}
+	if req.ID == 0 {
+		return fmt.Errorf("deduction ID is required for update")
+	}
+
+	clauses := map[string]interface{}{"updated_at": time.Now().UTC()}
+
+	if req.UserID != nil {
+		clauses["user_id"] = *req.UserID
+	}
+	if req.AttendanceID != nil {
+		clauses["attendance_id"] = *req.AttendanceID
+	}
+	if req.Kind != nil {
+		clauses["kind"] = *req.Kind
+	}
+	if req.Amount != nil {
+		clauses["amount"] = *req.Amount
+	}
+	if req.Currency != nil {
+		clauses["currency"] = *req.Currency
+	}
+	if req.Reason != nil {
+		clauses["reason"] = *req.Reason
+	}
+
+	if len(clauses) <= 1 { // Only updated_at means no actual fields were passed
+		return fmt.Errorf("no fields to update")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", req.ID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	deduction, err := p.lockDeduction(ctx, tx, req.ID)
+	if err != nil {
+		return err
+	}
+
+	if req.UserID != nil || req.AttendanceID != nil {
+		userID, attendanceID := deduction.UserID, deduction.AttendanceID
+		if req.UserID != nil {
+			userID = *req.UserID
+		}
+		if req.AttendanceID != nil {
+			attendanceID = req.AttendanceID
+		}
+		if attendanceID != nil {
+			if err := p.checkAttendance(ctx, tx, userID, *attendanceID); err != nil {
+				return err
+			}
+		}
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, sqlStr, args...); err != nil {
+		return p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *deductionsRepo) Delete(ctx context.Context, id int64) error {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.Delete - %s", reqID))
+	}
+
+	if id == 0 {
+		return fmt.Errorf("deduction ID is required for deletion")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no deduction found with ID %d", id)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// Report totals the deductions issued from from to to in currency, converting
+// each one at the rates known on the day it was issued.
+func (p *deductionsRepo) Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error) {
+	var reqID = deductionsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("deductionsRepo.Report - %s", reqID))
+	}
+
+	builder := p.db.Sq.Builder.
+		Select("amount", "currency", "created_at").
+		From(p.tableName).
+		Where(squirrel.GtOrEq{"created_at": dateOnly(from)}).
+		Where(squirrel.Lt{"created_at": dateOnly(to).AddDate(0, 0, 1)})
+
+	// Apply filters
+	for key, value := range filter {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" report")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var deductions []*entity.Deduction
+	for rows.Next() {
+		var deduction entity.Deduction
+		if err := rows.Scan(&deduction.Amount, &deduction.Currency, &deduction.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		deductions = append(deductions, &deduction)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	totals := newPayoutTotals(p.db, currency, from, to)
+	for _, deduction := range deductions {
+		if err := totals.add(ctx, deduction.Amount, deduction.Currency, deduction.CreatedAt, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return totals.finish(), nil
+}
+
+// checkAttendance makes sure the attendance record with the given ID exists
+// and belongs to userID.
+func (p *deductionsRepo) checkAttendance(ctx context.Context, tx pgx.Tx, userID, attendanceID int64) error {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("user_id").
+		From(attendanceTableName).
+		Where(squirrel.Eq{"id": attendanceID}).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, attendanceTableName+" deduction")
+	}
+
+	var ownerID int64
+	if err := tx.QueryRow(ctx, sqlStr, args...).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no attendance found with ID %d", attendanceID)
+		}
+		return p.db.Error(err)
+	}
+	if ownerID != userID {
+		return entity.ErrorDeductionAttendance
+	}
+
+	return nil
+}
+
+// lockDeduction returns the deduction with the given ID, locked for the rest
+// of tx.
+func (p *deductionsRepo) lockDeduction(ctx context.Context, tx pgx.Tx, id int64) (*entity.Deduction, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(deductionColumns...).
+		From(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" lock")
+	}
+
+	deduction, err := scanDeduction(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no deduction found with ID %d", id)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	return deduction, nil
+}
+
+func scanDeduction(row pgx.Row) (*entity.Deduction, error) {
+	var deduction entity.Deduction
+	var (
+		nullAdminID      sql.NullInt64
+		nullAttendanceID sql.NullInt64
+		nullReason       sql.NullString
+	)
+
+	err := row.Scan(
+		&deduction.ID,
+		&nullAdminID,
+		&deduction.UserID,
+		&nullAttendanceID,
+		&deduction.Kind,
+		&deduction.Amount,
+		&deduction.Currency,
+		&nullReason,
+		&deduction.CreatedAt,
+		&deduction.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullAdminID.Valid {
+		deduction.AdminID = &nullAdminID.Int64
+	}
+	if nullAttendanceID.Valid {
+		deduction.AttendanceID = &nullAttendanceID.Int64
+	}
+	if nullReason.Valid {
+		deduction.Reason = &nullReason.String
+	}
+
+	return &deduction, nil
+}
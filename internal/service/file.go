@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -19,6 +20,11 @@ const (
 	fileIDKey     = "fileID: "
 )
 
+var fileColumns = []string{
+	"id", "name", "taskid", "object_key", "size", "mime_type", "uploaded_by",
+	"created_at", "updated_at",
+}
+
 // FileRepoInterface defines the interface for File CRUD operations.
 type FileRepoInterface interface {
 	Create(ctx context.Context, req *entity.CreateFileRequest) (*entity.File, error)
@@ -26,6 +32,7 @@ type FileRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllFilesResponse, error)
 	Update(ctx context.Context, req *entity.UpdateFileRequest) error
 	Delete(ctx context.Context, id int64) error
+	ObjectKeys(ctx context.Context, taskID int64) ([]string, error)
 }
 
 type fileRepo struct {
@@ -51,11 +58,11 @@ func (p *fileRepo) Create(ctx context.Context, req *entity.CreateFileRequest) (*
 
 	// Define columns and values for insertion.
 	columns := []string{
-		"name",
+		"name", "object_key", "size", "mime_type", "uploaded_by",
 		"created_at", "updated_at",
 	}
 	values := []interface{}{
-		req.Name,
+		req.Name, req.ObjectKey, req.Size, req.MimeType, req.UploadedBy,
 		time.Now().UTC(), time.Now().UTC(),
 	}
 
@@ -69,42 +76,32 @@ func (p *fileRepo) Create(ctx context.Context, req *entity.CreateFileRequest) (*
 		Insert(p.tableName).
 		Columns(columns...).
 		Values(values...).
-		Suffix(`RETURNING id, name, taskid, created_at, updated_at`).
+		Suffix("RETURNING " + strings.Join(fileColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
 	}
 
-	var createdFile entity.File
-	var nullTaskID sql.NullInt64 // For nullable taskid
-
 	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("transaction start failed: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx, sqlStr, args...)
-	err = row.Scan(
-		&createdFile.ID,
-		&createdFile.Name,
-		&nullTaskID, // Scan into NullInt64
-		&createdFile.CreatedAt,
-		&createdFile.UpdatedAt,
-	)
+	createdFile, err := scanFile(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullTaskID.Valid {
-		createdFile.TaskID = &nullTaskID.Int64
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdFile.ID, nil); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 
-	return &createdFile, nil
+	return createdFile, nil
 }
 
 func (p *fileRepo) Get(ctx context.Context, params map[string]string) (*entity.File, error) {
@@ -118,9 +115,7 @@ func (p *fileRepo) Get(ctx context.Context, params map[string]string) (*entity.F
 		return nil, fmt.Errorf("at least one filter parameter is required")
 	}
 
-	builder := p.db.Sq.Builder.Select(
-		"id", "name", "taskid", "created_at", "updated_at",
-	).From(p.tableName)
+	builder := p.db.Sq.Builder.Select(fileColumns...).From(p.tableName)
 
 	for key, value := range params {
 		builder = builder.Where(squirrel.Eq{key: value})
@@ -131,25 +126,12 @@ func (p *fileRepo) Get(ctx context.Context, params map[string]string) (*entity.F
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
 	}
 
-	var file entity.File
-	var nullTaskID sql.NullInt64
-
-	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
-		&file.ID,
-		&file.Name,
-		&nullTaskID,
-		&file.CreatedAt,
-		&file.UpdatedAt,
-	)
+	file, err := scanFile(p.db.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullTaskID.Valid {
-		file.TaskID = &nullTaskID.Int64
-	}
-
-	return &file, nil
+	return file, nil
 }
 
 func (p *fileRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllFilesResponse, error) {
@@ -160,9 +142,7 @@ func (p *fileRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	}
 
 	var files entity.GetAllFilesResponse
-	baseBuilder := p.db.Sq.Builder.Select(
-		"id", "name", "taskid", "created_at", "updated_at",
-	).From(p.tableName)
+	baseBuilder := p.db.Sq.Builder.Select(fileColumns...).From(p.tableName)
 
 	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
 
@@ -196,17 +176,11 @@ func (p *fileRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	defer rows.Close()
 
 	for rows.Next() {
-		var file entity.File
-		var nullTaskID sql.NullInt64
-		if err := rows.Scan(
-			&file.ID, &file.Name, &nullTaskID, &file.CreatedAt, &file.UpdatedAt,
-		); err != nil {
+		file, err := scanFile(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
-		if nullTaskID.Valid {
-			file.TaskID = &nullTaskID.Int64
-		}
-		files.Items = append(files.Items, &file)
+		files.Items = append(files.Items, file)
 	}
 
 	// Get total count
@@ -272,6 +246,11 @@ func (p *fileRepo) Update(ctx context.Context, req *entity.UpdateFileRequest) er
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -281,6 +260,10 @@ func (p *fileRepo) Update(ctx context.Context, req *entity.UpdateFileRequest) er
 		return fmt.Errorf("no file found with ID %d", req.ID)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -307,7 +290,18 @@ func (p *fileRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("delete query build error: %w", err)
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
 	}
@@ -316,5 +310,97 @@ func (p *fileRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("no file found with ID %d", id)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
 	return nil
 }
+
+// ObjectKeys returns the MinIO keys of the files attached to a task, so the
+// objects can be removed along with the task.
+func (p *fileRepo) ObjectKeys(ctx context.Context, taskID int64) ([]string, error) {
+	var reqID = fileIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("fileRepo.ObjectKeys - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("object_key").
+		From(p.tableName).
+		Where(squirrel.Eq{"taskid": taskID}).
+		Where(squirrel.NotEq{"object_key": nil}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" object keys")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return keys, nil
+}
+
+func scanFile(row pgx.Row) (*entity.File, error) {
+	var file entity.File
+	var (
+		nullTaskID     sql.NullInt64
+		nullObjectKey  sql.NullString
+		nullSize       sql.NullInt64
+		nullMimeType   sql.NullString
+		nullUploadedBy sql.NullInt64
+	)
+
+	err := row.Scan(
+		&file.ID,
+		&file.Name,
+		&nullTaskID,
+		&nullObjectKey,
+		&nullSize,
+		&nullMimeType,
+		&nullUploadedBy,
+		&file.CreatedAt,
+		&file.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullTaskID.Valid {
+		file.TaskID = &nullTaskID.Int64
+	}
+	if nullObjectKey.Valid {
+		file.ObjectKey = &nullObjectKey.String
+	}
+	if nullSize.Valid {
+		file.Size = &nullSize.Int64
+	}
+	if nullMimeType.Valid {
+		file.MimeType = &nullMimeType.String
+	}
+	if nullUploadedBy.Valid {
+		file.UploadedBy = &nullUploadedBy.Int64
+	}
+
+	return &file, nil
+}
@@ -0,0 +1,723 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	leavesTableName = "leave_requests"
+	leavesIDKey     = "leaveID: "
+)
+
+var leaveColumns = []string{
+	"id", "user_id", "type", "start_date", "end_date", "days", "reason", "status",
+	"reviewer_id", "reviewed_at", "review_comment", "created_at", "updated_at",
+}
+
+// leaveTypes fixes the order balances are reported in.
+var leaveTypes = []entity.LeaveType{entity.LeaveTypeVacation, entity.LeaveTypeSick, entity.LeaveTypeUnpaid}
+
+// LeaveRepoInterface defines the interface for leave requests and balances.
+type LeaveRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateLeaveRequest, accrual entity.LeaveAccrual) (*entity.Leave, error)
+	Get(ctx context.Context, params map[string]string) (*entity.Leave, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllLeavesResponse, error)
+	Review(ctx context.Context, req *entity.ReviewLeaveRequest, accrual entity.LeaveAccrual) (*entity.Leave, error)
+	Cancel(ctx context.Context, id, userID int64) (*entity.Leave, error)
+	Balances(ctx context.Context, userID int64, accrual entity.LeaveAccrual) (*entity.LeaveBalancesResponse, error)
+	ApprovedDays(ctx context.Context, userID int64, from, to time.Time) (map[entity.LeaveType]int, error)
+}
+
+type leaveRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewLeaveRepo(db *postgres.Postgres, log *logger.Logger) LeaveRepoInterface {
+	return &leaveRepo{
+		tableName: leavesTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+// Create files a pending leave request. It is refused when it covers no
+// working day, overlaps another pending or approved request of the user, or
+// exceeds the available balance of its type.
+func (p *leaveRepo) Create(ctx context.Context, req *entity.CreateLeaveRequest, accrual entity.LeaveAccrual) (*entity.Leave, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.Create - %s", reqID))
+	}
+
+	start := dateOnly(req.StartDate)
+	end := dateOnly(req.EndDate)
+	if end.Before(start) {
+		return nil, fmt.Errorf("leave end date is before its start date")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Locking the user serialises concurrent requests of the same user, which
+	// keeps the overlap and balance checks below honest.
+	hiredAt, err := p.lockUser(ctx, tx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := loadShiftRules(ctx, p.db, tx, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	days := countLeaveDays(rules, start, end)
+	if days == 0 {
+		return nil, entity.ErrorLeaveNoWorkDays
+	}
+
+	overlaps, err := p.overlaps(ctx, tx, req.UserID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if overlaps {
+		return nil, entity.ErrorLeaveOverlap
+	}
+
+	if rate, limited := accrual[req.Type]; limited {
+		balance, err := p.balance(ctx, tx, req.UserID, req.Type, hiredAt, rate)
+		if err != nil {
+			return nil, err
+		}
+		if float64(days) > balance.Available {
+			return nil, entity.ErrorLeaveBalance
+		}
+	}
+
+	columns := []string{
+		"user_id", "type", "start_date", "end_date", "days", "status",
+		"created_at", "updated_at",
+	}
+	values := []interface{}{
+		req.UserID, req.Type, start, end, days, entity.LeaveStatusPending,
+		time.Now().UTC(), time.Now().UTC(),
+	}
+
+	if req.Reason != nil {
+		columns = append(columns, "reason")
+		values = append(values, *req.Reason)
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns(columns...).
+		Values(values...).
+		Suffix("RETURNING " + strings.Join(leaveColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	leave, err := scanLeave(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, leave.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return leave, nil
+}
+
+func (p *leaveRepo) Get(ctx context.Context, params map[string]string) (*entity.Leave, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(leaveColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	leave, err := scanLeave(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return leave, nil
+}
+
+func (p *leaveRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllLeavesResponse, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.List - %s", reqID))
+	}
+
+	var leaves entity.GetAllLeavesResponse
+	baseBuilder := p.db.Sq.Builder.Select(leaveColumns...).From(p.tableName)
+
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	// Order, Limit, Offset for data query
+	baseBuilder = baseBuilder.OrderBy("start_date DESC", "id DESC").Limit(limit).Offset(offset)
+
+	sqlStr, args, err := baseBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		leave, err := scanLeave(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		leaves.Items = append(leaves.Items, leave)
+	}
+
+	// Get total count
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	var totalCount uint64
+	err = p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	leaves.Total = totalCount
+	return &leaves, nil
+}
+
+// Review approves or rejects a pending request. Approval re-checks the
+// balance and removes absence markers the nightly job may already have
+// written for the covered days.
+func (p *leaveRepo) Review(ctx context.Context, req *entity.ReviewLeaveRequest, accrual entity.LeaveAccrual) (*entity.Leave, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.Review - %s", reqID))
+	}
+
+	if req.Status != entity.LeaveStatusApproved && req.Status != entity.LeaveStatusRejected {
+		return nil, fmt.Errorf("invalid review status %q", req.Status)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	leave, err := p.lockLeave(ctx, tx, squirrel.Eq{"id": req.ID})
+	if err != nil {
+		return nil, err
+	}
+	if leave.Status != entity.LeaveStatusPending {
+		return nil, entity.ErrorLeaveNotPending
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, leave.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Status == entity.LeaveStatusApproved {
+		hiredAt, err := p.lockUser(ctx, tx, leave.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if rate, limited := accrual[leave.Type]; limited {
+			balance, err := p.balance(ctx, tx, leave.UserID, leave.Type, hiredAt, rate)
+			if err != nil {
+				return nil, err
+			}
+			// The request itself is counted as pending, so it is given back first
+			if float64(leave.Days) > balance.Available+float64(leave.Days) {
+				return nil, entity.ErrorLeaveBalance
+			}
+		}
+
+		if err := p.clearAbsences(ctx, tx, leave); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	clauses := map[string]interface{}{
+		"status":      req.Status,
+		"reviewer_id": req.ReviewerID,
+		"reviewed_at": now,
+		"updated_at":  now,
+	}
+	if req.Comment != nil {
+		clauses["review_comment"] = *req.Comment
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(squirrel.Eq{"id": leave.ID}).
+		Suffix("RETURNING " + strings.Join(leaveColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" review")
+	}
+
+	reviewed, err := scanLeave(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, leave.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return reviewed, nil
+}
+
+// Cancel withdraws a pending request of the given user.
+func (p *leaveRepo) Cancel(ctx context.Context, id, userID int64) (*entity.Leave, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.Cancel - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	leave, err := p.lockLeave(ctx, tx, squirrel.Eq{"id": id, "user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if leave.Status != entity.LeaveStatusPending {
+		return nil, entity.ErrorLeaveNotPending
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, leave.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("status", entity.LeaveStatusCancelled).
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": leave.ID}).
+		Suffix("RETURNING " + strings.Join(leaveColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" cancel")
+	}
+
+	cancelled, err := scanLeave(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, leave.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+// Balances reports accrued, used, pending and available days of every
+// leave type limited by the accrual policy.
+func (p *leaveRepo) Balances(ctx context.Context, userID int64, accrual entity.LeaveAccrual) (*entity.LeaveBalancesResponse, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.Balances - %s", reqID))
+	}
+
+	hiredAt, err := p.hiredAt(ctx, p.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := entity.LeaveBalancesResponse{UserID: userID}
+	for _, leaveType := range leaveTypes {
+		rate, limited := accrual[leaveType]
+		if !limited {
+			continue
+		}
+
+		balance, err := p.balance(ctx, p.db, userID, leaveType, hiredAt, rate)
+		if err != nil {
+			return nil, err
+		}
+		response.Items = append(response.Items, balance)
+	}
+
+	return &response, nil
+}
+
+// ApprovedDays counts the working days of approved leave per type that fall
+// between from and to (inclusive).
+func (p *leaveRepo) ApprovedDays(ctx context.Context, userID int64, from, to time.Time) (map[entity.LeaveType]int, error) {
+	var reqID = leavesIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("leaveRepo.ApprovedDays - %s", reqID))
+	}
+
+	rules, err := loadShiftRules(ctx, p.db, p.db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := loadApprovedLeave(ctx, p.db, p.db, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[entity.LeaveType]int)
+	for date, leaveType := range leaves {
+		day, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, err
+		}
+		if isWorkingDay(rules, day) {
+			days[leaveType]++
+		}
+	}
+
+	return days, nil
+}
+
+// clearAbsences deletes the absence markers the nightly job left on the days
+// of an approved leave.
+func (p *leaveRepo) clearAbsences(ctx context.Context, tx pgx.Tx, leave *entity.Leave) error {
+	absentSqlStr, absentArgs, err := p.db.Sq.Builder.
+		Select("id").
+		From(attendanceTableName).
+		Where(squirrel.Eq{"user_id": leave.UserID, "status": entity.AttendanceStatusAbsent}).
+		Where(squirrel.GtOrEq{"date": leave.StartDate}).
+		Where(squirrel.LtOrEq{"date": leave.EndDate}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, attendanceTableName+" absences")
+	}
+
+	rows, err := tx.Query(ctx, absentSqlStr, absentArgs...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	for _, id := range ids {
+		before, err := snapshot(ctx, p.db, tx, attendanceTableName, id)
+		if err != nil {
+			return err
+		}
+
+		deleteSqlStr, deleteArgs, err := p.db.Sq.Builder.
+			Delete(attendanceTableName).
+			Where(squirrel.Eq{"id": id}).
+			ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, attendanceTableName+" clear absences")
+		}
+		if _, err := tx.Exec(ctx, deleteSqlStr, deleteArgs...); err != nil {
+			return p.db.Error(err)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, attendanceTableName, id, before); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// lockUser locks the user row for the rest of the transaction and returns
+// when the user was hired, which is when leave starts accruing.
+func (p *leaveRepo) lockUser(ctx context.Context, tx pgx.Tx, userID int64) (time.Time, error) {
+	var hiredAt time.Time
+	err := tx.QueryRow(ctx, "SELECT created_at FROM "+userTableName+" WHERE id = $1 FOR UPDATE", userID).Scan(&hiredAt)
+	if err != nil {
+		return hiredAt, p.db.Error(err)
+	}
+
+	return hiredAt, nil
+}
+
+func (p *leaveRepo) hiredAt(ctx context.Context, q rowQuerier, userID int64) (time.Time, error) {
+	var hiredAt time.Time
+	err := q.QueryRow(ctx, "SELECT created_at FROM "+userTableName+" WHERE id = $1", userID).Scan(&hiredAt)
+	if err != nil {
+		return hiredAt, p.db.Error(err)
+	}
+
+	return hiredAt, nil
+}
+
+func (p *leaveRepo) lockLeave(ctx context.Context, tx pgx.Tx, where squirrel.Eq) (*entity.Leave, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(leaveColumns...).
+		From(p.tableName).
+		Where(where).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" lock")
+	}
+
+	leave, err := scanLeave(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return leave, nil
+}
+
+func (p *leaveRepo) overlaps(ctx context.Context, tx pgx.Tx, userID int64, start, end time.Time) (bool, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("COUNT(*)").
+		From(p.tableName).
+		Where(squirrel.Eq{
+			"user_id": userID,
+			"status":  []entity.LeaveStatus{entity.LeaveStatusPending, entity.LeaveStatusApproved},
+		}).
+		Where(squirrel.LtOrEq{"start_date": end}).
+		Where(squirrel.GtOrEq{"end_date": start}).
+		ToSql()
+	if err != nil {
+		return false, p.db.ErrSQLBuild(err, p.tableName+" overlap")
+	}
+
+	var count uint64
+	if err := tx.QueryRow(ctx, sqlStr, args...).Scan(&count); err != nil {
+		return false, p.db.Error(err)
+	}
+
+	return count > 0, nil
+}
+
+// balance accrues rate days at the start of every month of employment,
+// including the month of hire.
+func (p *leaveRepo) balance(ctx context.Context, q rowQuerier, userID int64, leaveType entity.LeaveType, hiredAt time.Time, rate float64) (*entity.LeaveBalance, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(
+			"COALESCE(SUM(days) FILTER (WHERE status = 'approved'), 0)",
+			"COALESCE(SUM(days) FILTER (WHERE status = 'pending'), 0)",
+		).
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID, "type": leaveType}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" balance")
+	}
+
+	var used, pending int64
+	if err := q.QueryRow(ctx, sqlStr, args...).Scan(&used, &pending); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	now := time.Now().UTC()
+	months := (now.Year()-hiredAt.Year())*12 + int(now.Month()) - int(hiredAt.Month()) + 1
+	if months < 0 {
+		months = 0
+	}
+
+	balance := entity.LeaveBalance{
+		Type:    leaveType,
+		Accrued: float64(months) * rate,
+		Used:    float64(used),
+		Pending: float64(pending),
+	}
+	balance.Available = balance.Accrued - balance.Used - balance.Pending
+
+	return &balance, nil
+}
+
+// loadApprovedLeave returns the leave type of every date between from and to
+// (inclusive) covered by approved leave of the user, keyed by YYYY-MM-DD.
+func loadApprovedLeave(ctx context.Context, db *postgres.Postgres, q querier, userID int64, from, to time.Time) (map[string]entity.LeaveType, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Select("type", "start_date", "end_date").
+		From(leavesTableName).
+		Where(squirrel.Eq{"user_id": userID, "status": entity.LeaveStatusApproved}).
+		Where(squirrel.LtOrEq{"start_date": to}).
+		Where(squirrel.GtOrEq{"end_date": from}).
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, leavesTableName+" approved")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	from, to = dateOnly(from), dateOnly(to)
+	leaves := make(map[string]entity.LeaveType)
+	for rows.Next() {
+		var (
+			leaveType  entity.LeaveType
+			start, end time.Time
+		)
+		if err := rows.Scan(&leaveType, &start, &end); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		for date := dateOnly(start); !date.After(dateOnly(end)); date = date.AddDate(0, 0, 1) {
+			if !date.Before(from) && !date.After(to) {
+				leaves[date.Format(time.DateOnly)] = leaveType
+			}
+		}
+	}
+
+	return leaves, rows.Err()
+}
+
+// isWorkingDay tells whether the user is expected to work on date: a
+// scheduled weekday, or Monday to Friday for users without a schedule.
+func isWorkingDay(rules map[time.Weekday]*entity.ShiftRule, date time.Time) bool {
+	if len(rules) > 0 {
+		_, scheduled := rules[date.Weekday()]
+		return scheduled
+	}
+
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+func countLeaveDays(rules map[time.Weekday]*entity.ShiftRule, start, end time.Time) int {
+	var days int
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		if isWorkingDay(rules, date) {
+			days++
+		}
+	}
+
+	return days
+}
+
+// dateOnly drops the clock and zone of t, keeping its calendar date.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func scanLeave(row pgx.Row) (*entity.Leave, error) {
+	var leave entity.Leave
+	var (
+		nullReason        sql.NullString
+		nullReviewerID    sql.NullInt64
+		nullReviewedAt    sql.NullTime
+		nullReviewComment sql.NullString
+	)
+
+	err := row.Scan(
+		&leave.ID,
+		&leave.UserID,
+		&leave.Type,
+		&leave.StartDate,
+		&leave.EndDate,
+		&leave.Days,
+		&nullReason,
+		&leave.Status,
+		&nullReviewerID,
+		&nullReviewedAt,
+		&nullReviewComment,
+		&leave.CreatedAt,
+		&leave.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullReason.Valid {
+		leave.Reason = &nullReason.String
+	}
+	if nullReviewerID.Valid {
+		leave.ReviewerID = &nullReviewerID.Int64
+	}
+	if nullReviewedAt.Valid {
+		leave.ReviewedAt = &nullReviewedAt.Time
+	}
+	if nullReviewComment.Valid {
+		leave.ReviewComment = &nullReviewComment.String
+	}
+
+	return &leave, nil
+}
@@ -25,6 +25,9 @@ type NotificationRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllNotificationsResponse, error)
 	Update(ctx context.Context, req *entity.UpdateNotificationRequest) error
 	Delete(ctx context.Context, id int64) error
+	Broadcast(ctx context.Context, req *entity.BroadcastNotificationRequest) (int64, error)
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	CountUnread(ctx context.Context, userID int64) (uint64, error)
 }
 
 type notificationRepo struct {
@@ -80,6 +83,10 @@ func (p *notificationRepo) Create(ctx context.Context, req *entity.CreateNotific
 		return nil, p.db.Error(err)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdNotification.ID, nil); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
@@ -114,13 +121,13 @@ func (p *notificationRepo) Get(ctx context.Context, params map[string]string) (*
 	var notification entity.Notification
 
 	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
-		notification.ID,
-		notification.UserID,
-		notification.Message,
-		notification.Type,
-		notification.Read,
-		notification.CreatedAt,
-		notification.UpdatedAt,
+		&notification.ID,
+		&notification.UserID,
+		&notification.Message,
+		&notification.Type,
+		&notification.Read,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
 	)
 	if err != nil {
 		return nil, p.db.Error(err)
@@ -173,9 +180,9 @@ func (p *notificationRepo) List(ctx context.Context, limit, offset uint64, filte
 	for rows.Next() {
 		var notification entity.Notification
 		if err := rows.Scan(
-			notification.ID, notification.UserID, notification.Message,
-			notification.Type, notification.Read, notification.CreatedAt,
-			notification.UpdatedAt,
+			&notification.ID, &notification.UserID, &notification.Message,
+			&notification.Type, &notification.Read, &notification.CreatedAt,
+			&notification.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
@@ -242,6 +249,11 @@ func (p *notificationRepo) Update(ctx context.Context, req *entity.UpdateNotific
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -251,6 +263,10 @@ func (p *notificationRepo) Update(ctx context.Context, req *entity.UpdateNotific
 		return fmt.Errorf("no notification found with ID %d", req.ID)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -277,7 +293,18 @@ func (p *notificationRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("delete query build error: %w", err)
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
 	}
@@ -286,5 +313,196 @@ func (p *notificationRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("no notification found with ID %d", id)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
 	return nil
 }
+
+// Broadcast inserts the same notification for every targeted user in a single statement.
+// Users are selected by ID and/or role; with neither set every user is targeted.
+func (p *notificationRepo) Broadcast(ctx context.Context, req *entity.BroadcastNotificationRequest) (int64, error) {
+	var reqID = notificationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("notificationRepo.Broadcast - %s", reqID))
+	}
+
+	if req == nil || req.Message == "" {
+		return 0, fmt.Errorf("notification message is required")
+	}
+
+	now := time.Now().UTC()
+	recipients := p.db.Sq.Builder.
+		Select("id").
+		Column("?", req.Message).
+		Column("?", req.Type).
+		Column("false").
+		Column("?", now).
+		Column("?", now).
+		From(userTableName).
+		Where(notDeleted)
+
+	if len(req.UserIDs) > 0 {
+		recipients = recipients.Where(squirrel.Eq{"id": req.UserIDs})
+	}
+	if req.Role != nil {
+		recipients = recipients.Where(squirrel.Eq{"role": *req.Role})
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("user_id", "message", "type", "read", "created_at", "updated_at").
+		Select(recipients).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, p.tableName+" broadcast")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	ids, err := p.collectIDs(ctx, tx, sqlStr, args)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, id, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many changed.
+func (p *notificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	var reqID = notificationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("notificationRepo.MarkAllRead - %s", reqID))
+	}
+
+	if userID == 0 {
+		return 0, fmt.Errorf("user ID is required")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	unreadSqlStr, unreadArgs, err := p.db.Sq.Builder.
+		Select("id").
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID, "read": false}).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, p.tableName+" unread")
+	}
+
+	ids, err := p.collectIDs(ctx, tx, unreadSqlStr, unreadArgs)
+	if err != nil {
+		return 0, err
+	}
+
+	befores := make(map[int64][]byte, len(ids))
+	for _, id := range ids {
+		before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+		if err != nil {
+			return 0, err
+		}
+		befores[id] = before
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("read", true).
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, p.tableName+" mark all read")
+	}
+
+	if _, err := tx.Exec(ctx, sqlStr, args...); err != nil {
+		return 0, p.db.Error(err)
+	}
+
+	for _, id := range ids {
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, id, befores[id]); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return int64(len(ids)), nil
+}
+
+// collectIDs runs sqlStr in tx and returns the ids it yields.
+func (p *notificationRepo) collectIDs(ctx context.Context, tx pgx.Tx, sqlStr string, args []interface{}) ([]int64, error) {
+	rows, err := tx.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return ids, nil
+}
+
+// CountUnread returns the number of unread notifications of the user.
+func (p *notificationRepo) CountUnread(ctx context.Context, userID int64) (uint64, error) {
+	var reqID = notificationIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("notificationRepo.CountUnread - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("COUNT(*)").
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID, "read": false}).
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, p.tableName+" count unread")
+	}
+
+	var count uint64
+	if err := p.db.QueryRow(ctx, sqlStr, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count query error: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	payoutCardTableName = "payout_cards"
+	payoutIDKey         = "payoutID: "
+
+	// payoutStaleAfter is how long a payout may stay processing before it is
+	// taken for lost and may be claimed again. Its key does not change, so
+	// the provider still pays it at most once.
+	payoutStaleAfter = 10 * time.Minute
+)
+
+// payoutSources describes the tables payouts are made from.
+var payoutSources = map[entity.PayoutKind]struct {
+	table     string
+	dateCol   string // Date the amount is converted to UZS at
+	payable   string // Status the record must have to be paid out
+	paidByRun bool   // Whether a payroll run that includes the record pays it
+}{
+	entity.PayoutKindSalary: {table: salaryTableName, dateCol: "pay_date", payable: string(entity.SalaryStatusPaid)},
+	entity.PayoutKindBonus:  {table: bonusesTableName, dateCol: "created_at", payable: string(entity.BonusStatusApproved), paidByRun: true},
+}
+
+// PayoutRepoInterface defines the interface for employees' payout cards and
+// the payout state of salaries and bonuses.
+type PayoutRepoInterface interface {
+	SetCard(ctx context.Context, userID int64, card string) (*entity.PayoutCard, error)
+	GetCard(ctx context.Context, userID int64) (*entity.PayoutCard, error)
+	DeleteCard(ctx context.Context, userID int64) error
+	Claim(ctx context.Context, kind entity.PayoutKind, id int64) (*entity.PayoutOrder, error)
+	Finish(ctx context.Context, order *entity.PayoutOrder, status entity.PayoutStatus, transactionID, errMsg string) (*entity.Payout, error)
+}
+
+type payoutRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewPayoutRepo(db *postgres.Postgres, log *logger.Logger) PayoutRepoInterface {
+	return &payoutRepo{
+		tableName: payoutCardTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+// SetCard stores the card the user is paid to, replacing the previous one.
+func (p *payoutRepo) SetCard(ctx context.Context, userID int64, card string) (*entity.PayoutCard, error) {
+	var reqID = payoutIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payoutRepo.SetCard - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("user_id", "card", "masked", "created_at", "updated_at").
+		Values(userID, card, maskCard(card), time.Now().UTC(), time.Now().UTC()).
+		Suffix(
+			"ON CONFLICT (user_id) DO UPDATE SET card = EXCLUDED.card, masked = EXCLUDED.masked, updated_at = EXCLUDED.updated_at " +
+				"RETURNING user_id, card, masked, created_at, updated_at",
+		).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" set")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var payoutCard entity.PayoutCard
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(
+		&payoutCard.UserID, &payoutCard.Card, &payoutCard.Masked, &payoutCard.CreatedAt, &payoutCard.UpdatedAt,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	action := entity.AuditActionCreate
+	if before != nil {
+		action = entity.AuditActionUpdate
+	}
+	if err := recordAudit(ctx, p.db, tx, action, p.tableName, userID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &payoutCard, nil
+}
+
+// GetCard returns the card the user is paid to, or pgx.ErrNoRows when they
+// have none.
+func (p *payoutRepo) GetCard(ctx context.Context, userID int64) (*entity.PayoutCard, error) {
+	var reqID = payoutIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payoutRepo.GetCard - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("user_id", "card", "masked", "created_at", "updated_at").
+		From(p.tableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var payoutCard entity.PayoutCard
+	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
+		&payoutCard.UserID, &payoutCard.Card, &payoutCard.Masked, &payoutCard.CreatedAt, &payoutCard.UpdatedAt,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &payoutCard, nil
+}
+
+func (p *payoutRepo) DeleteCard(ctx context.Context, userID int64) error {
+	var reqID = payoutIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payoutRepo.DeleteCard - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, userID)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no payout card found for user %d", userID)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, userID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// Claim marks a salary or bonus as being paid out and returns what to pay
+// whom. Only paid salaries and approved bonuses that were not paid out yet can
+// be claimed; others fail with entity.ErrorPayoutNotAllowed. It fails with
+// entity.ErrorPayoutInProgress when another payout of the record is under way,
+// entity.ErrorPayoutNoCard when the employee has no card and
+// entity.ErrorNoExchangeRate when the amount cannot be converted to UZS.
+func (p *payoutRepo) Claim(ctx context.Context, kind entity.PayoutKind, id int64) (*entity.PayoutOrder, error) {
+	var reqID = payoutIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payoutRepo.Claim - %s", reqID))
+	}
+
+	source, ok := payoutSources[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown payout kind %q", kind)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(
+			"user_id", "amount", "currency", source.dateCol, "status::text",
+			"payout_status", "payout_key", "payout_transaction_id", "payout_updated_at", "payroll_run_id",
+		).
+		From(source.table).
+		Where(squirrel.Eq{"id": id}).
+		Where(notDeleted).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, source.table+" claim payout")
+	}
+
+	var (
+		order            = entity.PayoutOrder{Kind: kind, RefID: id}
+		date             time.Time
+		status           string
+		nullPayoutStatus sql.NullString
+		nullPayoutKey    sql.NullString
+		nullTxID         sql.NullString
+		nullUpdatedAt    sql.NullTime
+		nullPayrollRunID sql.NullInt64
+	)
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(
+		&order.UserID, &order.Amount, &order.Currency, &date, &status,
+		&nullPayoutStatus, &nullPayoutKey, &nullTxID, &nullUpdatedAt, &nullPayrollRunID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no %s found with ID %d", kind, id)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	if status != source.payable {
+		return nil, fmt.Errorf("%w: %s %d is %s, not %s", entity.ErrorPayoutNotAllowed, kind, id, status, source.payable)
+	}
+	if source.paidByRun && nullPayrollRunID.Valid {
+		return nil, fmt.Errorf("%w: %s %d was already paid with payroll run %d", entity.ErrorPayoutNotAllowed, kind, id, nullPayrollRunID.Int64)
+	}
+	switch entity.PayoutStatus(nullPayoutStatus.String) {
+	case entity.PayoutStatusSucceeded:
+		return nil, fmt.Errorf("%w: %s %d was already paid out in transaction %s", entity.ErrorPayoutNotAllowed, kind, id, nullTxID.String)
+	case entity.PayoutStatusProcessing:
+		if nullUpdatedAt.Valid && time.Since(nullUpdatedAt.Time) < payoutStaleAfter {
+			return nil, entity.ErrorPayoutInProgress
+		}
+	}
+
+	err = tx.QueryRow(ctx, "SELECT card FROM "+p.tableName+" WHERE user_id = $1", order.UserID).Scan(&order.Card)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, entity.ErrorPayoutNoCard
+		}
+		return nil, p.db.Error(err)
+	}
+
+	rates, err := loadRates(ctx, p.db, tx, date)
+	if err != nil {
+		return nil, err
+	}
+	amountUZS, err := rates.convert(order.Amount, order.Currency, entity.CurrencyUZS)
+	if err != nil {
+		return nil, err
+	}
+	order.AmountUZS = roundMoney(amountUZS)
+
+	order.Key = nullPayoutKey.String
+	if order.Key == "" {
+		order.Key = fmt.Sprintf("%s-%d", kind, id)
+	}
+
+	before, err := snapshot(ctx, p.db, tx, source.table, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err = p.db.Sq.Builder.
+		Update(source.table).
+		Set("payout_status", entity.PayoutStatusProcessing).
+		Set("payout_key", order.Key).
+		Set("payout_error", nil).
+		Set("payout_updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, source.table+" claim payout")
+	}
+
+	if _, err := tx.Exec(ctx, sqlStr, args...); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, source.table, id, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &order, nil
+}
+
+// Finish records the outcome of a claimed payout on its salary or bonus.
+func (p *payoutRepo) Finish(ctx context.Context, order *entity.PayoutOrder, status entity.PayoutStatus, transactionID, errMsg string) (*entity.Payout, error) {
+	var reqID = payoutIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payoutRepo.Finish - %s", reqID))
+	}
+
+	source, ok := payoutSources[order.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown payout kind %q", order.Kind)
+	}
+
+	payout := entity.Payout{
+		Kind:      order.Kind,
+		RefID:     order.RefID,
+		Key:       order.Key,
+		Status:    status,
+		AmountUZS: order.AmountUZS,
+	}
+	clauses := map[string]interface{}{
+		"payout_status":     status,
+		"payout_error":      nil,
+		"payout_updated_at": time.Now().UTC(),
+	}
+	if transactionID != "" {
+		clauses["payout_transaction_id"] = transactionID
+		payout.TransactionID = &transactionID
+	}
+	if errMsg != "" {
+		clauses["payout_error"] = errMsg
+		payout.Error = &errMsg
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, source.table, order.RefID)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(source.table).
+		SetMap(clauses).
+		Where(squirrel.Eq{"id": order.RefID, "payout_key": order.Key}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, source.table+" finish payout")
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("no %s found with ID %d", order.Kind, order.RefID)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, source.table, order.RefID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &payout, nil
+}
+
+// maskCard hides all but the first six and last four digits of a card number,
+// and all but the last four characters of a token.
+func maskCard(card string) string {
+	if len(card) == 16 {
+		return card[:6] + "******" + card[12:]
+	}
+	if len(card) <= 4 {
+		return card
+	}
+	return "****" + card[len(card)-4:]
+}
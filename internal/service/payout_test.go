@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+)
+
+func TestPayoutClaimStale(t *testing.T) {
+	db := testDB(t)
+	repo := NewPayoutRepo(db, testLogger(t))
+	ctx := context.Background()
+
+	admin := createTestUser(t, db, "admin", entity.UserRoleAdmin)
+	employee := createTestUser(t, db, "employee", entity.UserRoleUser)
+	payDate := time.Date(2026, 9, 30, 0, 0, 0, 0, time.UTC)
+	createTestRate(t, db, entity.CurrencyUSD, entity.CurrencyUZS, payDate, 12650)
+	salaryID := createTestSalary(t, db, employee, admin, 1000.5, entity.CurrencyUSD, payDate, entity.SalaryStatusPaid)
+
+	if _, err := repo.SetCard(ctx, employee, "8600123412341234"); err != nil {
+		t.Fatalf("SetCard: %v", err)
+	}
+
+	first, err := repo.Claim(ctx, entity.PayoutKindSalary, salaryID)
+	if err != nil {
+		t.Fatalf("first Claim: %v", err)
+	}
+	if first.AmountUZS != 12656325 {
+		t.Errorf("AmountUZS = %.2f, want 12656325.00", first.AmountUZS)
+	}
+
+	if _, err := repo.Claim(ctx, entity.PayoutKindSalary, salaryID); !errors.Is(err, entity.ErrorPayoutInProgress) {
+		t.Fatalf("Claim while processing error = %v, want %v", err, entity.ErrorPayoutInProgress)
+	}
+
+	// A claim that never finished is taken for lost after payoutStaleAfter
+	_, err = db.Exec(ctx, "UPDATE "+salaryTableName+" SET payout_updated_at = $1 WHERE id = $2",
+		time.Now().Add(-payoutStaleAfter-time.Minute), salaryID)
+	if err != nil {
+		t.Fatalf("age claim: %v", err)
+	}
+
+	second, err := repo.Claim(ctx, entity.PayoutKindSalary, salaryID)
+	if err != nil {
+		t.Fatalf("stale Claim: %v", err)
+	}
+	if second.Key != first.Key {
+		t.Errorf("re-claimed key = %q, want %q so the payout is not made twice", second.Key, first.Key)
+	}
+
+	if _, err := repo.Finish(ctx, second, entity.PayoutStatusSucceeded, "tx-1", ""); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if _, err := repo.Claim(ctx, entity.PayoutKindSalary, salaryID); !errors.Is(err, entity.ErrorPayoutNotAllowed) {
+		t.Errorf("Claim after success error = %v, want %v", err, entity.ErrorPayoutNotAllowed)
+	}
+}
@@ -0,0 +1,640 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	payrollRunTableName = "payroll_runs"
+	payrollIDKey        = "payrollID: "
+)
+
+var payrollRunColumns = []string{
+	"id", "admin_id", "period_start", "period_end", "pay_date", "currency", "total", "created_at",
+}
+
+// PayrollRepoInterface defines the interface for computing and committing payroll runs.
+type PayrollRepoInterface interface {
+	Preview(ctx context.Context, req *entity.PayrollRunRequest) (*entity.PayrollRun, error)
+	Run(ctx context.Context, req *entity.PayrollRunRequest) (*entity.PayrollRun, error)
+	Get(ctx context.Context, id int64) (*entity.PayrollRun, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllPayrollRunsResponse, error)
+}
+
+type payrollRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewPayrollRepo(db *postgres.Postgres, log *logger.Logger) PayrollRepoInterface {
+	return &payrollRepo{
+		tableName: payrollRunTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+// payrollQuerier is satisfied by both the pool and a transaction.
+type payrollQuerier interface {
+	querier
+	rowQuerier
+}
+
+// Preview computes the pay of every employee of the request without
+// creating anything, so admins can check the figures before running it.
+func (p *payrollRepo) Preview(ctx context.Context, req *entity.PayrollRunRequest) (*entity.PayrollRun, error) {
+	var reqID = payrollIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payrollRepo.Preview - %s", reqID))
+	}
+
+	return p.compute(ctx, p.db, req)
+}
+
+// Run computes the payroll like Preview and creates a pending salary for
+// every employee with something to pay, all in one transaction. Employees
+// already paid by a run overlapping the period are skipped, and the bonuses
+// a salary pays are marked with the run so they are not paid again.
+func (p *payrollRepo) Run(ctx context.Context, req *entity.PayrollRunRequest) (*entity.PayrollRun, error) {
+	var reqID = payrollIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payrollRepo.Run - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Concurrent runs would both see the period as unpaid, so they take turns
+	if _, err := tx.Exec(ctx, "LOCK TABLE "+p.tableName+" IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	run, err := p.compute(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if run.Total <= 0 {
+		return nil, entity.ErrorPayrollEmpty
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("admin_id", "period_start", "period_end", "pay_date", "currency", "total", "created_at").
+		Values(run.AdminID, run.From, run.To, run.PayDate, run.Currency, run.Total, time.Now().UTC()).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	if err := tx.QueryRow(ctx, sqlStr, args...).Scan(&run.ID, &run.CreatedAt); err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, run.ID, nil); err != nil {
+		return nil, err
+	}
+
+	for _, line := range run.Lines {
+		if line.Skipped != "" {
+			continue
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Insert(salaryTableName).
+			Columns(
+				"amount", "user_id", "admin_id", "pay_date", "currency", "status", "payroll_run_id",
+				"base_pay", "bonus_total", "deduction_total",
+				"created_at", "updated_at",
+			).
+			Values(
+				line.Amount, line.UserID, run.AdminID, run.PayDate, run.Currency, entity.SalaryStatusPending, run.ID,
+				line.BasePay, line.Bonuses, line.Deductions,
+				time.Now().UTC(), time.Now().UTC(),
+			).
+			Suffix("RETURNING id").
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, salaryTableName+" create")
+		}
+
+		var salaryID int64
+		if err := tx.QueryRow(ctx, sqlStr, args...).Scan(&salaryID); err != nil {
+			return nil, p.db.Error(err)
+		}
+		line.SalaryID = &salaryID
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, salaryTableName, salaryID, nil); err != nil {
+			return nil, err
+		}
+
+		if err := p.markBonuses(ctx, tx, line, run); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return run, nil
+}
+
+func (p *payrollRepo) Get(ctx context.Context, id int64) (*entity.PayrollRun, error) {
+	var reqID = payrollIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payrollRepo.Get - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(payrollRunColumns...).
+		From(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	run, err := scanPayrollRun(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return run, nil
+}
+
+func (p *payrollRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllPayrollRunsResponse, error) {
+	var reqID = payrollIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("payrollRepo.List - %s", reqID))
+	}
+
+	var runs entity.GetAllPayrollRunsResponse
+	baseBuilder := p.db.Sq.Builder.Select(payrollRunColumns...).From(p.tableName)
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := baseBuilder.OrderBy("created_at DESC").Limit(limit).Offset(offset).ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		run, err := scanPayrollRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		runs.Items = append(runs.Items, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&runs.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &runs, nil
+}
+
+// compute works out the payroll line of every employee of the request. Super
+// admins are not on the payroll, working days before an employee was hired
+// are not counted, and compensation, bonuses and deductions in other
+// currencies are converted at the latest rates known at the end of the
+// period. Employees with an amount that cannot be converted are left out of
+// the run.
+func (p *payrollRepo) compute(ctx context.Context, q payrollQuerier, req *entity.PayrollRunRequest) (*entity.PayrollRun, error) {
+	run := entity.PayrollRun{
+		AdminID:  req.AdminID,
+		From:     dateOnly(req.From),
+		To:       dateOnly(req.To),
+		PayDate:  dateOnly(req.PayDate),
+		Currency: req.Currency,
+	}
+
+	builder := p.db.Sq.Builder.
+		Select("id", "first_name", "last_name", "created_at").
+		From(userTableName).
+		Where(squirrel.NotEq{"role": entity.UserRoleSuperAdmin}).
+		Where(notDeleted).
+		OrderBy("id")
+	if len(req.UserIDs) > 0 {
+		builder = builder.Where(squirrel.Eq{"id": req.UserIDs})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, userTableName+" payroll")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	hiredAt := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			line  entity.PayrollLine
+			hired time.Time
+		)
+		if err := rows.Scan(&line.UserID, &line.FirstName, &line.LastName, &hired); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		hiredAt[line.UserID] = dateOnly(hired)
+		run.Lines = append(run.Lines, &line)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	paid, err := p.paidUsers(ctx, q, run.From, run.To)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := loadRates(ctx, p.db, q, run.To)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range run.Lines {
+		// An explicit rate wins over the compensation profile in effect at
+		// the end of the period, which wins over the run's base rate
+		line.RateType = entity.RateTypeMonthly
+		line.BaseRate = req.BaseRate
+		var rateErr error
+		if rate, ok := req.Rates[line.UserID]; ok {
+			line.BaseRate = rate
+		} else {
+			compensation, err := loadCompensation(ctx, p.db, q, line.UserID, run.To)
+			if err != nil {
+				return nil, err
+			}
+			if compensation != nil {
+				line.RateType = compensation.RateType
+				line.BaseRate, rateErr = rates.convert(compensation.Amount, compensation.Currency, run.Currency)
+			}
+		}
+
+		err := p.computeLine(ctx, q, line, hiredAt[line.UserID], &run, rates)
+		if err != nil && !errors.Is(err, entity.ErrorNoExchangeRate) {
+			return nil, err
+		}
+		if rateErr == nil {
+			rateErr = err
+		}
+
+		switch {
+		case rateErr != nil:
+			line.Skipped = rateErr.Error()
+		case paid[line.UserID]:
+			line.Skipped = "already paid by a payroll run overlapping the period"
+		case line.Amount <= 0:
+			line.Skipped = "nothing to pay"
+		default:
+			run.Total += line.Amount
+		}
+	}
+
+	run.Total = roundMoney(run.Total)
+
+	return &run, nil
+}
+
+// computeLine fills in the attendance, leave, bonuses and deductions of line
+// over the period of run and prices them. It returns
+// entity.ErrorNoExchangeRate when a bonus or deduction cannot be converted into
+// the run's currency.
+func (p *payrollRepo) computeLine(ctx context.Context, q payrollQuerier, line *entity.PayrollLine, hiredAt time.Time, run *entity.PayrollRun, rates rateTable) error {
+	rules, err := loadShiftRules(ctx, p.db, q, line.UserID)
+	if err != nil {
+		return err
+	}
+
+	leaves, err := loadApprovedLeave(ctx, p.db, q, line.UserID, run.From, run.To)
+	if err != nil {
+		return err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("date", "intime", "outtime").
+		From(attendanceTableName).
+		Where(squirrel.Eq{"user_id": line.UserID}).
+		Where(squirrel.NotEq{"status": entity.AttendanceStatusAbsent}).
+		Where(squirrel.GtOrEq{"date": run.From}).
+		Where(squirrel.LtOrEq{"date": run.To}).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, attendanceTableName+" payroll")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return fmt.Errorf("query error: %w", err)
+	}
+
+	attended := make(map[string]bool)
+	var worked time.Duration
+	for rows.Next() {
+		var (
+			date, inTime time.Time
+			outTime      *time.Time
+		)
+		if err := rows.Scan(&date, &inTime, &outTime); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan error: %w", err)
+		}
+		attended[date.Format(time.DateOnly)] = true
+		if outTime != nil {
+			worked += outTime.Sub(inTime)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+	line.WorkedHours = hours(worked)
+
+	for date := run.From; !date.After(run.To); date = date.AddDate(0, 0, 1) {
+		if date.Before(hiredAt) || !isWorkingDay(rules, date) {
+			continue
+		}
+		line.WorkingDays++
+
+		key := date.Format(time.DateOnly)
+		switch leaveType, onLeave := leaves[key]; {
+		case attended[key]:
+			line.AttendedDays++
+		case onLeave && leaveType != entity.LeaveTypeUnpaid:
+			line.PaidLeaveDays++
+		default:
+			line.UnpaidDays++
+		}
+	}
+
+	switch {
+	case line.RateType == entity.RateTypeHourly:
+		line.BasePay = roundMoney(line.BaseRate * line.WorkedHours)
+	case line.WorkingDays > 0:
+		paidDays := float64(line.AttendedDays + line.PaidLeaveDays)
+		line.BasePay = roundMoney(line.BaseRate * paidDays / float64(line.WorkingDays))
+	}
+
+	var convertErr error
+	line.Bonuses, err = p.runBonuses(ctx, q, line, run, rates)
+	if err != nil {
+		if !errors.Is(err, entity.ErrorNoExchangeRate) {
+			return err
+		}
+		convertErr = err
+	}
+
+	line.Deductions, err = p.periodTotal(ctx, q, deductionsTableName, squirrel.Eq{"user_id": line.UserID}, run, rates)
+	if err != nil {
+		if !errors.Is(err, entity.ErrorNoExchangeRate) {
+			return err
+		}
+		convertErr = err
+	}
+
+	line.Amount = roundMoney(line.BasePay + line.Bonuses - line.Deductions)
+
+	return convertErr
+}
+
+// payoutNotStarted matches the bonuses that were not paid out on their own,
+// which are the only ones a payroll run may pay.
+var payoutNotStarted = squirrel.Or{
+	squirrel.Eq{"payout_status": nil},
+	squirrel.NotEq{"payout_status": []entity.PayoutStatus{entity.PayoutStatusProcessing, entity.PayoutStatusSucceeded}},
+}
+
+// runBonuses sums the approved bonuses of line created up to the end of the
+// period of run that neither an earlier run nor a payout has paid, in the
+// run's currency, and records them in line.BonusIDs. A bonus approved after
+// the run of its period is paid by the next one. Bonuses that cannot be converted are
+// left out and reported with entity.ErrorNoExchangeRate.
+func (p *payrollRepo) runBonuses(ctx context.Context, q payrollQuerier, line *entity.PayrollLine, run *entity.PayrollRun, rates rateTable) (float64, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("id", "currency", "amount").
+		From(bonusesTableName).
+		Where(squirrel.Eq{"user_id": line.UserID, "status": entity.BonusStatusApproved, "payroll_run_id": nil, "deleted_at": nil}).
+		Where(payoutNotStarted).
+		Where(squirrel.Lt{"created_at": run.To.AddDate(0, 0, 1)}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, bonusesTableName+" payroll")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s query error: %w", bonusesTableName, err)
+	}
+	defer rows.Close()
+
+	var (
+		total      float64
+		convertErr error
+	)
+	line.BonusIDs = nil
+	for rows.Next() {
+		var (
+			id       int64
+			currency entity.Currency
+			amount   float64
+		)
+		if err := rows.Scan(&id, &currency, &amount); err != nil {
+			return 0, fmt.Errorf("scan error: %w", err)
+		}
+
+		converted, err := rates.convert(amount, currency, run.Currency)
+		if err != nil {
+			convertErr = err
+			continue
+		}
+		total += converted
+		line.BonusIDs = append(line.BonusIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return roundMoney(total), convertErr
+}
+
+// markBonuses records that the bonuses of line are paid with the salary of
+// run. A bonus a payout or another run took since the line was computed
+// fails it with entity.ErrorPayoutInProgress, as the salary would pay it twice.
+func (p *payrollRepo) markBonuses(ctx context.Context, tx pgx.Tx, line *entity.PayrollLine, run *entity.PayrollRun) error {
+	for _, id := range line.BonusIDs {
+		before, err := snapshot(ctx, p.db, tx, bonusesTableName, id)
+		if err != nil {
+			return err
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Update(bonusesTableName).
+			Set("payroll_run_id", run.ID).
+			Set("updated_at", time.Now().UTC()).
+			Where(squirrel.Eq{"id": id, "payroll_run_id": nil}).
+			Where(payoutNotStarted).
+			ToSql()
+		if err != nil {
+			return p.db.ErrSQLBuild(err, bonusesTableName+" payroll")
+		}
+
+		result, err := tx.Exec(ctx, sqlStr, args...)
+		if err != nil {
+			return p.db.Error(err)
+		}
+		if result.RowsAffected() == 0 {
+			return fmt.Errorf("%w: bonus %d of user %d", entity.ErrorPayoutInProgress, id, line.UserID)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, bonusesTableName, id, before); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// periodTotal sums the amounts of the rows of table that match where and were
+// created during the period of run, in the run's currency. Amounts that cannot
+// be converted are left out and reported with entity.ErrorNoExchangeRate.
+func (p *payrollRepo) periodTotal(ctx context.Context, q payrollQuerier, table string, where squirrel.Eq, run *entity.PayrollRun, rates rateTable) (float64, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("currency", "SUM(amount)").
+		From(table).
+		Where(where).
+		Where(squirrel.GtOrEq{"created_at": run.From}).
+		Where(squirrel.Lt{"created_at": run.To.AddDate(0, 0, 1)}).
+		GroupBy("currency").
+		OrderBy("currency").
+		ToSql()
+	if err != nil {
+		return 0, p.db.ErrSQLBuild(err, table+" payroll")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%s query error: %w", table, err)
+	}
+	defer rows.Close()
+
+	var (
+		total      float64
+		convertErr error
+	)
+	for rows.Next() {
+		var (
+			currency entity.Currency
+			amount   float64
+		)
+		if err := rows.Scan(&currency, &amount); err != nil {
+			return 0, fmt.Errorf("scan error: %w", err)
+		}
+
+		converted, err := rates.convert(amount, currency, run.Currency)
+		if err != nil {
+			convertErr = err
+			continue
+		}
+		total += converted
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return roundMoney(total), convertErr
+}
+
+// paidUsers returns the users that already have a salary from a payroll run
+// whose period overlaps from to to.
+func (p *payrollRepo) paidUsers(ctx context.Context, q querier, from, to time.Time) (map[int64]bool, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("DISTINCT s.user_id").
+		From(salaryTableName + " s").
+		Join(p.tableName + " r ON r.id = s.payroll_run_id").
+		Where(squirrel.Eq{"s.deleted_at": nil}).
+		Where(squirrel.LtOrEq{"r.period_start": to}).
+		Where(squirrel.GtOrEq{"r.period_end": from}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, salaryTableName+" paid")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	paid := make(map[int64]bool)
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		paid[userID] = true
+	}
+
+	return paid, rows.Err()
+}
+
+func scanPayrollRun(row pgx.Row) (*entity.PayrollRun, error) {
+	var run entity.PayrollRun
+	err := row.Scan(
+		&run.ID,
+		&run.AdminID,
+		&run.From,
+		&run.To,
+		&run.PayDate,
+		&run.Currency,
+		&run.Total,
+		&run.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+)
+
+// testAmount is a bonus or deduction fixture.
+type testAmount struct {
+	amount   float64
+	currency entity.Currency
+	day      time.Time
+}
+
+func september(day int) time.Time {
+	return time.Date(2026, time.September, day, 0, 0, 0, 0, time.UTC)
+}
+
+func createTestAmounts(t *testing.T, db *postgres.Postgres, table string, userID int64, amounts []testAmount) {
+	t.Helper()
+
+	for _, a := range amounts {
+		sqlStr := "INSERT INTO " + table + " (user_id, amount, currency, created_at) VALUES ($1, $2, $3, $4)"
+		if table == bonusesTableName {
+			sqlStr = "INSERT INTO " + table + " (user_id, amount, currency, created_at, status) VALUES ($1, $2, $3, $4, 'approved')"
+		}
+		testExec(t, db, sqlStr, userID, a.amount, a.currency, a.day.Add(12*time.Hour))
+	}
+}
+
+func TestComputeLine(t *testing.T) {
+	// Monday to Sunday, five working days without a schedule
+	run := &entity.PayrollRun{From: september(7), To: september(13), Currency: entity.CurrencyUSD}
+	rates := rateTable{{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1}
+	fullWeek := []int{7, 8, 9, 10, 11}
+
+	tests := []struct {
+		name       string
+		rateType   entity.RateType
+		baseRate   float64
+		attended   []int
+		shift      time.Duration
+		leave      map[int]entity.LeaveType
+		bonuses    []testAmount
+		deductions []testAmount
+		rates      rateTable
+		want       entity.PayrollLine
+		wantErr    error
+	}{
+		{
+			name:     "monthly full attendance",
+			rateType: entity.RateTypeMonthly, baseRate: 1000,
+			attended: fullWeek, shift: 8 * time.Hour,
+			want: entity.PayrollLine{WorkingDays: 5, AttendedDays: 5, WorkedHours: 40, BasePay: 1000, Amount: 1000},
+		},
+		{
+			name:     "monthly with leave",
+			rateType: entity.RateTypeMonthly, baseRate: 1000,
+			attended: []int{7, 8, 9}, shift: 8 * time.Hour,
+			leave: map[int]entity.LeaveType{10: entity.LeaveTypeVacation, 11: entity.LeaveTypeUnpaid},
+			want:  entity.PayrollLine{WorkingDays: 5, AttendedDays: 3, PaidLeaveDays: 1, UnpaidDays: 1, WorkedHours: 24, BasePay: 800, Amount: 800},
+		},
+		{
+			name:     "monthly with absences",
+			rateType: entity.RateTypeMonthly, baseRate: 1000,
+			attended: []int{7, 12}, shift: 8 * time.Hour,
+			want: entity.PayrollLine{WorkingDays: 5, AttendedDays: 1, UnpaidDays: 4, WorkedHours: 16, BasePay: 200, Amount: 200},
+		},
+		{
+			name:     "hourly",
+			rateType: entity.RateTypeHourly, baseRate: 12.5,
+			attended: []int{7, 8}, shift: 7*time.Hour + 30*time.Minute,
+			want: entity.PayrollLine{WorkingDays: 5, AttendedDays: 2, UnpaidDays: 3, WorkedHours: 15, BasePay: 187.5, Amount: 187.5},
+		},
+		{
+			name:     "bonuses and deductions converted",
+			rateType: entity.RateTypeMonthly, baseRate: 1000,
+			attended: fullWeek, shift: 8 * time.Hour,
+			bonuses: []testAmount{
+				{100, entity.CurrencyEUR, september(8)},
+				{50, entity.CurrencyUSD, september(1)}, // Left over from before the period
+				{30, entity.CurrencyUSD, september(14)},
+			},
+			deductions: []testAmount{
+				{25, entity.CurrencyUSD, september(9)},
+				{10, entity.CurrencyEUR, september(10)},
+				{40, entity.CurrencyUSD, september(4)},
+			},
+			want: entity.PayrollLine{WorkingDays: 5, AttendedDays: 5, WorkedHours: 40, BasePay: 1000, Bonuses: 160, Deductions: 36, Amount: 1124},
+		},
+		{
+			name:     "rounded to cents",
+			rateType: entity.RateTypeMonthly, baseRate: 1000.01,
+			attended: []int{7, 8, 9, 10}, shift: 8 * time.Hour,
+			bonuses:    []testAmount{{10, entity.CurrencyGBP, september(8)}},
+			deductions: []testAmount{{1, entity.CurrencyEUR, september(8)}, {2, entity.CurrencyEUR, september(9)}},
+			rates: rateTable{
+				{entity.CurrencyEUR, entity.CurrencyUSD}: 1.111111,
+				{entity.CurrencyGBP, entity.CurrencyUSD}: 1.333333,
+			},
+			want: entity.PayrollLine{WorkingDays: 5, AttendedDays: 4, UnpaidDays: 1, WorkedHours: 32, BasePay: 800.01, Bonuses: 13.33, Deductions: 3.33, Amount: 810.01},
+		},
+		{
+			name:     "no exchange rate",
+			rateType: entity.RateTypeMonthly, baseRate: 1000,
+			attended: fullWeek, shift: 8 * time.Hour,
+			bonuses:    []testAmount{{20, entity.CurrencyGBP, september(8)}, {10, entity.CurrencyUSD, september(8)}},
+			deductions: []testAmount{{5, entity.CurrencyGBP, september(9)}},
+			want:       entity.PayrollLine{WorkingDays: 5, AttendedDays: 5, WorkedHours: 40, BasePay: 1000, Bonuses: 10, Amount: 1010},
+			wantErr:    entity.ErrorNoExchangeRate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			repo := NewPayrollRepo(db, testLogger(t)).(*payrollRepo)
+
+			userID := createTestUser(t, db, "employee", entity.UserRoleUser)
+			for _, day := range tt.attended {
+				inTime := september(day).Add(9 * time.Hour)
+				testExec(t, db, "INSERT INTO "+attendanceTableName+" (user_id, date, intime, outtime, status) VALUES ($1, $2, $3, $4, 'present')",
+					userID, september(day), inTime, inTime.Add(tt.shift))
+			}
+			for day, leaveType := range tt.leave {
+				testExec(t, db, "INSERT INTO "+leavesTableName+" (user_id, type, start_date, end_date, days, status) VALUES ($1, $2, $3, $3, 1, 'approved')",
+					userID, leaveType, september(day))
+			}
+			createTestAmounts(t, db, bonusesTableName, userID, tt.bonuses)
+			createTestAmounts(t, db, deductionsTableName, userID, tt.deductions)
+
+			lineRates := rates
+			if tt.rates != nil {
+				lineRates = tt.rates
+			}
+
+			line := &entity.PayrollLine{UserID: userID, RateType: tt.rateType, BaseRate: tt.baseRate}
+			err := repo.computeLine(context.Background(), db, line, time.Time{}, run, lineRates)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("computeLine error = %v, want %v", err, tt.wantErr)
+			}
+
+			got := entity.PayrollLine{
+				WorkingDays: line.WorkingDays, AttendedDays: line.AttendedDays, PaidLeaveDays: line.PaidLeaveDays,
+				UnpaidDays: line.UnpaidDays, WorkedHours: line.WorkedHours, BasePay: line.BasePay,
+				Bonuses: line.Bonuses, Deductions: line.Deductions, Amount: line.Amount,
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("computeLine = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodTotal(t *testing.T) {
+	db := testDB(t)
+	repo := NewPayrollRepo(db, testLogger(t)).(*payrollRepo)
+
+	userID := createTestUser(t, db, "employee", entity.UserRoleUser)
+	otherID := createTestUser(t, db, "other", entity.UserRoleUser)
+	createTestAmounts(t, db, deductionsTableName, userID, []testAmount{
+		{100, entity.CurrencyUSD, september(8)},
+		{50, entity.CurrencyEUR, september(9)},
+		{20, entity.CurrencyGBP, september(13)},
+		{30, entity.CurrencyUSD, time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC)},
+	})
+	createTestAmounts(t, db, deductionsTableName, otherID, []testAmount{{1000, entity.CurrencyUSD, september(8)}})
+
+	week := func(currency entity.Currency) *entity.PayrollRun {
+		return &entity.PayrollRun{From: september(7), To: september(13), Currency: currency}
+	}
+
+	tests := []struct {
+		name    string
+		run     *entity.PayrollRun
+		rates   rateTable
+		want    float64
+		wantErr error
+	}{
+		{
+			name:  "in the run currency",
+			run:   week(entity.CurrencyUSD),
+			rates: rateTable{{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1, {entity.CurrencyGBP, entity.CurrencyUSD}: 1.27},
+			want:  180.4,
+		},
+		{
+			name:  "inverse rates",
+			run:   week(entity.CurrencyEUR),
+			rates: rateTable{{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1, {entity.CurrencyGBP, entity.CurrencyEUR}: 1.2},
+			want:  164.91,
+		},
+		{
+			name: "through a pivot",
+			run:  week(entity.CurrencyUZS),
+			rates: rateTable{
+				{entity.CurrencyUSD, entity.CurrencyUZS}: 12650,
+				{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1,
+				{entity.CurrencyGBP, entity.CurrencyUSD}: 1.25,
+			},
+			want: 2277000,
+		},
+		{
+			name:    "missing rate",
+			run:     week(entity.CurrencyUSD),
+			rates:   rateTable{{entity.CurrencyEUR, entity.CurrencyUSD}: 1.1},
+			want:    155,
+			wantErr: entity.ErrorNoExchangeRate,
+		},
+		{
+			name: "other period",
+			run:  &entity.PayrollRun{From: time.Date(2026, time.August, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2026, time.August, 31, 0, 0, 0, 0, time.UTC), Currency: entity.CurrencyUSD},
+			want: 30,
+		},
+		{
+			name: "nothing",
+			run:  &entity.PayrollRun{From: september(1), To: september(6), Currency: entity.CurrencyUSD},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.periodTotal(context.Background(), db, deductionsTableName, squirrel.Eq{"user_id": userID}, tt.run, tt.rates)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("periodTotal error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("periodTotal = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
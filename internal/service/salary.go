@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -19,6 +22,19 @@ const (
 	salaryIDKey     = "salaryID: "
 )
 
+var salaryColumns = []string{
+	"id", "amount", "user_id", "admin_id", "updateradminid", "pay_date", "currency", "status",
+	"paid_at", "payroll_run_id", "payslip_key", "payout_status", "payout_transaction_id",
+	"deleted_at", "created_at", "updated_at",
+}
+
+// salaryTransitions lists the statuses each salary status may move to.
+var salaryTransitions = map[entity.SalaryStatus][]entity.SalaryStatus{
+	entity.SalaryStatusPending: {entity.SalaryStatusPaid, entity.SalaryStatusOverdue},
+	entity.SalaryStatusOverdue: {entity.SalaryStatusPaid, entity.SalaryStatusPending},
+	entity.SalaryStatusPaid:    {entity.SalaryStatusPending},
+}
+
 // SalaryRepoInterface defines the interface for Salary CRUD operations.
 type SalaryRepoInterface interface {
 	Create(ctx context.Context, req *entity.CreateSalaryRequest) (*entity.Salary, error)
@@ -26,6 +42,13 @@ type SalaryRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllSalariesResponse, error)
 	Update(ctx context.Context, req *entity.UpdateSalaryRequest) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Transition(ctx context.Context, req *entity.SalaryTransitionRequest) (*entity.Salary, error)
+	MarkOverdue(ctx context.Context, date time.Time) ([]*entity.Salary, error)
+	Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error)
+	Payslip(ctx context.Context, id int64) (*entity.Payslip, error)
+	SetPayslip(ctx context.Context, id int64, key string) error
 }
 
 type salaryRepo struct {
@@ -69,47 +92,36 @@ func (p *salaryRepo) Create(ctx context.Context, req *entity.CreateSalaryRequest
 		Insert(p.tableName).
 		Columns(columns...).
 		Values(values...).
-		Suffix(`RETURNING id, amount, user_id, admin_id, updateradminid, pay_date, currency, status, created_at, updated_at`).
+		Suffix("RETURNING " + strings.Join(salaryColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
 	}
 
-	var createdSalary entity.Salary
-	var nullUpdaterAdminID sql.NullInt64 // For nullable updaterAdminId
-
 	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("transaction start failed: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx, sqlStr, args...)
-	err = row.Scan(
-		&createdSalary.ID,
-		&createdSalary.Amount,
-		&createdSalary.UserID,
-		&createdSalary.AdminID,
-		&nullUpdaterAdminID, // Scan into NullInt64
-		&createdSalary.PayDate,
-		&createdSalary.Currency,
-		&createdSalary.Status,
-		&createdSalary.CreatedAt,
-		&createdSalary.UpdatedAt,
-	)
+	if err := checkCompensation(ctx, p.db, tx, req.UserID, req.PayDate, req.Amount, req.Currency); err != nil {
+		return nil, err
+	}
+
+	createdSalary, err := scanSalary(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullUpdaterAdminID.Valid {
-		createdSalary.UpdaterAdminID = &nullUpdaterAdminID.Int64
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdSalary.ID, nil); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 
-	return &createdSalary, nil
+	return createdSalary, nil
 }
 
 func (p *salaryRepo) Get(ctx context.Context, params map[string]string) (*entity.Salary, error) {
@@ -123,10 +135,7 @@ func (p *salaryRepo) Get(ctx context.Context, params map[string]string) (*entity
 		return nil, fmt.Errorf("at least one filter parameter is required")
 	}
 
-	builder := p.db.Sq.Builder.Select(
-		"id", "amount", "user_id", "admin_id", "updateradminid", "pay_date", "currency", "status",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	builder := p.db.Sq.Builder.Select(salaryColumns...).From(p.tableName).Where(notDeleted)
 
 	for key, value := range params {
 		builder = builder.Where(squirrel.Eq{key: value})
@@ -137,30 +146,12 @@ func (p *salaryRepo) Get(ctx context.Context, params map[string]string) (*entity
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
 	}
 
-	var salary entity.Salary
-	var nullUpdaterAdminID sql.NullInt64
-
-	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
-		&salary.ID,
-		&salary.Amount,
-		&salary.UserID,
-		&salary.AdminID,
-		&nullUpdaterAdminID,
-		&salary.PayDate,
-		&salary.Currency,
-		&salary.Status,
-		&salary.CreatedAt,
-		&salary.UpdatedAt,
-	)
+	salary, err := scanSalary(p.db.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullUpdaterAdminID.Valid {
-		salary.UpdaterAdminID = &nullUpdaterAdminID.Int64
-	}
-
-	return &salary, nil
+	return salary, nil
 }
 
 func (p *salaryRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllSalariesResponse, error) {
@@ -171,13 +162,14 @@ func (p *salaryRepo) List(ctx context.Context, limit, offset uint64, filter map[
 	}
 
 	var salaries entity.GetAllSalariesResponse
-	baseBuilder := p.db.Sq.Builder.Select(
-		"id", "amount", "user_id", "admin_id", "updateradminid", "pay_date", "currency", "status",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	baseBuilder := p.db.Sq.Builder.Select(salaryColumns...).From(p.tableName)
 
 	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
 
+	trash := deletedFilter(filter)
+	baseBuilder = baseBuilder.Where(trash)
+	countBuilder = countBuilder.Where(trash)
+
 	// Apply filters
 	for key, value := range filter {
 		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
@@ -209,19 +201,11 @@ func (p *salaryRepo) List(ctx context.Context, limit, offset uint64, filter map[
 	defer rows.Close()
 
 	for rows.Next() {
-		var salary entity.Salary
-		var nullUpdaterAdminID sql.NullInt64
-		if err := rows.Scan(
-			&salary.ID, &salary.Amount, &salary.UserID, &salary.AdminID,
-			&nullUpdaterAdminID, &salary.PayDate, &salary.Currency, &salary.Status,
-			&salary.CreatedAt, &salary.UpdatedAt,
-		); err != nil {
+		salary, err := scanSalary(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
-		if nullUpdaterAdminID.Valid {
-			salary.UpdaterAdminID = &nullUpdaterAdminID.Int64
-		}
-		salaries.Items = append(salaries.Items, &salary)
+		salaries.Items = append(salaries.Items, salary)
 	}
 
 	// Get total count
@@ -258,6 +242,9 @@ func (p *salaryRepo) Update(ctx context.Context, req *entity.UpdateSalaryRequest
 
 	if req.Amount != nil {
 		clauses["amount"] = *req.Amount
+		// A changed run salary keeps its bonuses and deductions, the
+		// difference goes to the base pay on its payslip
+		clauses["base_pay"] = squirrel.Expr("base_pay + (? - amount)", *req.Amount)
 	}
 	if req.UserID != nil {
 		clauses["user_id"] = *req.UserID
@@ -274,9 +261,6 @@ func (p *salaryRepo) Update(ctx context.Context, req *entity.UpdateSalaryRequest
 	if req.Currency != nil {
 		clauses["currency"] = *req.Currency
 	}
-	if req.Status != nil {
-		clauses["status"] = *req.Status
-	}
 
 	if len(clauses) < 2 {
 		return fmt.Errorf("no fields to update")
@@ -286,6 +270,7 @@ func (p *salaryRepo) Update(ctx context.Context, req *entity.UpdateSalaryRequest
 		Update(p.tableName).
 		SetMap(clauses).
 		Where(p.db.Sq.Equal("id", req.ID)).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return p.db.ErrSQLBuild(err, p.tableName+" update")
@@ -297,6 +282,40 @@ func (p *salaryRepo) Update(ctx context.Context, req *entity.UpdateSalaryRequest
 	}
 	defer tx.Rollback(ctx)
 
+	var (
+		status       entity.SalaryStatus
+		payoutStatus sql.NullString
+		runCurrency  entity.Currency
+		runID        sql.NullInt64
+	)
+	err = tx.QueryRow(ctx, "SELECT status, payout_status, currency, payroll_run_id FROM "+p.tableName+" WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", req.ID).
+		Scan(&status, &payoutStatus, &runCurrency, &runID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no salary found with ID %d", req.ID)
+		}
+		return p.db.Error(err)
+	}
+
+	// A paid salary is reopened before it is corrected, and one whose
+	// payout has started is left alone so the card gets what the row says
+	if status != entity.SalaryStatusPending && status != entity.SalaryStatusOverdue {
+		return fmt.Errorf("%w: a %s salary cannot be edited", entity.ErrorSalaryTransition, status)
+	}
+	if payoutStarted(payoutStatus) {
+		return fmt.Errorf("%w: payout is %s", entity.ErrorSalaryTransition, payoutStatus.String)
+	}
+	// The base pay, bonuses and deductions of a run salary are in the
+	// run's currency
+	if runID.Valid && req.Currency != nil && *req.Currency != runCurrency {
+		return fmt.Errorf("%w: a payroll run salary stays in %s", entity.ErrorSalaryTransition, runCurrency)
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -306,6 +325,33 @@ func (p *salaryRepo) Update(ctx context.Context, req *entity.UpdateSalaryRequest
 		return fmt.Errorf("no salary found with ID %d", req.ID)
 	}
 
+	// Run salaries add bonuses and subtract deductions on top of the
+	// contractual amount, only hand-entered ones are held to it
+	if req.Amount != nil || req.UserID != nil || req.PayDate != nil || req.Currency != nil {
+		var (
+			userID       int64
+			payDate      time.Time
+			amount       float64
+			currency     entity.Currency
+			payrollRunID sql.NullInt64
+		)
+		err := tx.QueryRow(ctx, "SELECT user_id, pay_date, amount, currency, payroll_run_id FROM "+p.tableName+" WHERE id = $1", req.ID).
+			Scan(&userID, &payDate, &amount, &currency, &payrollRunID)
+		if err != nil {
+			return p.db.Error(err)
+		}
+
+		if !payrollRunID.Valid {
+			if err := checkCompensation(ctx, p.db, tx, userID, payDate, amount, currency); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -324,15 +370,491 @@ func (p *salaryRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("salary ID is required for deletion")
 	}
 
+	deleted, err := softDelete(ctx, p.db, p.tableName, id, salaryPaidOut)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no salary found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Restore takes a deleted salary out of the trash.
+func (p *salaryRepo) Restore(ctx context.Context, id int64) error {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.Restore - %s", reqID))
+	}
+
+	restored, err := restoreDeleted(ctx, p.db, p.tableName, id)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return fmt.Errorf("no deleted salary found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Purge removes a deleted salary for good.
+func (p *salaryRepo) Purge(ctx context.Context, id int64) error {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.Purge - %s", reqID))
+	}
+
+	purged, err := purgeDeleted(ctx, p.db, p.tableName, id, salaryPaidOut)
+	if err != nil {
+		return err
+	}
+	if !purged {
+		return fmt.Errorf("no deleted salary found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Transition moves a salary to req.Status when its current status allows it
+// and records the admin doing so. Paying stamps paid_at, and reopening
+// clears it and the payslip and may move the pay date.
+func (p *salaryRepo) Transition(ctx context.Context, req *entity.SalaryTransitionRequest) (*entity.Salary, error) {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.Transition - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.SalaryStatus
+	var payoutStatus sql.NullString
+	err = tx.QueryRow(ctx, "SELECT status, payout_status FROM "+p.tableName+" WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", req.ID).Scan(&current, &payoutStatus)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no salary found with ID %d", req.ID)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	if !slices.Contains(salaryTransitions[current], req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", entity.ErrorSalaryTransition, current, req.Status)
+	}
+
+	// Money that is on its way or already on the card cannot be taken back
+	// by reopening, or the salary could be edited and paid out again
+	if req.Status == entity.SalaryStatusPending && payoutStarted(payoutStatus) {
+		return nil, fmt.Errorf("%w: payout is %s", entity.ErrorSalaryTransition, payoutStatus.String)
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	clauses := map[string]interface{}{
+		"status":         req.Status,
+		"updateradminid": req.AdminID,
+		"updated_at":     now,
+	}
+	switch req.Status {
+	case entity.SalaryStatusPaid:
+		clauses["paid_at"] = now
+	case entity.SalaryStatusPending:
+		clauses["paid_at"] = nil
+		clauses["payslip_key"] = nil
+		if req.PayDate != nil {
+			clauses["pay_date"] = *req.PayDate
+		}
+	}
+
 	sqlStr, args, err := p.db.Sq.Builder.
-		Delete(p.tableName).
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(squirrel.Eq{"id": req.ID}).
+		Suffix("RETURNING " + strings.Join(salaryColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" transition")
+	}
+
+	salary, err := scanSalary(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return salary, nil
+}
+
+// MarkOverdue moves every pending salary due before date to overdue and
+// returns them.
+func (p *salaryRepo) MarkOverdue(ctx context.Context, date time.Time) ([]*entity.Salary, error) {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.MarkOverdue - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	dueSqlStr, dueArgs, err := p.db.Sq.Builder.
+		Select("id").
+		From(p.tableName).
+		Where(squirrel.Eq{"status": entity.SalaryStatusPending}).
+		Where(squirrel.Lt{"pay_date": dateOnly(date)}).
+		Where(notDeleted).
+		OrderBy("id").
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" overdue")
+	}
+
+	rows, err := tx.Query(ctx, dueSqlStr, dueArgs...)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	var salaries []*entity.Salary
+	for _, id := range ids {
+		before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+		if err != nil {
+			return nil, err
+		}
+
+		sqlStr, args, err := p.db.Sq.Builder.
+			Update(p.tableName).
+			Set("status", entity.SalaryStatusOverdue).
+			Set("updated_at", time.Now().UTC()).
+			Where(squirrel.Eq{"id": id}).
+			Suffix("RETURNING " + strings.Join(salaryColumns, ", ")).
+			ToSql()
+		if err != nil {
+			return nil, p.db.ErrSQLBuild(err, p.tableName+" mark overdue")
+		}
+
+		salary, err := scanSalary(tx.QueryRow(ctx, sqlStr, args...))
+		if err != nil {
+			return nil, p.db.Error(err)
+		}
+
+		if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, id, before); err != nil {
+			return nil, err
+		}
+		salaries = append(salaries, salary)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return salaries, nil
+}
+
+// Report totals the salaries due from from to to in currency, converting
+// each one at the rates known on its pay date.
+func (p *salaryRepo) Report(ctx context.Context, filter map[string]string, from, to time.Time, currency entity.Currency) (*entity.PayoutReport, error) {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.Report - %s", reqID))
+	}
+
+	builder := p.db.Sq.Builder.
+		Select("amount", "currency", "pay_date", "status").
+		From(p.tableName).
+		Where(squirrel.GtOrEq{"pay_date": dateOnly(from)}).
+		Where(squirrel.LtOrEq{"pay_date": dateOnly(to)}).
+		Where(notDeleted)
+
+	// Apply filters
+	for key, value := range filter {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" report")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	var salaries []*entity.Salary
+	for rows.Next() {
+		var salary entity.Salary
+		if err := rows.Scan(&salary.Amount, &salary.Currency, &salary.PayDate, &salary.Status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		salaries = append(salaries, &salary)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	totals := newPayoutTotals(p.db, currency, from, to)
+	for _, salary := range salaries {
+		if err := totals.add(ctx, salary.Amount, salary.Currency, salary.PayDate, string(salary.Status)); err != nil {
+			return nil, err
+		}
+	}
+
+	return totals.finish(), nil
+}
+
+// Payslip builds the payslip of a paid salary. The period is the one of the
+// payroll run that created the salary, or the calendar month of the pay date
+// for salaries entered by hand. The net is the amount paid. Salaries entered
+// by hand have no bonuses or deductions; run salaries list the ones of the
+// period converted into the salary's currency at the rates known at its end,
+// with the base pay and totals the run computed.
+func (p *salaryRepo) Payslip(ctx context.Context, id int64) (*entity.Payslip, error) {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.Payslip - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(
+			"s.id", "s.user_id", "u.first_name", "u.last_name", "s.amount", "s.currency", "s.status",
+			"s.pay_date", "s.paid_at", "s.payroll_run_id", "r.period_start", "r.period_end",
+			"s.base_pay", "s.bonus_total", "s.deduction_total",
+		).
+		From(p.tableName + " s").
+		Join(userTableName + " u ON u.id = s.user_id").
+		LeftJoin(payrollRunTableName + " r ON r.id = s.payroll_run_id").
+		Where(squirrel.Eq{"s.id": id, "s.deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" payslip")
+	}
+
+	var (
+		payslip            entity.Payslip
+		amount             float64
+		status             entity.SalaryStatus
+		nullPaidAt         sql.NullTime
+		nullPayrollRunID   sql.NullInt64
+		nullPeriodStart    sql.NullTime
+		nullPeriodEnd      sql.NullTime
+		nullBasePay        sql.NullFloat64
+		nullBonusTotal     sql.NullFloat64
+		nullDeductionTotal sql.NullFloat64
+	)
+	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
+		&payslip.SalaryID,
+		&payslip.UserID,
+		&payslip.FirstName,
+		&payslip.LastName,
+		&amount,
+		&payslip.Currency,
+		&status,
+		&payslip.PayDate,
+		&nullPaidAt,
+		&nullPayrollRunID,
+		&nullPeriodStart,
+		&nullPeriodEnd,
+		&nullBasePay,
+		&nullBonusTotal,
+		&nullDeductionTotal,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no salary found with ID %d", id)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	if status != entity.SalaryStatusPaid {
+		return nil, entity.ErrorSalaryNotPaid
+	}
+	if nullPaidAt.Valid {
+		payslip.PaidAt = &nullPaidAt.Time
+	}
+
+	if nullPeriodStart.Valid && nullPeriodEnd.Valid {
+		payslip.PeriodStart = dateOnly(nullPeriodStart.Time)
+		payslip.PeriodEnd = dateOnly(nullPeriodEnd.Time)
+	} else {
+		payDate := dateOnly(payslip.PayDate)
+		payslip.PeriodStart = payDate.AddDate(0, 0, 1-payDate.Day())
+		payslip.PeriodEnd = payslip.PeriodStart.AddDate(0, 1, -1)
+	}
+
+	// The net is always what was paid. A hand-entered salary is paid as it
+	// is, bonuses and deductions only ever go through payroll runs.
+	payslip.Net = amount
+	payslip.BaseSalary = amount
+	if !nullPayrollRunID.Valid {
+		return &payslip, nil
+	}
+
+	rates, err := loadRates(ctx, p.db, p.db, payslip.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	period := squirrel.And{
+		squirrel.GtOrEq{"created_at": payslip.PeriodStart},
+		squirrel.Lt{"created_at": payslip.PeriodEnd.AddDate(0, 0, 1)},
+	}
+
+	// Runs mark the bonuses they pay, which may be from an earlier period.
+	// Runs from before the breakdown was stored did not.
+	bonuses := squirrel.And{squirrel.Eq{"payroll_run_id": nullPayrollRunID.Int64}}
+	if !nullBasePay.Valid {
+		bonuses = squirrel.And{squirrel.Eq{"user_id": payslip.UserID, "status": entity.BonusStatusApproved, "deleted_at": nil}, period}
+	}
+	payslip.Bonuses, payslip.TotalBonuses, err = p.payslipItems(ctx, bonusesTableName, bonuses, "Bonus", &payslip, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	payslip.Deductions, payslip.TotalDeductions, err = p.payslipItems(ctx, deductionsTableName, squirrel.And{squirrel.Eq{"user_id": payslip.UserID}, period}, "Deduction", &payslip, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	// The totals the run computed win over the rows as they are now, which
+	// may have been edited since. Runs from before the breakdown was stored
+	// work the base pay out backwards.
+	if nullBasePay.Valid && nullBonusTotal.Valid && nullDeductionTotal.Valid {
+		payslip.BaseSalary = nullBasePay.Float64
+		payslip.TotalBonuses = nullBonusTotal.Float64
+		payslip.TotalDeductions = nullDeductionTotal.Float64
+	} else {
+		payslip.BaseSalary = roundMoney(amount - payslip.TotalBonuses + payslip.TotalDeductions)
+	}
+
+	return &payslip, nil
+}
+
+// payslipItems lists the rows of table that match where, converted into the
+// currency of payslip, along with their total. Rows without a reason are
+// described as fallback.
+func (p *salaryRepo) payslipItems(ctx context.Context, table string, where squirrel.Sqlizer, fallback string, payslip *entity.Payslip, rates rateTable) ([]*entity.PayslipItem, float64, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("amount", "currency", "reason", "created_at").
+		From(table).
+		Where(where).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, 0, p.db.ErrSQLBuild(err, table+" payslip")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var total float64
+	items := []*entity.PayslipItem{}
+	for rows.Next() {
+		var (
+			amount     float64
+			currency   entity.Currency
+			nullReason sql.NullString
+			createdAt  time.Time
+		)
+		if err := rows.Scan(&amount, &currency, &nullReason, &createdAt); err != nil {
+			return nil, 0, fmt.Errorf("scan error: %w", err)
+		}
+
+		converted, err := rates.convert(amount, currency, payslip.Currency)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		description := fallback
+		if nullReason.Valid && nullReason.String != "" {
+			description = nullReason.String
+		}
+		items = append(items, &entity.PayslipItem{
+			Date:        createdAt,
+			Description: description,
+			Amount:      roundMoney(converted),
+		})
+		total += converted
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+
+	return items, roundMoney(total), nil
+}
+
+// SetPayslip records the object key of the payslip of a salary.
+func (p *salaryRepo) SetPayslip(ctx context.Context, id int64, key string) error {
+	var reqID = salaryIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("salaryRepo.SetPayslip - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("payslip_key", key).
 		Where(squirrel.Eq{"id": id}).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("delete query build error: %w", err)
+		return p.db.ErrSQLBuild(err, p.tableName+" set payslip")
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
 	}
@@ -341,5 +863,111 @@ func (p *salaryRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("no salary found with ID %d", id)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
 	return nil
 }
+
+// checkCompensation holds a hand-entered salary to the compensation of the
+// user in effect on its pay date: it is paid in the same currency and, for a
+// monthly rate, does not exceed the contractual amount. Less is allowed for
+// partial months. Users without a compensation profile are not checked.
+func checkCompensation(ctx context.Context, db *postgres.Postgres, q rowQuerier, userID int64, payDate time.Time, amount float64, currency entity.Currency) error {
+	compensation, err := loadCompensation(ctx, db, q, userID, payDate)
+	if err != nil {
+		return err
+	}
+	if compensation == nil {
+		return nil
+	}
+
+	if currency != compensation.Currency {
+		return fmt.Errorf("%w: the salary is in %s, the compensation in %s", entity.ErrorSalaryContract, currency, compensation.Currency)
+	}
+	if compensation.RateType == entity.RateTypeMonthly && amount > compensation.Amount {
+		return fmt.Errorf("%w: %.2f exceeds the monthly amount of %.2f %s", entity.ErrorSalaryContract, amount, compensation.Amount, compensation.Currency)
+	}
+
+	return nil
+}
+
+// salaryPaidOut matches the salaries that are paid or whose payout has
+// started, which are kept as the record of the money sent.
+var salaryPaidOut = squirrel.Or{
+	squirrel.Eq{"status": entity.SalaryStatusPaid},
+	squirrel.Eq{"payout_status": []entity.PayoutStatus{entity.PayoutStatusProcessing, entity.PayoutStatusSucceeded}},
+}
+
+// payoutStarted reports whether a payout_status value means the money is on
+// its way or already on the card.
+func payoutStarted(status sql.NullString) bool {
+	return status.Valid &&
+		(entity.PayoutStatus(status.String) == entity.PayoutStatusProcessing ||
+			entity.PayoutStatus(status.String) == entity.PayoutStatusSucceeded)
+}
+
+func scanSalary(row pgx.Row) (*entity.Salary, error) {
+	var salary entity.Salary
+	var (
+		nullUpdaterAdminID sql.NullInt64
+		nullPaidAt         sql.NullTime
+		nullPayrollRunID   sql.NullInt64
+		nullPayslipKey     sql.NullString
+		nullPayoutStatus   sql.NullString
+		nullPayoutTxID     sql.NullString
+		nullDeletedAt      sql.NullTime
+	)
+
+	err := row.Scan(
+		&salary.ID,
+		&salary.Amount,
+		&salary.UserID,
+		&salary.AdminID,
+		&nullUpdaterAdminID,
+		&salary.PayDate,
+		&salary.Currency,
+		&salary.Status,
+		&nullPaidAt,
+		&nullPayrollRunID,
+		&nullPayslipKey,
+		&nullPayoutStatus,
+		&nullPayoutTxID,
+		&nullDeletedAt,
+		&salary.CreatedAt,
+		&salary.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullUpdaterAdminID.Valid {
+		salary.UpdaterAdminID = &nullUpdaterAdminID.Int64
+	}
+	if nullPaidAt.Valid {
+		salary.PaidAt = &nullPaidAt.Time
+	}
+	if nullPayrollRunID.Valid {
+		salary.PayrollRunID = &nullPayrollRunID.Int64
+	}
+	if nullPayslipKey.Valid {
+		salary.PayslipKey = &nullPayslipKey.String
+	}
+	if nullPayoutStatus.Valid {
+		status := entity.PayoutStatus(nullPayoutStatus.String)
+		salary.PayoutStatus = &status
+	}
+	if nullPayoutTxID.Valid {
+		salary.PayoutTransactionID = &nullPayoutTxID.String
+	}
+	if nullDeletedAt.Valid {
+		salary.DeletedAt = &nullDeletedAt.Time
+	}
+
+	return &salary, nil
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+)
+
+func TestSalaryTransitions(t *testing.T) {
+	tests := []struct {
+		from, to entity.SalaryStatus
+		allowed  bool
+	}{
+		{entity.SalaryStatusPending, entity.SalaryStatusPaid, true},
+		{entity.SalaryStatusPending, entity.SalaryStatusOverdue, true},
+		{entity.SalaryStatusPending, entity.SalaryStatusPending, false},
+		{entity.SalaryStatusOverdue, entity.SalaryStatusPaid, true},
+		{entity.SalaryStatusOverdue, entity.SalaryStatusPending, true},
+		{entity.SalaryStatusOverdue, entity.SalaryStatusOverdue, false},
+		{entity.SalaryStatusPaid, entity.SalaryStatusPending, true},
+		{entity.SalaryStatusPaid, entity.SalaryStatusOverdue, false},
+		{entity.SalaryStatusPaid, entity.SalaryStatusPaid, false},
+		{"unknown", entity.SalaryStatusPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			if got := slices.Contains(salaryTransitions[tt.from], tt.to); got != tt.allowed {
+				t.Errorf("allowed = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
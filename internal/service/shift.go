@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/pkg/helper"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	shiftsTableName = "shifts"
+	shiftsIDKey     = "shiftID: "
+)
+
+// shiftColumns formats the TIME columns as HH:MM so they scan into strings.
+var shiftColumns = []string{
+	"id", "user_id", "weekday",
+	"to_char(start_time, 'HH24:MI')", "to_char(end_time, 'HH24:MI')",
+	"grace_minutes", "timezone", "created_at", "updated_at",
+}
+
+// ShiftRepoInterface defines the interface for Shift CRUD operations.
+type ShiftRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateShiftRequest) (*entity.Shift, error)
+	Get(ctx context.Context, params map[string]string) (*entity.Shift, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllShiftsResponse, error)
+	Update(ctx context.Context, req *entity.UpdateShiftRequest) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type shiftRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewShiftRepo(db *postgres.Postgres, log *logger.Logger) ShiftRepoInterface {
+	return &shiftRepo{
+		tableName: shiftsTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+func (p *shiftRepo) Create(ctx context.Context, req *entity.CreateShiftRequest) (*entity.Shift, error) {
+	var reqID = shiftsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("shiftRepo.Create - %s", reqID))
+	}
+
+	if req.Weekday == nil {
+		return nil, fmt.Errorf("shift weekday is required")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns(
+			"user_id", "weekday", "start_time", "end_time", "grace_minutes", "timezone",
+			"created_at", "updated_at",
+		).
+		Values(
+			req.UserID, *req.Weekday, req.StartTime, req.EndTime, req.GraceMinutes, req.Timezone,
+			time.Now().UTC(), time.Now().UTC(),
+		).
+		Suffix(`RETURNING id, user_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), grace_minutes, timezone, created_at, updated_at`).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	var createdShift entity.Shift
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, sqlStr, args...).Scan(
+		&createdShift.ID,
+		&createdShift.UserID,
+		&createdShift.Weekday,
+		&createdShift.StartTime,
+		&createdShift.EndTime,
+		&createdShift.GraceMinutes,
+		&createdShift.Timezone,
+		&createdShift.CreatedAt,
+		&createdShift.UpdatedAt,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdShift.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return &createdShift, nil
+}
+
+func (p *shiftRepo) Get(ctx context.Context, params map[string]string) (*entity.Shift, error) {
+	var reqID = shiftsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("shiftRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(shiftColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	var shift entity.Shift
+
+	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
+		&shift.ID,
+		&shift.UserID,
+		&shift.Weekday,
+		&shift.StartTime,
+		&shift.EndTime,
+		&shift.GraceMinutes,
+		&shift.Timezone,
+		&shift.CreatedAt,
+		&shift.UpdatedAt,
+	)
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return &shift, nil
+}
+
+func (p *shiftRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string) (*entity.GetAllShiftsResponse, error) {
+	var reqID = shiftsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("shiftRepo.List - %s", reqID))
+	}
+
+	var shifts entity.GetAllShiftsResponse
+	baseBuilder := p.db.Sq.Builder.Select(shiftColumns...).From(p.tableName)
+
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	// Order, Limit, Offset for data query
+	baseBuilder = baseBuilder.OrderBy("user_id", "weekday").Limit(limit).Offset(offset)
+
+	sqlStr, args, err := baseBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift entity.Shift
+		if err := rows.Scan(
+			&shift.ID, &shift.UserID, &shift.Weekday, &shift.StartTime, &shift.EndTime,
+			&shift.GraceMinutes, &shift.Timezone, &shift.CreatedAt, &shift.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		shifts.Items = append(shifts.Items, &shift)
+	}
+
+	// Get total count
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	var totalCount uint64
+	err = p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&totalCount)
+	if err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	shifts.Total = totalCount
+	return &shifts, nil
+}
+
+func (p *shiftRepo) Update(ctx context.Context, req *entity.UpdateShiftRequest) error {
+	var reqID = shiftsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("shiftRepo.Update - %s", reqID))
+	}
+
+	if req == nil {
+		return fmt.Errorf("shift update request cannot be nil")
+	}
+	if req.ID == 0 {
+		return fmt.Errorf("shift ID is required for update")
+	}
+
+	clauses := map[string]interface{}{"updated_at": time.Now().UTC()}
+
+	if req.Weekday != nil {
+		clauses["weekday"] = *req.Weekday
+	}
+	if req.StartTime != nil {
+		clauses["start_time"] = *req.StartTime
+	}
+	if req.EndTime != nil {
+		clauses["end_time"] = *req.EndTime
+	}
+	if req.GraceMinutes != nil {
+		clauses["grace_minutes"] = *req.GraceMinutes
+	}
+	if req.Timezone != nil {
+		clauses["timezone"] = *req.Timezone
+	}
+
+	if len(clauses) <= 1 { // Only updated_at means no actual fields were passed
+		return fmt.Errorf("no fields to update")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(p.db.Sq.Equal("id", req.ID)).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no shift found with ID %d", req.ID)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+func (p *shiftRepo) Delete(ctx context.Context, id int64) error {
+	var reqID = shiftsIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("shiftRepo.Delete - %s", reqID))
+	}
+
+	if id == 0 {
+		return fmt.Errorf("shift ID is required for deletion")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no shift found with ID %d", id)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// querier is satisfied by both the pool and a transaction, so schedules can be
+// read inside a transaction without needing a second connection.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// loadShiftRules returns the weekly schedule of a user keyed by weekday. An
+// empty map means the user has no schedule.
+func loadShiftRules(ctx context.Context, db *postgres.Postgres, q querier, userID int64) (map[time.Weekday]*entity.ShiftRule, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Select(
+			"weekday", "to_char(start_time, 'HH24:MI')", "to_char(end_time, 'HH24:MI')",
+			"grace_minutes", "timezone",
+		).
+		From(shiftsTableName).
+		Where(squirrel.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, db.ErrSQLBuild(err, shiftsTableName+" rules")
+	}
+
+	rows, err := q.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make(map[time.Weekday]*entity.ShiftRule)
+	for rows.Next() {
+		var (
+			weekday              int
+			start, end, timezone string
+			graceMinutes         int
+		)
+		if err := rows.Scan(&weekday, &start, &end, &graceMinutes, &timezone); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		rule, err := helper.ParseShiftRule(start, end, graceMinutes, timezone)
+		if err != nil {
+			return nil, fmt.Errorf("shift of user %d on weekday %d: %w", userID, weekday, err)
+		}
+		rules[time.Weekday(weekday)] = rule
+	}
+
+	return rules, rows.Err()
+}
@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -15,10 +18,28 @@ import (
 )
 
 const (
-	taskTableName = "tasks"
-	taskIDKey     = "taskID: "
+	taskTableName              = "tasks"
+	taskStatusHistoryTableName = "task_status_history"
+	taskIDKey                  = "taskID: "
 )
 
+var taskColumns = []string{
+	"id", "assignedto", "admin_id", "title", "description", "status", "priority", "duedate",
+	"completed_at", "deleted_at", "created_at", "updated_at",
+}
+
+var taskStatusChangeColumns = []string{
+	"id", "task_id", "from_status", "to_status", "actor_id", "created_at",
+}
+
+// taskTransitions lists the statuses each task status may move to.
+var taskTransitions = map[entity.TaskStatus][]entity.TaskStatus{
+	entity.TaskStatusPending:    {entity.TaskStatusInProgress, entity.TaskStatusCompleted, entity.TaskStatusCancelled},
+	entity.TaskStatusInProgress: {entity.TaskStatusCompleted, entity.TaskStatusCancelled},
+	entity.TaskStatusCompleted:  {entity.TaskStatusPending},
+	entity.TaskStatusCancelled:  {entity.TaskStatusPending},
+}
+
 // TaskRepoInterface defines the interface for Task CRUD operations.
 type TaskRepoInterface interface {
 	Create(ctx context.Context, req *entity.CreateTaskRequest) (*entity.Task, error)
@@ -26,6 +47,11 @@ type TaskRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllTasksResponse, error)
 	Update(ctx context.Context, req *entity.UpdateTaskRequest) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
+	Transition(ctx context.Context, req *entity.TaskTransitionRequest) (*entity.Task, error)
+	History(ctx context.Context, id int64) ([]*entity.TaskStatusChange, error)
+	Activity(ctx context.Context, id int64, limit, offset uint64) (*entity.GetTaskActivityResponse, error)
 }
 
 type taskRepo struct {
@@ -49,13 +75,14 @@ func (p *taskRepo) Create(ctx context.Context, req *entity.CreateTaskRequest) (*
 		p.log.Info(fmt.Sprintf("taskRepo.Create - %s", reqID))
 	}
 
-	// Define columns and values for insertion.
+	// Define columns and values for insertion. Every task starts out
+	// pending and moves on through Transition.
 	columns := []string{
 		"admin_id", "title", "status", "priority",
 		"created_at", "updated_at",
 	}
 	values := []interface{}{
-		req.AdminID, req.Title, req.Status, req.Priority,
+		req.AdminID, req.Title, entity.TaskStatusPending, req.Priority,
 		time.Now().UTC(), time.Now().UTC(),
 	}
 
@@ -77,59 +104,36 @@ func (p *taskRepo) Create(ctx context.Context, req *entity.CreateTaskRequest) (*
 		Insert(p.tableName).
 		Columns(columns...).
 		Values(values...).
-		Suffix(`RETURNING id, assignedto, admin_id, title, description, status, priority, duedate, created_at, updated_at`).
+		Suffix("RETURNING " + strings.Join(taskColumns, ", ")).
 		ToSql()
 	if err != nil {
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
 	}
 
-	var createdTask entity.Task
-	// Use sql.Null* types for scanning nullable columns
-	var (
-		nullAssignedTo  sql.NullInt64
-		nullDescription sql.NullString
-		nullDueDate     sql.NullTime
-	)
-
 	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("transaction start failed: %w", err)
 	}
 	defer tx.Rollback(ctx)
 
-	row := tx.QueryRow(ctx, sqlStr, args...)
-	err = row.Scan(
-		&createdTask.ID,
-		&nullAssignedTo,
-		&createdTask.AdminID,
-		&createdTask.Title,
-		&nullDescription,
-		&createdTask.Status,
-		&createdTask.Priority,
-		&nullDueDate,
-		&createdTask.CreatedAt,
-		&createdTask.UpdatedAt,
-	)
+	createdTask, err := scanTask(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	// Assign scanned null values to pointers in the entity struct
-	if nullAssignedTo.Valid {
-		createdTask.AssignedTo = &nullAssignedTo.Int64
-	}
-	if nullDescription.Valid {
-		createdTask.Description = &nullDescription.String
+	if err := p.recordStatusChange(ctx, tx, createdTask.ID, nil, createdTask.Status, req.AdminID); err != nil {
+		return nil, err
 	}
-	if nullDueDate.Valid {
-		createdTask.DueDate = &nullDueDate.Time
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdTask.ID, nil); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
 
-	return &createdTask, nil
+	return createdTask, nil
 }
 
 func (p *taskRepo) Get(ctx context.Context, params map[string]string) (*entity.Task, error) {
@@ -143,10 +147,7 @@ func (p *taskRepo) Get(ctx context.Context, params map[string]string) (*entity.T
 		return nil, fmt.Errorf("at least one filter parameter is required")
 	}
 
-	builder := p.db.Sq.Builder.Select(
-		"id", "assignedto", "admin_id", "title", "description", "status", "priority", "duedate",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	builder := p.db.Sq.Builder.Select(taskColumns...).From(p.tableName).Where(notDeleted)
 
 	for key, value := range params {
 		builder = builder.Where(squirrel.Eq{key: value})
@@ -157,40 +158,12 @@ func (p *taskRepo) Get(ctx context.Context, params map[string]string) (*entity.T
 		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
 	}
 
-	var task entity.Task
-	var (
-		nullAssignedTo  sql.NullInt64
-		nullDescription sql.NullString
-		nullDueDate     sql.NullTime
-	)
-
-	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
-		&task.ID,
-		&nullAssignedTo,
-		&task.AdminID,
-		&task.Title,
-		&nullDescription,
-		&task.Status,
-		&task.Priority,
-		&nullDueDate,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-	)
+	task, err := scanTask(p.db.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
 		return nil, p.db.Error(err)
 	}
 
-	if nullAssignedTo.Valid {
-		task.AssignedTo = &nullAssignedTo.Int64
-	}
-	if nullDescription.Valid {
-		task.Description = &nullDescription.String
-	}
-	if nullDueDate.Valid {
-		task.DueDate = &nullDueDate.Time
-	}
-
-	return &task, nil
+	return task, nil
 }
 
 func (p *taskRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllTasksResponse, error) {
@@ -201,13 +174,14 @@ func (p *taskRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	}
 
 	var tasks entity.GetAllTasksResponse
-	baseBuilder := p.db.Sq.Builder.Select(
-		"id", "assignedto", "admin_id", "title", "description", "status", "priority", "duedate",
-		"created_at", "updated_at",
-	).From(p.tableName)
+	baseBuilder := p.db.Sq.Builder.Select(taskColumns...).From(p.tableName)
 
 	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
 
+	trash := deletedFilter(filter)
+	baseBuilder = baseBuilder.Where(trash)
+	countBuilder = countBuilder.Where(trash)
+
 	// Apply filters
 	for key, value := range filter {
 		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
@@ -241,28 +215,11 @@ func (p *taskRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	defer rows.Close()
 
 	for rows.Next() {
-		var task entity.Task
-		var (
-			nullAssignedTo  sql.NullInt64
-			nullDescription sql.NullString
-			nullDueDate     sql.NullTime
-		)
-		if err := rows.Scan(
-			&task.ID, &nullAssignedTo, &task.AdminID, &task.Title, &nullDescription,
-			&task.Status, &task.Priority, &nullDueDate, &task.CreatedAt, &task.UpdatedAt,
-		); err != nil {
+		task, err := scanTask(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
-		if nullAssignedTo.Valid {
-			task.AssignedTo = &nullAssignedTo.Int64
-		}
-		if nullDescription.Valid {
-			task.Description = &nullDescription.String
-		}
-		if nullDueDate.Valid {
-			task.DueDate = &nullDueDate.Time
-		}
-		tasks.Items = append(tasks.Items, &task)
+		tasks.Items = append(tasks.Items, task)
 	}
 
 	// Get total count
@@ -309,9 +266,6 @@ func (p *taskRepo) Update(ctx context.Context, req *entity.UpdateTaskRequest) er
 	if req.Description != nil {
 		clauses["description"] = *req.Description
 	}
-	if req.Status != nil {
-		clauses["status"] = *req.Status
-	}
 	if req.Priority != nil {
 		clauses["priority"] = *req.Priority
 	}
@@ -327,6 +281,7 @@ func (p *taskRepo) Update(ctx context.Context, req *entity.UpdateTaskRequest) er
 		Update(p.tableName).
 		SetMap(clauses).
 		Where(p.db.Sq.Equal("id", req.ID)).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return p.db.ErrSQLBuild(err, p.tableName+" update")
@@ -338,6 +293,11 @@ func (p *taskRepo) Update(ctx context.Context, req *entity.UpdateTaskRequest) er
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -347,6 +307,10 @@ func (p *taskRepo) Update(ctx context.Context, req *entity.UpdateTaskRequest) er
 		return fmt.Errorf("no task found with ID %d", req.ID)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -365,22 +329,346 @@ func (p *taskRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("task ID is required for deletion")
 	}
 
+	deleted, err := softDelete(ctx, p.db, p.tableName, id, nil)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no task found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Restore takes a deleted task out of the trash.
+func (p *taskRepo) Restore(ctx context.Context, id int64) error {
+	var reqID = taskIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskRepo.Restore - %s", reqID))
+	}
+
+	restored, err := restoreDeleted(ctx, p.db, p.tableName, id)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		return fmt.Errorf("no deleted task found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Purge removes a deleted task for good.
+func (p *taskRepo) Purge(ctx context.Context, id int64) error {
+	var reqID = taskIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskRepo.Purge - %s", reqID))
+	}
+
+	purged, err := purgeDeleted(ctx, p.db, p.tableName, id, nil)
+	if err != nil {
+		return err
+	}
+	if !purged {
+		return fmt.Errorf("no deleted task found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Transition moves a task to req.Status when its current status allows it
+// and records the move in the status history. Completing stamps
+// completed_at, and any other move clears it.
+func (p *taskRepo) Transition(ctx context.Context, req *entity.TaskTransitionRequest) (*entity.Task, error) {
+	var reqID = taskIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskRepo.Transition - %s", reqID))
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current entity.TaskStatus
+	err = tx.QueryRow(ctx, "SELECT status FROM "+p.tableName+" WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", req.ID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no task found with ID %d", req.ID)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	if !slices.Contains(taskTransitions[current], req.Status) {
+		return nil, fmt.Errorf("%w: %s to %s", entity.ErrorTaskTransition, current, req.Status)
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	clauses := map[string]interface{}{
+		"status":       req.Status,
+		"completed_at": nil,
+		"updated_at":   now,
+	}
+	if req.Status == entity.TaskStatusCompleted {
+		clauses["completed_at"] = now
+	}
+
 	sqlStr, args, err := p.db.Sq.Builder.
-		Delete(p.tableName).
-		Where(squirrel.Eq{"id": id}).
+		Update(p.tableName).
+		SetMap(clauses).
+		Where(squirrel.Eq{"id": req.ID}).
+		Suffix("RETURNING " + strings.Join(taskColumns, ", ")).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("delete query build error: %w", err)
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" transition")
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	task, err := scanTask(tx.QueryRow(ctx, sqlStr, args...))
 	if err != nil {
-		return p.db.Error(err)
+		return nil, p.db.Error(err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("no task found with ID %d", id)
+	if err := p.recordStatusChange(ctx, tx, task.ID, &current, task.Status, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return task, nil
+}
+
+// History returns the status changes of a task, oldest first.
+func (p *taskRepo) History(ctx context.Context, id int64) ([]*entity.TaskStatusChange, error) {
+	var reqID = taskIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskRepo.History - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(taskStatusChangeColumns...).
+		From(taskStatusHistoryTableName).
+		Where(squirrel.Eq{"task_id": id}).
+		OrderBy("created_at", "id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, taskStatusHistoryTableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*entity.TaskStatusChange
+	for rows.Next() {
+		change, err := scanTaskStatusChange(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, rows.Err()
+}
+
+// taskActivityFeed merges everything that happened to the task with ID $1
+// into one feed. Reassignments come from the audit trail, where $2 is the
+// task ID as text.
+var taskActivityFeed = `
+	SELECT 'comment' AS kind, id AS ref_id, user_id AS actor_id, body AS text,
+		NULL AS from_value, NULL AS to_value, created_at
+	FROM ` + taskCommentTableName + ` WHERE task_id = $1
+	UNION ALL
+	SELECT 'status', id, actor_id, NULL, from_status::text, to_status::text, created_at
+	FROM ` + taskStatusHistoryTableName + ` WHERE task_id = $1
+	UNION ALL
+	SELECT 'reassignment', id, actor_id, NULL, before->>'assignedto', after->>'assignedto', created_at
+	FROM ` + auditLogTableName + `
+	WHERE entity = '` + taskTableName + `' AND entity_id = $2 AND action = 'update'
+		AND before->'assignedto' IS DISTINCT FROM after->'assignedto'
+	UNION ALL
+	SELECT 'file', id, NULL, name, NULL, NULL, created_at
+	FROM ` + fileTableName + ` WHERE taskid = $1`
+
+// Activity returns the activity feed of a task, newest first: its comments,
+// status changes, reassignments and attached files.
+func (p *taskRepo) Activity(ctx context.Context, id int64, limit, offset uint64) (*entity.GetTaskActivityResponse, error) {
+	var reqID = taskIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskRepo.Activity - %s", reqID))
+	}
+
+	var activity entity.GetTaskActivityResponse
+	taskKey := fmt.Sprint(id)
+
+	rows, err := p.db.Query(ctx,
+		"SELECT kind, ref_id, actor_id, text, from_value, to_value, created_at FROM ("+taskActivityFeed+") feed ORDER BY created_at DESC, ref_id DESC LIMIT $3 OFFSET $4",
+		id, taskKey, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	for rows.Next() {
+		var item entity.TaskActivity
+		var (
+			nullActorID sql.NullInt64
+			nullText    sql.NullString
+			nullFrom    sql.NullString
+			nullTo      sql.NullString
+		)
+		if err := rows.Scan(&item.Kind, &item.RefID, &nullActorID, &nullText, &nullFrom, &nullTo, &item.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if nullActorID.Valid {
+			item.ActorID = &nullActorID.Int64
+		}
+		if nullText.Valid {
+			item.Text = &nullText.String
+		}
+		if nullFrom.Valid {
+			item.From = &nullFrom.String
+		}
+		if nullTo.Valid {
+			item.To = &nullTo.String
+		}
+		activity.Items = append(activity.Items, &item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	err = p.db.QueryRow(ctx, "SELECT COUNT(*) FROM ("+taskActivityFeed+") feed", id, taskKey).Scan(&activity.Total)
+	if err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &activity, nil
+}
+
+// recordStatusChange adds the move of a task from one status to another to
+// its status history. from is nil for the status a task is created with.
+func (p *taskRepo) recordStatusChange(ctx context.Context, tx pgx.Tx, taskID int64, from *entity.TaskStatus, to entity.TaskStatus, actorID int64) error {
+	columns := []string{"task_id", "to_status", "created_at"}
+	values := []interface{}{taskID, to, time.Now().UTC()}
+	if from != nil {
+		columns = append(columns, "from_status")
+		values = append(values, *from)
+	}
+	if actorID != 0 {
+		columns = append(columns, "actor_id")
+		values = append(values, actorID)
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(taskStatusHistoryTableName).
+		Columns(columns...).
+		Values(values...).
+		ToSql()
+	if err != nil {
+		return p.db.ErrSQLBuild(err, taskStatusHistoryTableName+" create")
+	}
+
+	if _, err := tx.Exec(ctx, sqlStr, args...); err != nil {
+		return p.db.Error(err)
 	}
 
 	return nil
 }
+
+func scanTask(row pgx.Row) (*entity.Task, error) {
+	var task entity.Task
+	var (
+		nullAssignedTo  sql.NullInt64
+		nullDescription sql.NullString
+		nullDueDate     sql.NullTime
+		nullCompletedAt sql.NullTime
+		nullDeletedAt   sql.NullTime
+	)
+
+	err := row.Scan(
+		&task.ID,
+		&nullAssignedTo,
+		&task.AdminID,
+		&task.Title,
+		&nullDescription,
+		&task.Status,
+		&task.Priority,
+		&nullDueDate,
+		&nullCompletedAt,
+		&nullDeletedAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullAssignedTo.Valid {
+		task.AssignedTo = &nullAssignedTo.Int64
+	}
+	if nullDescription.Valid {
+		task.Description = &nullDescription.String
+	}
+	if nullDueDate.Valid {
+		task.DueDate = &nullDueDate.Time
+	}
+	if nullCompletedAt.Valid {
+		task.CompletedAt = &nullCompletedAt.Time
+	}
+	if nullDeletedAt.Valid {
+		task.DeletedAt = &nullDeletedAt.Time
+	}
+
+	return &task, nil
+}
+
+func scanTaskStatusChange(row pgx.Row) (*entity.TaskStatusChange, error) {
+	var change entity.TaskStatusChange
+	var (
+		nullFromStatus sql.NullString
+		nullActorID    sql.NullInt64
+	)
+
+	err := row.Scan(
+		&change.ID,
+		&change.TaskID,
+		&nullFromStatus,
+		&change.ToStatus,
+		&nullActorID,
+		&change.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullFromStatus.Valid {
+		status := entity.TaskStatus(nullFromStatus.String)
+		change.FromStatus = &status
+	}
+	if nullActorID.Valid {
+		change.ActorID = &nullActorID.Int64
+	}
+
+	return &change, nil
+}
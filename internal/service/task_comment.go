@@ -0,0 +1,430 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+	logger "github.com/ruziba3vich/prodonik_lgger"
+)
+
+const (
+	taskCommentTableName = "task_comments"
+	taskCommentIDKey     = "taskCommentID: "
+)
+
+var taskCommentColumns = []string{
+	"id", "task_id", "user_id", "body", "created_at", "updated_at",
+}
+
+// mentionPattern matches an @mention of a user by email, as in
+// "@jane.doe@example.com".
+var mentionPattern = regexp.MustCompile(`@([A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,})`)
+
+// TaskCommentRepoInterface defines the interface for TaskComment CRUD operations.
+type TaskCommentRepoInterface interface {
+	Create(ctx context.Context, req *entity.CreateTaskCommentRequest) (*entity.TaskComment, error)
+	Get(ctx context.Context, params map[string]string) (*entity.TaskComment, error)
+	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllTaskCommentsResponse, error)
+	Update(ctx context.Context, req *entity.UpdateTaskCommentRequest) (*entity.TaskComment, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type taskCommentRepo struct {
+	tableName string
+	db        *postgres.Postgres
+	log       *logger.Logger
+}
+
+func NewTaskCommentRepo(db *postgres.Postgres, log *logger.Logger) TaskCommentRepoInterface {
+	return &taskCommentRepo{
+		tableName: taskCommentTableName,
+		db:        db,
+		log:       log,
+	}
+}
+
+// Create adds a comment to a task that is not in the trash. Mentions holds
+// the users the comment mentions, who should be notified.
+func (p *taskCommentRepo) Create(ctx context.Context, req *entity.CreateTaskCommentRequest) (*entity.TaskComment, error) {
+	var reqID = taskCommentIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskCommentRepo.Create - %s", reqID))
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Insert(p.tableName).
+		Columns("task_id", "user_id", "body", "created_at", "updated_at").
+		Values(req.TaskID, req.UserID, req.Body, time.Now().UTC(), time.Now().UTC()).
+		Suffix("RETURNING " + strings.Join(taskCommentColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" create")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists int
+	err = tx.QueryRow(ctx, "SELECT 1 FROM "+taskTableName+" WHERE id = $1 AND deleted_at IS NULL FOR SHARE", req.TaskID).Scan(&exists)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no task found with ID %d", req.TaskID)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	createdComment, err := scanTaskComment(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	createdComment.Mentions, err = p.mentionedUsers(ctx, tx, req.TaskID, createdComment.Body, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdComment.ID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return createdComment, nil
+}
+
+func (p *taskCommentRepo) Get(ctx context.Context, params map[string]string) (*entity.TaskComment, error) {
+	var reqID = taskCommentIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskCommentRepo.Get - %s", reqID))
+	}
+
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one filter parameter is required")
+	}
+
+	builder := p.db.Sq.Builder.Select(taskCommentColumns...).From(p.tableName)
+
+	for key, value := range params {
+		builder = builder.Where(squirrel.Eq{key: value})
+	}
+
+	sqlStr, args, err := builder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" get")
+	}
+
+	comment, err := scanTaskComment(p.db.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	return comment, nil
+}
+
+// List returns comments oldest first, so a task reads as a conversation.
+func (p *taskCommentRepo) List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllTaskCommentsResponse, error) {
+	var reqID = taskCommentIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskCommentRepo.List - %s", reqID))
+	}
+
+	var comments entity.GetAllTaskCommentsResponse
+	baseBuilder := p.db.Sq.Builder.Select(taskCommentColumns...).From(p.tableName)
+
+	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
+
+	// Apply filters
+	for key, value := range filter {
+		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
+		countBuilder = countBuilder.Where(squirrel.Eq{key: value})
+	}
+
+	// Apply search on the body
+	if search != "" {
+		searchClause := squirrel.ILike{"body": fmt.Sprintf("%%%s%%", search)}
+		baseBuilder = baseBuilder.Where(searchClause)
+		countBuilder = countBuilder.Where(searchClause)
+	}
+
+	// Order, Limit, Offset for data query
+	baseBuilder = baseBuilder.OrderBy("created_at", "id").Limit(limit).Offset(offset)
+
+	sqlStr, args, err := baseBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" list")
+	}
+
+	rows, err := p.db.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+
+	for rows.Next() {
+		comment, err := scanTaskComment(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		comments.Items = append(comments.Items, comment)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	// Get total count
+	countSqlStr, countArgs, err := countBuilder.ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" count")
+	}
+
+	if err := p.db.QueryRow(ctx, countSqlStr, countArgs...).Scan(&comments.Total); err != nil {
+		return nil, fmt.Errorf("count query error: %w", err)
+	}
+
+	return &comments, nil
+}
+
+// Update edits the body of a comment. Mentions holds only the users the edit
+// newly mentions, so nobody is notified twice for the same comment.
+func (p *taskCommentRepo) Update(ctx context.Context, req *entity.UpdateTaskCommentRequest) (*entity.TaskComment, error) {
+	var reqID = taskCommentIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskCommentRepo.Update - %s", reqID))
+	}
+
+	if req == nil {
+		return nil, fmt.Errorf("task comment update request cannot be nil")
+	}
+	if req.ID == 0 {
+		return nil, fmt.Errorf("task comment ID is required for update")
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := p.lockTaskComment(ctx, tx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var authorID int64
+	if current.UserID != nil {
+		authorID = *current.UserID
+	}
+
+	mentioned, err := p.mentionedUsers(ctx, tx, current.TaskID, current.Body, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Update(p.tableName).
+		Set("body", req.Body).
+		Set("updated_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": req.ID}).
+		Suffix("RETURNING " + strings.Join(taskCommentColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" update")
+	}
+
+	comment, err := scanTaskComment(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		return nil, p.db.Error(err)
+	}
+
+	mentions, err := p.mentionedUsers(ctx, tx, comment.TaskID, comment.Body, authorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range mentions {
+		if !slices.Contains(mentioned, userID) {
+			comment.Mentions = append(comment.Mentions, userID)
+		}
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (p *taskCommentRepo) Delete(ctx context.Context, id int64) error {
+	var reqID = taskCommentIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("taskCommentRepo.Delete - %s", reqID))
+	}
+
+	if id == 0 {
+		return fmt.Errorf("task comment ID is required for deletion")
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Delete(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("delete query build error: %w", err)
+	}
+
+	tx, err := p.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	before, err := snapshot(ctx, p.db, tx, p.tableName, id)
+	if err != nil {
+		return err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return p.db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return fmt.Errorf("no task comment found with ID %d", id)
+	}
+
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionDelete, p.tableName, id, before); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// lockTaskComment returns the comment with the given ID, locked for the rest
+// of tx.
+func (p *taskCommentRepo) lockTaskComment(ctx context.Context, tx pgx.Tx, id int64) (*entity.TaskComment, error) {
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select(taskCommentColumns...).
+		From(p.tableName).
+		Where(squirrel.Eq{"id": id}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, p.tableName+" lock")
+	}
+
+	comment, err := scanTaskComment(tx.QueryRow(ctx, sqlStr, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("no task comment found with ID %d", id)
+		}
+		return nil, p.db.Error(err)
+	}
+
+	return comment, nil
+}
+
+// mentionedUsers returns the IDs of the users body mentions by email, leaving
+// out the author, users in the trash and plain users the task is not assigned
+// to, who cannot see it. Unknown emails are ignored.
+func (p *taskCommentRepo) mentionedUsers(ctx context.Context, tx pgx.Tx, taskID int64, body string, authorID int64) ([]int64, error) {
+	var emails []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		email := strings.ToLower(match[1])
+		if !slices.Contains(emails, email) {
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	sqlStr, args, err := p.db.Sq.Builder.
+		Select("id").
+		From(userTableName).
+		Where(squirrel.Eq{"LOWER(email)": emails}).
+		Where(squirrel.NotEq{"id": authorID}).
+		Where(notDeleted).
+		Where(squirrel.Or{
+			squirrel.NotEq{"role": entity.UserRoleUser},
+			squirrel.Expr("id = (SELECT assignedto FROM "+taskTableName+" WHERE id = ?)", taskID),
+		}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, p.db.ErrSQLBuild(err, userTableName+" mentions")
+	}
+
+	rows, err := tx.Query(ctx, sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query error: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int64
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, rows.Err()
+}
+
+func scanTaskComment(row pgx.Row) (*entity.TaskComment, error) {
+	var comment entity.TaskComment
+	var nullUserID sql.NullInt64
+
+	err := row.Scan(
+		&comment.ID,
+		&comment.TaskID,
+		&nullUserID,
+		&comment.Body,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nullUserID.Valid {
+		comment.UserID = &nullUserID.Int64
+	}
+
+	return &comment, nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+)
+
+func TestTaskCommentMentions(t *testing.T) {
+	db := testDB(t)
+	repo := NewTaskCommentRepo(db, testLogger(t))
+	ctx := context.Background()
+
+	author := createTestUser(t, db, "author", entity.UserRoleAdmin)
+	assignee := createTestUser(t, db, "assignee", entity.UserRoleUser)
+	outsider := createTestUser(t, db, "outsider", entity.UserRoleUser)
+	admin := createTestUser(t, db, "admin", entity.UserRoleAdmin)
+	taskID := createTestTask(t, db, author, &assignee)
+
+	comment, err := repo.Create(ctx, &entity.CreateTaskCommentRequest{
+		TaskID: taskID,
+		UserID: author,
+		Body:   "@assignee@example.com @outsider@example.com @ADMIN@example.com @author@example.com @nobody@example.com please look",
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The outsider cannot see the task and the author is not told about their own comment
+	want := []int64{assignee, admin}
+	if !slices.Equal(comment.Mentions, want) {
+		t.Errorf("Mentions = %v, want %v (outsider %d)", comment.Mentions, want, outsider)
+	}
+
+	updated, err := repo.Update(ctx, &entity.UpdateTaskCommentRequest{
+		ID:   comment.ID,
+		Body: comment.Body + " @outsider@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(updated.Mentions) != 0 {
+		t.Errorf("Mentions after edit = %v, want none", updated.Mentions)
+	}
+}
+
+func TestMentionPattern(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "none", body: "no mentions here", want: nil},
+		{name: "plain email", body: "mail jane@example.com instead", want: nil},
+		{name: "one", body: "@jane@example.com please look", want: []string{"jane@example.com"}},
+		{name: "several", body: "@jane@example.com and @john.doe+hr@mail.example.co.uk", want: []string{"jane@example.com", "john.doe+hr@mail.example.co.uk"}},
+		{name: "trailing punctuation", body: "thanks @jane@example.com.", want: []string{"jane@example.com"}},
+		{name: "in parentheses", body: "(cc @jane@example.com)", want: []string{"jane@example.com"}},
+		{name: "case kept", body: "@Jane@Example.COM", want: []string{"Jane@Example.COM"}},
+		{name: "no domain", body: "@jane@localhost", want: nil},
+		{name: "bare at", body: "@ @jane", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, match := range mentionPattern.FindAllStringSubmatch(tt.body, -1) {
+				got = append(got, match[1])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("mentions of %q = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
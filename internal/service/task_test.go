@@ -0,0 +1,37 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ruziba3vich/argus/internal/entity"
+)
+
+func TestTaskTransitions(t *testing.T) {
+	tests := []struct {
+		from, to entity.TaskStatus
+		allowed  bool
+	}{
+		{entity.TaskStatusPending, entity.TaskStatusInProgress, true},
+		{entity.TaskStatusPending, entity.TaskStatusCompleted, true},
+		{entity.TaskStatusPending, entity.TaskStatusCancelled, true},
+		{entity.TaskStatusPending, entity.TaskStatusPending, false},
+		{entity.TaskStatusInProgress, entity.TaskStatusCompleted, true},
+		{entity.TaskStatusInProgress, entity.TaskStatusCancelled, true},
+		{entity.TaskStatusInProgress, entity.TaskStatusPending, false},
+		{entity.TaskStatusCompleted, entity.TaskStatusPending, true},
+		{entity.TaskStatusCompleted, entity.TaskStatusInProgress, false},
+		{entity.TaskStatusCompleted, entity.TaskStatusCancelled, false},
+		{entity.TaskStatusCancelled, entity.TaskStatusPending, true},
+		{entity.TaskStatusCancelled, entity.TaskStatusCompleted, false},
+		{"unknown", entity.TaskStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+" to "+string(tt.to), func(t *testing.T) {
+			if got := slices.Contains(taskTransitions[tt.from], tt.to); got != tt.allowed {
+				t.Errorf("allowed = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
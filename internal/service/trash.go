@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/ruziba3vich/argus/internal/entity"
+	"github.com/ruziba3vich/argus/internal/postgres"
+)
+
+// notDeleted keeps the rows of a soft deleting table that are not in the
+// trash.
+var notDeleted = squirrel.Eq{"deleted_at": nil}
+
+// deletedFilter takes the deleted key out of a list filter and returns the
+// condition picking either the rows in the trash, when it is "true", or the
+// live ones.
+func deletedFilter(filter map[string]string) squirrel.Sqlizer {
+	deleted, ok := filter["deleted"]
+	if !ok {
+		return notDeleted
+	}
+	delete(filter, "deleted")
+
+	if deleted == "true" {
+		return squirrel.NotEq{"deleted_at": nil}
+	}
+	return notDeleted
+}
+
+// softDelete moves the row of table with the given ID to the trash and
+// records it in the audit trail. It reports whether there was such a row
+// outside of the trash. Rows matching kept, when it is not nil, are refused
+// with entity.ErrorPaidOut.
+func softDelete(ctx context.Context, db *postgres.Postgres, table string, id int64, kept squirrel.Sqlizer) (bool, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Update(table).
+		Set("deleted_at", time.Now().UTC()).
+		Where(squirrel.Eq{"id": id}).
+		Where(notDeleted).
+		ToSql()
+	if err != nil {
+		return false, db.ErrSQLBuild(err, table+" soft delete")
+	}
+
+	return trashChange(ctx, db, table, id, entity.AuditActionDelete, kept, sqlStr, args)
+}
+
+// restoreDeleted takes the row of table with the given ID out of the trash
+// and records it in the audit trail. It reports whether the row was in the
+// trash.
+func restoreDeleted(ctx context.Context, db *postgres.Postgres, table string, id int64) (bool, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Update(table).
+		Set("deleted_at", nil).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.NotEq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, db.ErrSQLBuild(err, table+" restore")
+	}
+
+	return trashChange(ctx, db, table, id, entity.AuditActionRestore, nil, sqlStr, args)
+}
+
+// purgeDeleted removes the row of table with the given ID for good and
+// records it in the audit trail. Only rows in the trash can be purged. It
+// reports whether the row was in the trash. Rows matching kept, when it is
+// not nil, are refused with entity.ErrorPaidOut.
+func purgeDeleted(ctx context.Context, db *postgres.Postgres, table string, id int64, kept squirrel.Sqlizer) (bool, error) {
+	sqlStr, args, err := db.Sq.Builder.
+		Delete(table).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.NotEq{"deleted_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, db.ErrSQLBuild(err, table+" purge")
+	}
+
+	return trashChange(ctx, db, table, id, entity.AuditActionPurge, kept, sqlStr, args)
+}
+
+// trashChange runs one of the trash statements on the row of table with the
+// given ID in a transaction, together with its audit log. The row is locked
+// first and left alone when it matches kept.
+func trashChange(ctx context.Context, db *postgres.Postgres, table string, id int64, action entity.AuditAction, kept squirrel.Sqlizer, sqlStr string, args []interface{}) (bool, error) {
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("transaction start failed: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if kept != nil {
+		keptStr, keptArgs, err := kept.ToSql()
+		if err != nil {
+			return false, db.ErrSQLBuild(err, table+" trash check")
+		}
+
+		lockStr, lockArgs, err := db.Sq.Builder.
+			Select().
+			Column(keptStr, keptArgs...).
+			From(table).
+			Where(squirrel.Eq{"id": id}).
+			Suffix("FOR UPDATE").
+			ToSql()
+		if err != nil {
+			return false, db.ErrSQLBuild(err, table+" trash check")
+		}
+
+		// A missing row is reported by the statement itself
+		var isKept bool
+		err = tx.QueryRow(ctx, lockStr, lockArgs...).Scan(&isKept)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return false, db.Error(err)
+		}
+		if isKept {
+			return false, entity.ErrorPaidOut
+		}
+	}
+
+	before, err := snapshot(ctx, db, tx, table, id)
+	if err != nil {
+		return false, err
+	}
+
+	commandTag, err := tx.Exec(ctx, sqlStr, args...)
+	if err != nil {
+		return false, db.Error(err)
+	}
+
+	if commandTag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if err := recordAudit(ctx, db, tx, action, table, id, before); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit failed: %w", err)
+	}
+
+	return true, nil
+}
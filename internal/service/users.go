@@ -10,6 +10,7 @@ import (
 	"github.com/Masterminds/squirrel"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/ruziba3vich/argus/internal/entity"
 	"github.com/ruziba3vich/argus/internal/pkg/helper"
 	"github.com/ruziba3vich/argus/internal/postgres"
@@ -30,6 +31,8 @@ type UserRepoInterface interface {
 	List(ctx context.Context, limit, offset uint64, filter map[string]string, search string) (*entity.GetAllUsersResponse, error)
 	Update(ctx context.Context, user *entity.UpdateUserRequest) error
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, id int64) error
 }
 
 type userRepo struct {
@@ -125,6 +128,10 @@ func (p *userRepo) Create(ctx context.Context, req *entity.CreateUserRequest) (*
 		createdUser.HashedRefreshToken = &nullHashedRefreshToken.String
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionCreate, p.tableName, createdUser.ID, nil); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, fmt.Errorf("commit failed: %w", err)
 	}
@@ -145,8 +152,8 @@ func (p *userRepo) Get(ctx context.Context, params map[string]string) (*entity.U
 
 	builder := p.db.Sq.Builder.Select(
 		"id", "first_name", "last_name", "role", "email", "phone",
-		"photo_url", "bio", "hashed_password", "hashed_refresh_token", "created_at", "updated_at",
-	).From(p.tableName)
+		"photo_url", "bio", "hashed_password", "hashed_refresh_token", "deleted_at", "created_at", "updated_at",
+	).From(p.tableName).Where(notDeleted)
 
 	for key, value := range params {
 		builder = builder.Where(squirrel.Eq{key: value})
@@ -162,12 +169,13 @@ func (p *userRepo) Get(ctx context.Context, params map[string]string) (*entity.U
 		nullPhotoURL           sql.NullString
 		nullBio                sql.NullString
 		nullHashedRefreshToken sql.NullString
+		nullDeletedAt          sql.NullTime
 	)
 
 	err = p.db.QueryRow(ctx, sqlStr, args...).Scan(
 		&user.ID, &user.FirstName, &user.LastName, &user.Role,
 		&user.Email, &user.Phone, &nullPhotoURL, &nullBio,
-		&user.HashedPassword, &nullHashedRefreshToken, &user.CreatedAt, &user.UpdatedAt,
+		&user.HashedPassword, &nullHashedRefreshToken, &nullDeletedAt, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, p.db.Error(err)
@@ -182,6 +190,9 @@ func (p *userRepo) Get(ctx context.Context, params map[string]string) (*entity.U
 	if nullHashedRefreshToken.Valid {
 		user.HashedRefreshToken = &nullHashedRefreshToken.String
 	}
+	if nullDeletedAt.Valid {
+		user.DeletedAt = &nullDeletedAt.Time
+	}
 
 	return &user, nil
 }
@@ -196,11 +207,15 @@ func (p *userRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	var users entity.GetAllUsersResponse
 	baseBuilder := p.db.Sq.Builder.Select(
 		"id", "first_name", "last_name", "role", "email", "phone",
-		"photo_url", "bio", "created_at", "updated_at", // Note: Hashed password/token not selected for list
+		"photo_url", "bio", "deleted_at", "created_at", "updated_at", // Note: Hashed password/token not selected for list
 	).From(p.tableName)
 
 	countBuilder := p.db.Sq.Builder.Select("COUNT(*)").From(p.tableName)
 
+	trash := deletedFilter(filter)
+	baseBuilder = baseBuilder.Where(trash)
+	countBuilder = countBuilder.Where(trash)
+
 	// Apply filters
 	for key, value := range filter {
 		baseBuilder = baseBuilder.Where(squirrel.Eq{key: value})
@@ -236,14 +251,15 @@ func (p *userRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 	for rows.Next() {
 		var user entity.User
 		var (
-			nullPhotoURL sql.NullString
-			nullBio      sql.NullString
+			nullPhotoURL  sql.NullString
+			nullBio       sql.NullString
+			nullDeletedAt sql.NullTime
 		)
 
 		if err := rows.Scan(
 			&user.ID, &user.FirstName, &user.LastName, &user.Role,
 			&user.Email, &user.Phone, &nullPhotoURL, &nullBio,
-			&user.CreatedAt, &user.UpdatedAt,
+			&nullDeletedAt, &user.CreatedAt, &user.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan error: %w", err)
 		}
@@ -253,6 +269,9 @@ func (p *userRepo) List(ctx context.Context, limit, offset uint64, filter map[st
 		if nullBio.Valid {
 			user.Bio = &nullBio.String
 		}
+		if nullDeletedAt.Valid {
+			user.DeletedAt = &nullDeletedAt.Time
+		}
 		users.Items = append(users.Items, &user)
 	}
 
@@ -327,6 +346,7 @@ func (p *userRepo) Update(ctx context.Context, req *entity.UpdateUserRequest) er
 		Update(p.tableName).
 		SetMap(clauses).
 		Where(p.db.Sq.Equal("id", req.ID)).
+		Where(notDeleted).
 		ToSql()
 	if err != nil {
 		return p.db.ErrSQLBuild(err, p.tableName+" update")
@@ -338,6 +358,11 @@ func (p *userRepo) Update(ctx context.Context, req *entity.UpdateUserRequest) er
 	}
 	defer tx.Rollback(ctx)
 
+	before, err := snapshot(ctx, p.db, tx, p.tableName, req.ID)
+	if err != nil {
+		return err
+	}
+
 	commandTag, err := tx.Exec(ctx, sqlStr, args...)
 	if err != nil {
 		return p.db.Error(err)
@@ -347,6 +372,10 @@ func (p *userRepo) Update(ctx context.Context, req *entity.UpdateUserRequest) er
 		return fmt.Errorf("no user found with ID %d", req.ID)
 	}
 
+	if err := recordAudit(ctx, p.db, tx, entity.AuditActionUpdate, p.tableName, req.ID, before); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit failed: %w", err)
 	}
@@ -365,21 +394,56 @@ func (p *userRepo) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("user ID is required for deletion")
 	}
 
-	sqlStr, args, err := p.db.Sq.Builder.
-		Delete(p.tableName).
-		Where(squirrel.Eq{"id": id}).
-		ToSql()
+	deleted, err := softDelete(ctx, p.db, p.tableName, id, nil)
 	if err != nil {
-		return fmt.Errorf("delete query build error: %w", err)
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("no user found with ID %d", id)
+	}
+
+	return nil
+}
+
+// Restore takes a deleted user out of the trash.
+func (p *userRepo) Restore(ctx context.Context, id int64) error {
+	var reqID = userIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("userRepo.Restore - %s", reqID))
 	}
 
-	commandTag, err := p.db.Exec(ctx, sqlStr, args...)
+	restored, err := restoreDeleted(ctx, p.db, p.tableName, id)
 	if err != nil {
-		return p.db.Error(err)
+		return err
+	}
+	if !restored {
+		return fmt.Errorf("no deleted user found with ID %d", id)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return fmt.Errorf("no user found with ID %d", id)
+	return nil
+}
+
+// Purge removes a deleted user for good. Users that salaries, bonuses,
+// attendance or other records still point at are kept, with
+// entity.ErrorUserHistory.
+func (p *userRepo) Purge(ctx context.Context, id int64) error {
+	var reqID = userIDKey
+	if rID := ctx.Value(middleware.RequestIDKey); rID != nil {
+		reqID += rID.(string)
+		p.log.Info(fmt.Sprintf("userRepo.Purge - %s", reqID))
+	}
+
+	purged, err := purgeDeleted(ctx, p.db, p.tableName, id, nil)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("%w: %s", entity.ErrorUserHistory, pgErr.TableName)
+		}
+		return err
+	}
+	if !purged {
+		return fmt.Errorf("no deleted user found with ID %d", id)
 	}
 
 	return nil
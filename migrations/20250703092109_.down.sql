@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS files;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS bonuses;
+DROP TABLE IF EXISTS salaries;
+DROP TABLE IF EXISTS attendances;
+DROP TABLE IF EXISTS users;
+
+DROP TYPE IF EXISTS notification_type;
+DROP TYPE IF EXISTS task_priority;
+DROP TYPE IF EXISTS task_status;
+DROP TYPE IF EXISTS salary_status;
+DROP TYPE IF EXISTS attendance_status;
+DROP TYPE IF EXISTS currency;
+DROP TYPE IF EXISTS user_role;
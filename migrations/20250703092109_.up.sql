@@ -0,0 +1,114 @@
+CREATE TYPE user_role AS ENUM ('super_admin', 'admin', 'user');
+CREATE TYPE currency AS ENUM ('USD', 'EUR', 'GBP');
+CREATE TYPE attendance_status AS ENUM ('present', 'absent', 'late');
+CREATE TYPE salary_status AS ENUM ('paid', 'pending', 'overdue');
+CREATE TYPE task_status AS ENUM ('pending', 'in_progress', 'completed', 'cancelled');
+CREATE TYPE task_priority AS ENUM ('low', 'medium', 'high');
+CREATE TYPE notification_type AS ENUM ('email', 'sms', 'app');
+
+CREATE TABLE IF NOT EXISTS users (
+    id                   BIGSERIAL PRIMARY KEY,
+    first_name           VARCHAR(64)  NOT NULL,
+    last_name            VARCHAR(64)  NOT NULL DEFAULT '',
+    role                 user_role    NOT NULL DEFAULT 'user',
+    email                VARCHAR(255) NOT NULL,
+    phone                VARCHAR(32)  NOT NULL DEFAULT '',
+    photo_url            TEXT,
+    bio                  TEXT,
+    hashed_password      TEXT         NOT NULL,
+    hashed_refresh_token TEXT,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users (email);
+-- registration does not require a phone number, so only non-empty ones are unique
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_key ON users (phone) WHERE phone <> '';
+CREATE INDEX IF NOT EXISTS users_role_idx ON users (role);
+
+CREATE TABLE IF NOT EXISTS attendances (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT            NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    date       DATE              NOT NULL,
+    intime     TIMESTAMPTZ       NOT NULL,
+    outtime    TIMESTAMPTZ,
+    status     attendance_status NOT NULL,
+    created_at TIMESTAMPTZ       NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ       NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS attendances_user_id_date_idx ON attendances (user_id, date);
+CREATE INDEX IF NOT EXISTS attendances_date_idx ON attendances (date);
+CREATE INDEX IF NOT EXISTS attendances_status_idx ON attendances (status);
+
+CREATE TABLE IF NOT EXISTS salaries (
+    id             BIGSERIAL PRIMARY KEY,
+    amount         NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    user_id        BIGINT         NOT NULL REFERENCES users (id),
+    admin_id       BIGINT         NOT NULL REFERENCES users (id),
+    updateradminid BIGINT         REFERENCES users (id) ON DELETE SET NULL,
+    pay_date       DATE           NOT NULL,
+    currency       currency       NOT NULL,
+    status         salary_status  NOT NULL DEFAULT 'pending',
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS salaries_user_id_idx ON salaries (user_id);
+CREATE INDEX IF NOT EXISTS salaries_admin_id_idx ON salaries (admin_id);
+CREATE INDEX IF NOT EXISTS salaries_status_idx ON salaries (status);
+CREATE INDEX IF NOT EXISTS salaries_pay_date_idx ON salaries (pay_date);
+
+CREATE TABLE IF NOT EXISTS bonuses (
+    id           BIGSERIAL PRIMARY KEY,
+    superadminid BIGINT         REFERENCES users (id) ON DELETE SET NULL,
+    user_id      BIGINT         NOT NULL REFERENCES users (id),
+    amount       NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    currency     currency       NOT NULL,
+    reason       TEXT,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS bonuses_user_id_idx ON bonuses (user_id);
+CREATE INDEX IF NOT EXISTS bonuses_superadminid_idx ON bonuses (superadminid);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id          BIGSERIAL PRIMARY KEY,
+    assignedto  BIGINT        REFERENCES users (id) ON DELETE SET NULL,
+    admin_id    BIGINT        NOT NULL REFERENCES users (id),
+    title       VARCHAR(255)  NOT NULL,
+    description TEXT,
+    status      task_status   NOT NULL DEFAULT 'pending',
+    priority    task_priority NOT NULL DEFAULT 'medium',
+    duedate     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS tasks_assignedto_idx ON tasks (assignedto);
+CREATE INDEX IF NOT EXISTS tasks_admin_id_idx ON tasks (admin_id);
+CREATE INDEX IF NOT EXISTS tasks_status_idx ON tasks (status);
+CREATE INDEX IF NOT EXISTS tasks_priority_idx ON tasks (priority);
+
+CREATE TABLE IF NOT EXISTS files (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    taskid     BIGINT       REFERENCES tasks (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS files_taskid_idx ON files (taskid);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT            NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    message    TEXT              NOT NULL,
+    type       notification_type NOT NULL DEFAULT 'app',
+    read       BOOLEAN           NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ       NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ       NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_read_idx ON notifications (user_id, read);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS attendances_open_record_key;
@@ -0,0 +1,2 @@
+-- a user can have at most one open (not checked out) record per day
+CREATE UNIQUE INDEX IF NOT EXISTS attendances_open_record_key ON attendances (user_id, date) WHERE outtime IS NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS shifts (
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    weekday       SMALLINT    NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    start_time    TIME        NOT NULL,
+    end_time      TIME        NOT NULL,
+    grace_minutes INTEGER     NOT NULL DEFAULT 0 CHECK (grace_minutes >= 0),
+    timezone      VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- one shift per user and weekday
+CREATE UNIQUE INDEX IF NOT EXISTS shifts_user_id_weekday_key ON shifts (user_id, weekday);
@@ -0,0 +1,2 @@
+-- set when the nightly job closes a record the user forgot to check out of
+ALTER TABLE attendances ADD COLUMN IF NOT EXISTS auto_closed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS leave_requests;
+
+DROP TYPE IF EXISTS leave_status;
+DROP TYPE IF EXISTS leave_type;
@@ -0,0 +1,22 @@
+CREATE TYPE leave_type AS ENUM ('vacation', 'sick', 'unpaid');
+CREATE TYPE leave_status AS ENUM ('pending', 'approved', 'rejected', 'cancelled');
+
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type           leave_type   NOT NULL,
+    start_date     DATE         NOT NULL,
+    end_date       DATE         NOT NULL,
+    days           INTEGER      NOT NULL CHECK (days >= 0),
+    reason         TEXT,
+    status         leave_status NOT NULL DEFAULT 'pending',
+    reviewer_id    BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at    TIMESTAMPTZ,
+    review_comment TEXT,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS leave_requests_user_id_dates_idx ON leave_requests (user_id, start_date, end_date);
+CREATE INDEX IF NOT EXISTS leave_requests_status_idx ON leave_requests (status);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS salaries_payroll_run_id_idx;
+ALTER TABLE salaries DROP COLUMN IF EXISTS payroll_run_id;
+
+DROP TABLE IF EXISTS payroll_runs;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS payroll_runs (
+    id           BIGSERIAL PRIMARY KEY,
+    admin_id     BIGINT         NOT NULL REFERENCES users (id),
+    period_start DATE           NOT NULL,
+    period_end   DATE           NOT NULL,
+    pay_date     DATE           NOT NULL,
+    currency     currency       NOT NULL,
+    total        NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX IF NOT EXISTS payroll_runs_period_idx ON payroll_runs (period_start, period_end);
+
+-- salaries typed in by hand keep a NULL run
+ALTER TABLE salaries ADD COLUMN IF NOT EXISTS payroll_run_id BIGINT REFERENCES payroll_runs (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS salaries_payroll_run_id_idx ON salaries (payroll_run_id);
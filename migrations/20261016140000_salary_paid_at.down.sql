@@ -0,0 +1 @@
+ALTER TABLE salaries DROP COLUMN IF EXISTS paid_at;
@@ -0,0 +1,4 @@
+ALTER TABLE salaries ADD COLUMN IF NOT EXISTS paid_at TIMESTAMPTZ;
+
+-- salaries paid before the column existed were last touched when they were paid
+UPDATE salaries SET paid_at = updated_at WHERE status = 'paid' AND paid_at IS NULL;
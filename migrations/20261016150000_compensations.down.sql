@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS compensations;
+
+DROP TYPE IF EXISTS rate_type;
@@ -0,0 +1,17 @@
+CREATE TYPE rate_type AS ENUM ('monthly', 'hourly');
+
+CREATE TABLE IF NOT EXISTS compensations (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    rate_type      rate_type      NOT NULL,
+    amount         NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    currency       currency       NOT NULL,
+    effective_from DATE           NOT NULL,
+    admin_id       BIGINT         REFERENCES users (id) ON DELETE SET NULL,
+    note           TEXT,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+-- a profile stays in effect until the next one of the user starts
+CREATE UNIQUE INDEX IF NOT EXISTS compensations_user_id_effective_from_key ON compensations (user_id, effective_from);
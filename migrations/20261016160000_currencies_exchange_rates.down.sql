@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+-- rows in currencies added later have no enum label and make this fail
+CREATE TYPE currency AS ENUM ('USD', 'EUR', 'GBP');
+
+ALTER TABLE compensations DROP CONSTRAINT IF EXISTS compensations_currency_fkey;
+ALTER TABLE compensations ALTER COLUMN currency TYPE currency USING currency::currency;
+
+ALTER TABLE payroll_runs DROP CONSTRAINT IF EXISTS payroll_runs_currency_fkey;
+ALTER TABLE payroll_runs ALTER COLUMN currency TYPE currency USING currency::currency;
+
+ALTER TABLE bonuses DROP CONSTRAINT IF EXISTS bonuses_currency_fkey;
+ALTER TABLE bonuses ALTER COLUMN currency TYPE currency USING currency::currency;
+
+ALTER TABLE salaries DROP CONSTRAINT IF EXISTS salaries_currency_fkey;
+ALTER TABLE salaries ALTER COLUMN currency TYPE currency USING currency::currency;
+
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,39 @@
+-- currencies become rows instead of enum labels, so new ones need no migration
+CREATE TABLE IF NOT EXISTS currencies (
+    code       VARCHAR(3)  PRIMARY KEY CHECK (code ~ '^[A-Z]{3}$'),
+    name       VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO currencies (code, name)
+VALUES ('USD', 'US Dollar'), ('EUR', 'Euro'), ('GBP', 'Pound Sterling')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE salaries ALTER COLUMN currency TYPE VARCHAR(3) USING currency::text;
+ALTER TABLE salaries ADD CONSTRAINT salaries_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE bonuses ALTER COLUMN currency TYPE VARCHAR(3) USING currency::text;
+ALTER TABLE bonuses ADD CONSTRAINT bonuses_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE payroll_runs ALTER COLUMN currency TYPE VARCHAR(3) USING currency::text;
+ALTER TABLE payroll_runs ADD CONSTRAINT payroll_runs_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+ALTER TABLE compensations ALTER COLUMN currency TYPE VARCHAR(3) USING currency::text;
+ALTER TABLE compensations ADD CONSTRAINT compensations_currency_fkey FOREIGN KEY (currency) REFERENCES currencies (code);
+
+DROP TYPE IF EXISTS currency;
+
+-- one unit of base is worth rate units of quote on rate_date
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id         BIGSERIAL PRIMARY KEY,
+    base       VARCHAR(3)     NOT NULL REFERENCES currencies (code),
+    quote      VARCHAR(3)     NOT NULL REFERENCES currencies (code),
+    rate_date  DATE           NOT NULL,
+    rate       NUMERIC(20, 8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    CHECK (base <> quote)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS exchange_rates_base_quote_rate_date_key ON exchange_rates (base, quote, rate_date);
+CREATE INDEX IF NOT EXISTS exchange_rates_rate_date_idx ON exchange_rates (rate_date);
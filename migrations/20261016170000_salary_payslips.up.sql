@@ -0,0 +1,2 @@
+-- object key of the payslip PDF in MinIO, set once the salary is paid
+ALTER TABLE salaries ADD COLUMN IF NOT EXISTS payslip_key VARCHAR(255);
@@ -0,0 +1,15 @@
+ALTER TABLE bonuses
+    DROP COLUMN IF EXISTS payout_updated_at,
+    DROP COLUMN IF EXISTS payout_error,
+    DROP COLUMN IF EXISTS payout_transaction_id,
+    DROP COLUMN IF EXISTS payout_key,
+    DROP COLUMN IF EXISTS payout_status;
+
+ALTER TABLE salaries
+    DROP COLUMN IF EXISTS payout_updated_at,
+    DROP COLUMN IF EXISTS payout_error,
+    DROP COLUMN IF EXISTS payout_transaction_id,
+    DROP COLUMN IF EXISTS payout_key,
+    DROP COLUMN IF EXISTS payout_status;
+
+DROP TABLE IF EXISTS payout_cards;
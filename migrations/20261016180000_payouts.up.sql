@@ -0,0 +1,25 @@
+-- cards are paid in sums, so payouts need UZS and its exchange rates
+INSERT INTO currencies (code, name) VALUES ('UZS', 'Uzbekistan Sum') ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS payout_cards (
+    user_id    BIGINT      PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    card       VARCHAR(64) NOT NULL,
+    masked     VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- payout_key is sent to Multicard on every attempt, so a retried payout is never paid twice
+ALTER TABLE salaries
+    ADD COLUMN IF NOT EXISTS payout_status         VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS payout_key            VARCHAR(64) UNIQUE,
+    ADD COLUMN IF NOT EXISTS payout_transaction_id VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS payout_error          TEXT,
+    ADD COLUMN IF NOT EXISTS payout_updated_at     TIMESTAMPTZ;
+
+ALTER TABLE bonuses
+    ADD COLUMN IF NOT EXISTS payout_status         VARCHAR(16),
+    ADD COLUMN IF NOT EXISTS payout_key            VARCHAR(64) UNIQUE,
+    ADD COLUMN IF NOT EXISTS payout_transaction_id VARCHAR(128),
+    ADD COLUMN IF NOT EXISTS payout_error          TEXT,
+    ADD COLUMN IF NOT EXISTS payout_updated_at     TIMESTAMPTZ;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS bonuses_status_idx;
+
+ALTER TABLE bonuses
+    DROP COLUMN IF EXISTS review_comment,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS proposed_by,
+    DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS bonus_status;
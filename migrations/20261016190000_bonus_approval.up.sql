@@ -0,0 +1,14 @@
+CREATE TYPE bonus_status AS ENUM ('pending', 'approved', 'rejected');
+
+-- Bonuses granted before the approval workflow keep counting
+ALTER TABLE bonuses
+    ADD COLUMN IF NOT EXISTS status         bonus_status NOT NULL DEFAULT 'approved',
+    ADD COLUMN IF NOT EXISTS proposed_by    BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS reviewed_at    TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS review_comment TEXT;
+
+UPDATE bonuses SET reviewed_at = created_at WHERE superadminid IS NOT NULL;
+
+ALTER TABLE bonuses ALTER COLUMN status SET DEFAULT 'pending';
+
+CREATE INDEX IF NOT EXISTS bonuses_status_idx ON bonuses (status);
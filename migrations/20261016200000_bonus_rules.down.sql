@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS bonuses_rule_user_period_key;
+
+ALTER TABLE bonuses
+    DROP COLUMN IF EXISTS rule_period,
+    DROP COLUMN IF EXISTS rule_id;
+
+DROP TABLE IF EXISTS bonus_rules;
+
+DROP TYPE IF EXISTS bonus_rule_kind;
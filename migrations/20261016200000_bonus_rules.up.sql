@@ -0,0 +1,21 @@
+CREATE TYPE bonus_rule_kind AS ENUM ('perfect_attendance', 'zero_late_days', 'high_priority_tasks');
+
+CREATE TABLE IF NOT EXISTS bonus_rules (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255)    NOT NULL,
+    kind       bonus_rule_kind NOT NULL,
+    threshold  INTEGER         NOT NULL DEFAULT 1 CHECK (threshold >= 1),
+    amount     NUMERIC(14, 2)  NOT NULL CHECK (amount > 0),
+    currency   VARCHAR(3)      NOT NULL REFERENCES currencies (code),
+    active     BOOLEAN         NOT NULL DEFAULT TRUE,
+    admin_id   BIGINT          REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ     NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ     NOT NULL DEFAULT NOW()
+);
+
+-- a rule proposes at most one bonus per employee and month, even after a rejection
+ALTER TABLE bonuses
+    ADD COLUMN IF NOT EXISTS rule_id     BIGINT REFERENCES bonus_rules (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS rule_period DATE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS bonuses_rule_user_period_key ON bonuses (rule_id, user_id, rule_period);
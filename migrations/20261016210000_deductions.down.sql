@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS deductions;
+
+DROP TYPE IF EXISTS deduction_kind;
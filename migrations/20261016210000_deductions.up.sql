@@ -0,0 +1,17 @@
+CREATE TYPE deduction_kind AS ENUM ('fine', 'advance', 'lateness');
+
+CREATE TABLE IF NOT EXISTS deductions (
+    id            BIGSERIAL PRIMARY KEY,
+    admin_id      BIGINT         REFERENCES users (id) ON DELETE SET NULL,
+    user_id       BIGINT         NOT NULL REFERENCES users (id),
+    attendance_id BIGINT         REFERENCES attendances (id) ON DELETE SET NULL,
+    kind          deduction_kind NOT NULL DEFAULT 'fine',
+    amount        NUMERIC(14, 2) NOT NULL CHECK (amount > 0),
+    currency      VARCHAR(3)     NOT NULL REFERENCES currencies (code),
+    reason        TEXT,
+    created_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS deductions_user_id_created_at_idx ON deductions (user_id, created_at);
+CREATE INDEX IF NOT EXISTS deductions_attendance_id_idx ON deductions (attendance_id);
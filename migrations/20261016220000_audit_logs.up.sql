@@ -0,0 +1,15 @@
+-- actor_id has no foreign key so the trail outlives the users it mentions
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id         BIGSERIAL PRIMARY KEY,
+    actor_id   BIGINT,
+    action     VARCHAR(16) NOT NULL,
+    entity     VARCHAR(64) NOT NULL,
+    entity_id  VARCHAR(64) NOT NULL,
+    before     JSONB,
+    after      JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_entity_idx ON audit_logs (entity, entity_id);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_id_idx ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at_idx ON audit_logs (created_at);
@@ -0,0 +1,11 @@
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE bonuses
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE salaries
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deleted_at;
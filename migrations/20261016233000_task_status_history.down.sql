@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS task_status_history;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
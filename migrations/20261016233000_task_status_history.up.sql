@@ -0,0 +1,15 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- tasks completed before the column existed were last touched when they were completed
+UPDATE tasks SET completed_at = updated_at WHERE status = 'completed' AND completed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS task_status_history (
+    id          BIGSERIAL PRIMARY KEY,
+    task_id     BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    from_status task_status,
+    to_status   task_status NOT NULL,
+    actor_id    BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_status_history_task_id_idx ON task_status_history (task_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS task_comments (
+    id         BIGSERIAL PRIMARY KEY,
+    task_id    BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    BIGINT      REFERENCES users (id) ON DELETE SET NULL,
+    body       TEXT        NOT NULL CHECK (body <> ''),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS task_comments_task_id_idx ON task_comments (task_id, created_at);
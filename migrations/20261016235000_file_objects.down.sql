@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS files_object_key_idx;
+
+ALTER TABLE files
+    DROP COLUMN IF EXISTS uploaded_by,
+    DROP COLUMN IF EXISTS mime_type,
+    DROP COLUMN IF EXISTS size,
+    DROP COLUMN IF EXISTS object_key;
@@ -0,0 +1,9 @@
+-- files without an object_key were registered by name only before uploads
+-- went to MinIO
+ALTER TABLE files
+    ADD COLUMN IF NOT EXISTS object_key  TEXT,
+    ADD COLUMN IF NOT EXISTS size        BIGINT,
+    ADD COLUMN IF NOT EXISTS mime_type   VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS uploaded_by BIGINT REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS files_object_key_idx ON files (object_key);
@@ -0,0 +1,4 @@
+ALTER TABLE salaries
+    DROP COLUMN IF EXISTS deduction_total,
+    DROP COLUMN IF EXISTS bonus_total,
+    DROP COLUMN IF EXISTS base_pay;
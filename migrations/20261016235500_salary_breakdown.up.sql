@@ -0,0 +1,7 @@
+-- the payroll line a run salary was computed from, so its payslip does not
+-- change when bonuses or deductions are edited afterwards. Hand-entered
+-- salaries and run salaries from before this migration have none.
+ALTER TABLE salaries
+    ADD COLUMN IF NOT EXISTS base_pay        NUMERIC(14, 2),
+    ADD COLUMN IF NOT EXISTS bonus_total     NUMERIC(14, 2),
+    ADD COLUMN IF NOT EXISTS deduction_total NUMERIC(14, 2);
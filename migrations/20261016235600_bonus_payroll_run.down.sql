@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS bonuses_payroll_run_id_idx;
+
+ALTER TABLE bonuses
+    DROP COLUMN IF EXISTS payroll_run_id;
@@ -0,0 +1,6 @@
+-- the payroll run whose salary a bonus was paid with. Such a bonus must not
+-- be paid out on its own as well, and the next run must not pick it up again.
+ALTER TABLE bonuses
+    ADD COLUMN IF NOT EXISTS payroll_run_id BIGINT REFERENCES payroll_runs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS bonuses_payroll_run_id_idx ON bonuses (payroll_run_id);
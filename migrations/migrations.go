@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL schema migrations so the
+// binary can apply them without the files being shipped next to it.
+package migrations
+
+import "embed"
+
+// FS holds every <version>_<title>.up.sql / .down.sql file of this directory.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,33 @@
+package migrations_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruziba3vich/argus/internal/postgres"
+	"github.com/ruziba3vich/argus/migrations"
+)
+
+// TestFS checks that every embedded migration loads, in version order, with
+// both directions.
+func TestFS(t *testing.T) {
+	loaded, err := postgres.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	for i, m := range loaded {
+		if i > 0 && m.Version <= loaded[i-1].Version {
+			t.Errorf("migration %d comes after %d", m.Version, loaded[i-1].Version)
+		}
+		if strings.TrimSpace(m.Up) == "" {
+			t.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %d_%s has no down script", m.Version, m.Name)
+		}
+	}
+}